@@ -1,22 +1,82 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mbaykara/azurermcli/internal/app"
 	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
 )
 
 func main() {
-	p := tea.NewProgram(app.New(), tea.WithAltScreen())
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	tenant := flag.String("tenant", "", "tenant ID to sign in to (defaults to the credential's home tenant)")
+	cloudName := flag.String("cloud", cfg.Cloud, "Azure cloud: AzurePublic, AzureChina, AzureUSGovernment or Custom")
+	armEndpoint := flag.String("arm-endpoint", cfg.ARMEndpoint, "Resource Manager endpoint of a Custom cloud")
+	authorityHost := flag.String("authority-host", cfg.AuthorityHost, "Microsoft Entra authority host of a Custom cloud")
+	authMethod := flag.String("auth", cfg.Auth, "authentication method: "+strings.Join(azure.AuthMethods, ", "))
+	clientID := flag.String("client-id", cfg.ClientID, "client ID of the service principal, workload identity or user-assigned managed identity")
+	clientCertificate := flag.String("client-certificate", cfg.ClientCertificate, "PEM or PKCS#12 certificate of the service principal")
+	flag.Parse()
+	*authMethod = strings.ToLower(*authMethod)
+
+	if *authMethod != "" && !slices.Contains(azure.AuthMethods, *authMethod) {
+		fmt.Printf("Error: unknown authentication method %q (want one of %s)\n", *authMethod, strings.Join(azure.AuthMethods, ", "))
+		os.Exit(1)
+	}
+
+	// Service principals take their secrets, and optionally their tenant and
+	// client ID, from the same environment variables as the Azure SDKs.
+	if *authMethod == azure.AuthServicePrincipal {
+		if *tenant == "" {
+			*tenant = os.Getenv("AZURE_TENANT_ID")
+		}
+		if *clientID == "" {
+			*clientID = os.Getenv("AZURE_CLIENT_ID")
+		}
+	}
+
+	cloudCfg, err := azure.ParseCloud(*cloudName, *armEndpoint, *authorityHost)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	azure.SetCloud(cloudCfg)
+	azure.SetTenant(*tenant)
+	azure.SetAuth(azure.AuthOptions{
+		Method:              *authMethod,
+		ClientID:            *clientID,
+		ClientSecret:        os.Getenv("AZURE_CLIENT_SECRET"),
+		CertificatePath:     *clientCertificate,
+		CertificatePassword: os.Getenv("AZURE_CLIENT_CERTIFICATE_PASSWORD"),
+	})
+
+	// Reports run headless, for cron jobs and pipelines.
+	if flag.Arg(0) == "report" {
+		azure.SetAuthPrompt(func(message string) {
+			fmt.Fprintln(os.Stderr, message)
+		})
+		os.Exit(runReport(flag.Args()[1:], os.Stdout, os.Stderr))
+	}
+
+	// Subscriptions are fetched by the model's Init.
+	p := tea.NewProgram(app.New(cfg), tea.WithAltScreen())
 
-	// Start by fetching subscriptions
-	go func() {
-		msg := azure.FetchSubscriptions()
-		p.Send(msg)
-	}()
+	// Interactive sign-in instructions are shown inside the TUI, since the
+	// alternate screen hides anything written to stdout.
+	azure.SetAuthPrompt(func(message string) {
+		p.Send(azure.AuthPromptMsg{Message: message})
+	})
 
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error: %v", err)
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const reportUsage = "usage: azr [flags] report expiring [-o table|json] [-days N] [-subscription ID,...]"
+
+// Exit codes of azr report expiring. A scan that could not read everything
+// fails even when it found items, so that a cron job never mistakes it for
+// a clean bill of health.
+const (
+	exitOK       = 0
+	exitFailed   = 1
+	exitUsage    = 2
+	exitExpiring = 3 // Something expired or expires within ExpiryCriticalDays.
+)
+
+// listSubscriptionIDs and scanExpiring reach Azure; tests replace them.
+var (
+	listSubscriptionIDs = func() ([]string, error) {
+		switch msg := azure.FetchSubscriptions().(type) {
+		case azure.ErrorMsg:
+			return nil, msg.Error
+		case azure.SubscriptionsMsg:
+			var ids []string
+			for _, sub := range msg.Subs {
+				if sub.SubscriptionID != nil {
+					ids = append(ids, *sub.SubscriptionID)
+				}
+			}
+			return ids, nil
+		}
+		return nil, nil
+	}
+	scanExpiring = azure.ScanExpiring
+)
+
+// expiringReport is the JSON document written by azr report expiring.
+type expiringReport struct {
+	GeneratedAt   time.Time            `json:"generatedAt"`
+	WindowDays    int                  `json:"windowDays"`
+	Subscriptions []string             `json:"subscriptions"`
+	Items         []azure.ExpiringItem `json:"items"`
+	Errors        map[string]string    `json:"errors,omitempty"`
+	Unlisted      map[string]string    `json:"unlisted,omitempty"`
+}
+
+// runReport runs a headless report without starting the terminal UI and
+// returns the process exit code. The report is written even when the scan
+// was incomplete.
+func runReport(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "expiring" {
+		fmt.Fprintln(stderr, reportUsage)
+		return exitUsage
+	}
+
+	flags := flag.NewFlagSet("report expiring", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "table", "output format: table or json")
+	days := flags.Int("days", azure.ExpiryWarningDays, "report items expiring within this many days")
+	subscriptions := flags.String("subscription", "", "comma-separated subscription IDs to scan (defaults to every subscription you can see)")
+	if err := flags.Parse(args[1:]); err != nil {
+		return exitUsage
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "Error: unknown output format %q (want table or json)\n", *output)
+		return exitUsage
+	}
+	if *days < 1 {
+		fmt.Fprintln(stderr, "Error: -days must be at least 1")
+		return exitUsage
+	}
+
+	var subscriptionIDs []string
+	for _, id := range strings.Split(*subscriptions, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			subscriptionIDs = append(subscriptionIDs, id)
+		}
+	}
+	if len(subscriptionIDs) == 0 {
+		ids, err := listSubscriptionIDs()
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: listing subscriptions: %v\n", err)
+			return exitFailed
+		}
+		if len(ids) == 0 {
+			fmt.Fprintln(stderr, "Error: no subscriptions to scan")
+			return exitFailed
+		}
+		subscriptionIDs = ids
+	}
+
+	scan, err := scanExpiring(context.Background(), subscriptionIDs, *days)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitFailed
+	}
+
+	report := expiringReport{
+		GeneratedAt:   time.Now().UTC(),
+		WindowDays:    *days,
+		Subscriptions: subscriptionIDs,
+		Items:         scan.Items,
+		Errors:        errorStrings(scan.Errors),
+		Unlisted:      errorStrings(scan.Unlisted),
+	}
+	if report.Items == nil {
+		report.Items = []azure.ExpiringItem{}
+	}
+
+	if *output == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitFailed
+		}
+		return reportExitCode(report)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DAYS\tSTATUS\tEXPIRES\tKIND\tNAME\tVAULT\tSUBSCRIPTION")
+	for _, item := range report.Items {
+		vault := item.Vault
+		if vault == "" {
+			vault = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", item.DaysLeft, item.Status, item.Expires.UTC().Format("2006-01-02"), item.Kind, item.Name, vault, item.SubscriptionID)
+	}
+	w.Flush()
+
+	printErrors(stderr, "Error", report.Errors)
+	printErrors(stderr, "Warning: not listed", report.Unlisted)
+	return reportExitCode(report)
+}
+
+// errorStrings turns errors keyed by ID into their messages, or nil when
+// there are none.
+func errorStrings(errs map[string]error) map[string]string {
+	if len(errs) == 0 {
+		return nil
+	}
+	messages := make(map[string]string, len(errs))
+	for id, err := range errs {
+		messages[id] = err.Error()
+	}
+	return messages
+}
+
+// printErrors writes one line per ID, sorted, after prefix.
+func printErrors(w io.Writer, prefix string, errs map[string]string) {
+	ids := make([]string, 0, len(errs))
+	for id := range errs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fmt.Fprintf(w, "%s: %s: %s\n", prefix, id, errs[id])
+	}
+}
+
+// reportExitCode fails a report with errors and flags one with expired or
+// critical items. Vault item kinds the caller may not list are the caller's
+// permissions rather than a failed scan, and only show up in the report.
+func reportExitCode(report expiringReport) int {
+	if len(report.Errors) > 0 {
+		return exitFailed
+	}
+	for _, item := range report.Items {
+		if item.Status == "expired" || item.Status == "critical" {
+			return exitExpiring
+		}
+	}
+	return exitOK
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+// fakeAzure replaces the subscription listing and the scan for one test.
+func fakeAzure(t *testing.T, subscriptions []string, items []azure.ExpiringItem, errs map[string]error) *[]string {
+	t.Helper()
+	return fakeScan(t, subscriptions, azure.ExpiryScan{Items: items, Errors: errs})
+}
+
+// fakeScan is fakeAzure for a scan with unlisted vault item kinds.
+func fakeScan(t *testing.T, subscriptions []string, scan azure.ExpiryScan) *[]string {
+	t.Helper()
+	var scanned []string
+	oldList, oldScan := listSubscriptionIDs, scanExpiring
+	t.Cleanup(func() { listSubscriptionIDs, scanExpiring = oldList, oldScan })
+	listSubscriptionIDs = func() ([]string, error) { return subscriptions, nil }
+	scanExpiring = func(_ context.Context, subscriptionIDs []string, _ int) (azure.ExpiryScan, error) {
+		scanned = subscriptionIDs
+		return scan, nil
+	}
+	return &scanned
+}
+
+func TestReportUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no report", args: nil, want: "usage: azr"},
+		{name: "unknown report", args: []string{"costs"}, want: "usage: azr"},
+		{name: "unknown flag", args: []string{"expiring", "-x"}, want: "flag provided but not defined"},
+		{name: "unknown format", args: []string{"expiring", "-o", "xml"}, want: `unknown output format "xml"`},
+		{name: "empty window", args: []string{"expiring", "-days", "0"}, want: "-days must be at least 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeAzure(t, []string{"sub-1"}, nil, nil)
+			var stdout, stderr bytes.Buffer
+			if code := runReport(tt.args, &stdout, &stderr); code != exitUsage {
+				t.Errorf("exit code = %d, want %d", code, exitUsage)
+			}
+			if !strings.Contains(stderr.String(), tt.want) || stdout.Len() != 0 {
+				t.Errorf("stdout %q, stderr %q; want %q on stderr", stdout.String(), stderr.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestReportJSON(t *testing.T) {
+	expires := time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		items     []azure.ExpiringItem
+		errs      map[string]error
+		wantCode  int
+		wantItems int
+		wantErrs  bool
+	}{
+		{name: "nothing expires", wantCode: exitOK},
+		{
+			name:      "warning only",
+			items:     []azure.ExpiringItem{{Name: "tls", Kind: "certificate", Vault: "kv-app", Expires: expires, DaysLeft: 20, Status: "warning"}},
+			wantCode:  exitOK,
+			wantItems: 1,
+		},
+		{
+			name:      "critical item",
+			items:     []azure.ExpiringItem{{Name: "tls", Kind: "certificate", Vault: "kv-app", Expires: expires, DaysLeft: 3, Status: "critical"}},
+			wantCode:  exitExpiring,
+			wantItems: 1,
+		},
+		{
+			name:     "every subscription failed",
+			errs:     map[string]error{"sub-1": errors.New("AuthorizationFailed")},
+			wantCode: exitFailed,
+			wantErrs: true,
+		},
+		{
+			name:      "vault unreadable",
+			items:     []azure.ExpiringItem{{Name: "tls", Kind: "certificate", Expires: expires, DaysLeft: -1, Status: "expired"}},
+			errs:      map[string]error{"/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv": errors.New("Forbidden")},
+			wantCode:  exitFailed,
+			wantItems: 1,
+			wantErrs:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanned := fakeAzure(t, []string{"sub-1"}, tt.items, tt.errs)
+			var stdout, stderr bytes.Buffer
+			if code := runReport([]string{"expiring", "-o", "json", "-days", "14"}, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("exit code = %d, want %d (stderr %q)", code, tt.wantCode, stderr.String())
+			}
+			if len(*scanned) != 1 || (*scanned)[0] != "sub-1" {
+				t.Errorf("scanned %v, want every listed subscription", *scanned)
+			}
+
+			var report map[string]json.RawMessage
+			if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, stdout.String())
+			}
+			var items []azure.ExpiringItem
+			if err := json.Unmarshal(report["items"], &items); err != nil || items == nil || len(items) != tt.wantItems {
+				t.Errorf("items = %s, want %d items as an array", report["items"], tt.wantItems)
+			}
+			if string(report["windowDays"]) != "14" {
+				t.Errorf("windowDays = %s", report["windowDays"])
+			}
+			if _, ok := report["errors"]; ok != tt.wantErrs {
+				t.Errorf("errors = %s, want present %v", report["errors"], tt.wantErrs)
+			}
+		})
+	}
+}
+
+func TestReportTable(t *testing.T) {
+	scanned := fakeAzure(t, nil, []azure.ExpiringItem{
+		{SubscriptionID: "sub-2", Name: "www", Kind: azure.KindAppServiceCertificate, Expires: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC), DaysLeft: 12, Status: "warning"},
+	}, map[string]error{"sub-3": errors.New("AuthorizationFailed")})
+
+	var stdout, stderr bytes.Buffer
+	code := runReport([]string{"expiring", "-subscription", "sub-2, sub-3"}, &stdout, &stderr)
+	if code != exitFailed {
+		t.Errorf("exit code = %d, want %d for an incomplete scan", code, exitFailed)
+	}
+	if len(*scanned) != 2 || (*scanned)[1] != "sub-3" {
+		t.Errorf("scanned %v, want sub-2 and sub-3", *scanned)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "DAYS") {
+		t.Fatalf("table = %q", stdout.String())
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "12 warning 2025-03-04 appservice-certificate www - sub-2" {
+		t.Errorf("row = %q", lines[1])
+	}
+	if stderr.String() != "Error: sub-3: AuthorizationFailed\n" {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestReportUnlistedKinds(t *testing.T) {
+	const vault = "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"
+	fakeScan(t, []string{"sub-1"}, azure.ExpiryScan{
+		Items:    []azure.ExpiringItem{{Name: "db", Kind: "secret", Vault: "kv", DaysLeft: 20, Status: "warning"}},
+		Unlisted: map[string]error{vault + "/keys": errors.New("Forbidden: Caller is not authorized")},
+	})
+
+	var stdout, stderr bytes.Buffer
+	if code := runReport([]string{"expiring"}, &stdout, &stderr); code != exitOK {
+		t.Errorf("exit code = %d, want %d when only a kind could not be listed", code, exitOK)
+	}
+	if want := "Warning: not listed: " + vault + "/keys: Forbidden: Caller is not authorized\n"; stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+
+	stdout.Reset()
+	runReport([]string{"expiring", "-o", "json"}, &stdout, &stderr)
+	var report expiringReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || len(report.Unlisted) != 1 || report.Errors != nil {
+		t.Errorf("report = %s, want the kind under unlisted and no errors", stdout.String())
+	}
+}
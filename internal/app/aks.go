@@ -0,0 +1,260 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+func (m *Model) openAKS(resourceID string) tea.Cmd {
+	m.openDetailView("aks")
+	m.aksID = resourceID
+	return azure.FetchAKSCluster(resourceID)
+}
+
+// apiServerAccess summarises who can reach the cluster's API server.
+func apiServerAccess(props *armcontainerservice.ManagedClusterProperties) string {
+	if props.APIServerAccessProfile == nil {
+		return "public"
+	}
+	profile := props.APIServerAccessProfile
+	if profile.EnablePrivateCluster != nil && *profile.EnablePrivateCluster {
+		return "private (" + orDash(props.PrivateFQDN) + ")"
+	}
+	if len(profile.AuthorizedIPRanges) > 0 {
+		return "public, authorized ranges " + joinOrDash(profile.AuthorizedIPRanges)
+	}
+	return "public"
+}
+
+func (m *Model) updateTableWithAKS() {
+	cluster := m.aksCluster.Cluster
+
+	m.properties = []ui.Property{{Key: "Cluster", Value: orDash(cluster.Name)}}
+	if props := cluster.Properties; props != nil {
+		powerState := "-"
+		if props.PowerState != nil {
+			powerState = orDash(props.PowerState.Code)
+		}
+		network := "-"
+		if props.NetworkProfile != nil {
+			network = orDash(props.NetworkProfile.NetworkPlugin)
+			if props.NetworkProfile.NetworkPolicy != nil {
+				network += ", policy " + orDash(props.NetworkProfile.NetworkPolicy)
+			}
+		}
+		upgrades := "none"
+		if len(m.aksCluster.Upgrades) > 0 {
+			upgrades = strings.Join(m.aksCluster.Upgrades, ", ")
+		}
+
+		m.properties = append(m.properties,
+			ui.Property{Key: "Kubernetes version", Value: orDash(props.CurrentKubernetesVersion)},
+			ui.Property{Key: "Power state", Value: powerState},
+			ui.Property{Key: "Provisioning state", Value: orDash(props.ProvisioningState)},
+			ui.Property{Key: "FQDN", Value: orDash(props.Fqdn)},
+			ui.Property{Key: "Network plugin", Value: network},
+			ui.Property{Key: "API server access", Value: apiServerAccess(props)},
+			ui.Property{Key: "Available upgrades", Value: upgrades},
+		)
+	}
+
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.15)      // 15% of width
+	modeWidth := int(float64(m.width) * 0.08)      // 8% of width
+	sizeWidth := int(float64(m.width) * 0.2)       // 20% of width
+	countWidth := int(float64(m.width) * 0.07)     // 7% of width
+	autoscaleWidth := int(float64(m.width) * 0.15) // 15% of width
+	osWidth := int(float64(m.width) * 0.1)         // 10% of width
+	versionWidth := int(float64(m.width) * 0.1)    // 10% of width
+	stateWidth := int(float64(m.width) * 0.15)     // 15% of width
+
+	columns := []table.Column{
+		{Title: "Node Pool", Width: nameWidth},
+		{Title: "Mode", Width: modeWidth},
+		{Title: "VM Size", Width: sizeWidth},
+		{Title: "Count", Width: countWidth},
+		{Title: "Autoscaler", Width: autoscaleWidth},
+		{Title: "OS", Width: osWidth},
+		{Title: "Version", Width: versionWidth},
+		{Title: "State", Width: stateWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	if cluster.Properties != nil {
+		for _, pool := range cluster.Properties.AgentPoolProfiles {
+			autoscaler := "off"
+			if pool.EnableAutoScaling != nil && *pool.EnableAutoScaling {
+				autoscaler = fmt.Sprintf("%s-%s", intOrDash(pool.MinCount), intOrDash(pool.MaxCount))
+			}
+			state := orDash(pool.ProvisioningState)
+			if pool.PowerState != nil && pool.PowerState.Code != nil {
+				state = fmt.Sprintf("%s/%s", orDash(pool.PowerState.Code), state)
+			}
+			rows = append(rows, table.Row{
+				orDash(pool.Name),
+				orDash(pool.Mode),
+				orDash(pool.VMSize),
+				intOrDash(pool.Count),
+				autoscaler,
+				orDash(pool.OSType),
+				orDash(pool.CurrentOrchestratorVersion),
+				state,
+			})
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No node pools", "-", "-", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// selectedNodePool returns the node pool under the cursor in the AKS view.
+func (m Model) selectedNodePool() (*armcontainerservice.ManagedClusterAgentPoolProfile, bool) {
+	props := m.aksCluster.Cluster.Properties
+	cursor := m.table.Cursor()
+	if props == nil || cursor < 0 || cursor >= len(props.AgentPoolProfiles) {
+		return nil, false
+	}
+	return props.AgentPoolProfiles[cursor], true
+}
+
+// handleAKSKey runs cluster and node pool actions from the AKS view. It
+// reports whether the key was an action.
+func (m *Model) handleAKSKey(key string) (tea.Cmd, bool) {
+	cluster := m.aksCluster.Cluster
+	if cluster.Properties == nil || cluster.Name == nil {
+		return nil, false
+	}
+	clusterID := m.aksID
+	clusterName := *cluster.Name
+
+	switch key {
+	case "s":
+		running := cluster.Properties.PowerState == nil || cluster.Properties.PowerState.Code == nil ||
+			*cluster.Properties.PowerState.Code == armcontainerservice.CodeRunning
+		if running {
+			m.askConfirm(fmt.Sprintf("Stop cluster %s?", clusterName), func(m *Model) tea.Cmd {
+				return m.startOperation("Stop AKS cluster "+clusterName, func(id int) tea.Cmd {
+					return azure.StopAKSCluster(id, clusterID)
+				})
+			})
+		} else {
+			m.askConfirm(fmt.Sprintf("Start cluster %s?", clusterName), func(m *Model) tea.Cmd {
+				return m.startOperation("Start AKS cluster "+clusterName, func(id int) tea.Cmd {
+					return azure.StartAKSCluster(id, clusterID)
+				})
+			})
+		}
+		return nil, true
+
+	case "K":
+		m.askKubeconfig(clusterID, clusterName)
+		return nil, true
+
+	case "u":
+		initial := ""
+		if len(m.aksCluster.Upgrades) > 0 {
+			initial = strings.TrimSuffix(m.aksCluster.Upgrades[0], " (preview)")
+		}
+		m.askValue("Upgrade control plane to version:", initial, func(m *Model, version string) tea.Cmd {
+			version = strings.TrimSpace(version)
+			if version == "" {
+				return nil
+			}
+			m.askConfirm(fmt.Sprintf("Upgrade %s control plane to %s?", clusterName, version), func(m *Model) tea.Cmd {
+				return m.startOperation(fmt.Sprintf("Upgrade %s control plane to %s", clusterName, version), func(id int) tea.Cmd {
+					return azure.UpgradeAKSControlPlane(id, clusterID, version)
+				})
+			})
+			return nil
+		})
+		return nil, true
+	}
+
+	pool, ok := m.selectedNodePool()
+	if !ok || pool.Name == nil {
+		return nil, false
+	}
+	poolName := *pool.Name
+	target := clusterName + "/" + poolName
+
+	switch key {
+	case "c":
+		m.askValue(fmt.Sprintf("Scale %s to node count:", target), intOrDash(pool.Count), func(m *Model, value string) tea.Cmd {
+			count, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+			if err != nil || count < 0 {
+				m.setFlash(fmt.Sprintf("invalid node count %q", value))
+				return nil
+			}
+			m.askConfirm(fmt.Sprintf("Scale %s to %d nodes?", target, count), func(m *Model) tea.Cmd {
+				return m.startOperation(fmt.Sprintf("Scale %s to %d nodes", target, count), func(id int) tea.Cmd {
+					return azure.ScaleAKSNodePool(id, clusterID, poolName, int32(count))
+				})
+			})
+			return nil
+		})
+		return nil, true
+
+	case "a":
+		initial := "off"
+		if pool.EnableAutoScaling != nil && *pool.EnableAutoScaling {
+			initial = intOrDash(pool.MinCount) + " " + intOrDash(pool.MaxCount)
+		}
+		m.askValue(fmt.Sprintf("Autoscaler bounds for %s (\"min max\" or \"off\"):", target), initial, func(m *Model, value string) tea.Cmd {
+			enabled, minCount, maxCount, err := parseAutoscalerBounds(value)
+			if err != nil {
+				m.setFlash(err.Error())
+				return nil
+			}
+			description := fmt.Sprintf("Disable autoscaler on %s", target)
+			if enabled {
+				description = fmt.Sprintf("Set autoscaler on %s to %d-%d nodes", target, minCount, maxCount)
+			}
+			m.askConfirm(description+"?", func(m *Model) tea.Cmd {
+				return m.startOperation(description, func(id int) tea.Cmd {
+					return azure.SetAKSAutoscaler(id, clusterID, poolName, enabled, minCount, maxCount)
+				})
+			})
+			return nil
+		})
+		return nil, true
+
+	case "i":
+		m.askConfirm(fmt.Sprintf("Upgrade %s to the latest node image?", target), func(m *Model) tea.Cmd {
+			return m.startOperation("Upgrade node image of "+target, func(id int) tea.Cmd {
+				return azure.UpgradeAKSNodeImage(id, clusterID, poolName)
+			})
+		})
+		return nil, true
+	}
+
+	return nil, false
+}
+
+// parseAutoscalerBounds parses "min max" or "off".
+func parseAutoscalerBounds(value string) (enabled bool, minCount, maxCount int32, err error) {
+	fields := strings.Fields(value)
+	if len(fields) == 1 && strings.EqualFold(fields[0], "off") {
+		return false, 0, 0, nil
+	}
+	if len(fields) != 2 {
+		return false, 0, 0, fmt.Errorf("autoscaler bounds must be \"min max\" or \"off\", got %q", value)
+	}
+	lo, err1 := strconv.ParseInt(fields[0], 10, 32)
+	hi, err2 := strconv.ParseInt(fields[1], 10, 32)
+	if err1 != nil || err2 != nil || lo < 0 || hi < lo || hi == 0 {
+		return false, 0, 0, fmt.Errorf("invalid autoscaler bounds %q", value)
+	}
+	return true, int32(lo), int32(hi), nil
+}
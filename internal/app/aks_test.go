@@ -0,0 +1,222 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testAKSID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.ContainerService/managedClusters/aks1"
+
+func newResourcesModel(resources ...armresources.GenericResourceExpanded) Model {
+	model := newMultiSubModel()
+	model.selectedSub = "sub-1"
+	model.selectedRG = "rg-a"
+	model.currentView = "resources"
+	model.selectedResourceType = "All"
+	updated, _ := model.Update(azure.ResourcesMsg{ResourceGroupName: "rg-a", Resources: resources})
+	return updated.(Model)
+}
+
+func newTestAKSCluster() armcontainerservice.ManagedCluster {
+	return armcontainerservice.ManagedCluster{
+		ID:   to.Ptr(testAKSID),
+		Name: to.Ptr("aks1"),
+		Properties: &armcontainerservice.ManagedClusterProperties{
+			CurrentKubernetesVersion: to.Ptr("1.29.2"),
+			PowerState:               &armcontainerservice.PowerState{Code: to.Ptr(armcontainerservice.CodeRunning)},
+			NetworkProfile:           &armcontainerservice.NetworkProfile{NetworkPlugin: to.Ptr(armcontainerservice.NetworkPluginAzure)},
+			APIServerAccessProfile: &armcontainerservice.ManagedClusterAPIServerAccessProfile{
+				AuthorizedIPRanges: []*string{to.Ptr("10.0.0.0/8")},
+			},
+			AgentPoolProfiles: []*armcontainerservice.ManagedClusterAgentPoolProfile{
+				{
+					Name:              to.Ptr("system"),
+					Mode:              to.Ptr(armcontainerservice.AgentPoolModeSystem),
+					VMSize:            to.Ptr("Standard_D4s_v5"),
+					Count:             to.Ptr[int32](3),
+					EnableAutoScaling: to.Ptr(true),
+					MinCount:          to.Ptr[int32](2),
+					MaxCount:          to.Ptr[int32](5),
+					OSType:            to.Ptr(armcontainerservice.OSTypeLinux),
+				},
+				{
+					Name:   to.Ptr("user"),
+					Mode:   to.Ptr(armcontainerservice.AgentPoolModeUser),
+					VMSize: to.Ptr("Standard_D8s_v5"),
+					Count:  to.Ptr[int32](1),
+				},
+			},
+		},
+	}
+}
+
+func TestOpenAKSCluster(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Storage/storageAccounts/st1"), Name: to.Ptr("st1"), Type: to.Ptr("Microsoft.Storage/storageAccounts")},
+		armresources.GenericResourceExpanded{ID: to.Ptr(testAKSID), Name: to.Ptr("aks1"), Type: to.Ptr("Microsoft.ContainerService/managedClusters")},
+	)
+
+	model.table.SetCursor(1)
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if cmd == nil || model.currentView != "aks" || model.aksID != testAKSID {
+		t.Fatalf("enter on AKS row opened %q (%s)", model.currentView, model.aksID)
+	}
+
+	updated, _ = model.Update(azure.AKSClusterMsg{ID: testAKSID, Cluster: newTestAKSCluster(), Upgrades: []string{"1.30.0"}})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("got %d node pool rows, want 2", len(rows))
+	}
+	if rows[0][4] != "2-5" || rows[1][4] != "off" {
+		t.Errorf("autoscaler columns = %q, %q, want 2-5 and off", rows[0][4], rows[1][4])
+	}
+
+	view := model.View()
+	for _, want := range []string{"1.29.2", "Running", "azure", "authorized ranges 10.0.0.0/8", "1.30.0"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q", want)
+		}
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.currentView != "resources" {
+		t.Errorf("esc returned to %q, want resources", model.currentView)
+	}
+
+	// A reply for a cluster that was left behind changes nothing.
+	model.aksCluster = azure.AKSClusterMsg{}
+	updated, _ = model.Update(azure.AKSClusterMsg{ID: testAKSID, Cluster: newTestAKSCluster()})
+	model = updated.(Model)
+	if model.aksCluster.ID != "" || model.currentView != "resources" {
+		t.Error("late cluster reply was applied outside the AKS view")
+	}
+}
+
+func TestEnterOnResourceWithoutDetailView(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Web/sites/app1"), Name: to.Ptr("app1"), Type: to.Ptr("Microsoft.Web/sites")},
+	)
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.currentView != "resources" {
+		t.Errorf("enter on a resource without details switched to %q", model.currentView)
+	}
+}
+
+func newAKSModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testAKSID), Name: to.Ptr("aks1"), Type: to.Ptr("Microsoft.ContainerService/managedClusters")},
+	)
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	updated, _ = model.Update(azure.AKSClusterMsg{ID: testAKSID, Cluster: newTestAKSCluster(), Upgrades: []string{"1.30.0"}})
+	return updated.(Model)
+}
+
+func pressKeys(model Model, keys ...tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var updated tea.Model
+		updated, cmd = model.Update(key)
+		model = updated.(Model)
+	}
+	return model, cmd
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestScaleNodePoolWithConfirmation(t *testing.T) {
+	model := newAKSModel(t)
+	model.table.SetCursor(1)
+
+	model, _ = pressKeys(model, runes("c"))
+	if model.prompt == nil || model.prompt.input.Value() != "1" {
+		t.Fatalf("c did not prompt for the node count of the selected pool")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyBackspace}, runes("4"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || !model.prompt.confirm || !strings.Contains(model.prompt.question, "aks1/user to 4 nodes") {
+		t.Fatalf("expected confirmation for scaling to 4 nodes, got %+v", model.prompt)
+	}
+
+	model, cmd := pressKeys(model, runes("y"))
+	if cmd == nil || model.prompt != nil {
+		t.Fatal("confirming did not start the operation")
+	}
+	if len(model.operations) != 1 || model.runningOperations() != 1 {
+		t.Fatalf("operations = %+v, want one running", model.operations)
+	}
+
+	updated, cmd := model.Update(azure.OperationUpdateMsg{ID: 1, Status: "InProgress", Next: func() tea.Msg { return nil }})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Error("in-progress update did not keep polling")
+	}
+
+	updated, cmd = model.Update(azure.OperationUpdateMsg{ID: 1, Status: "Succeeded", Done: true})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Error("finished operation did not refresh the cluster")
+	}
+	if model.runningOperations() != 0 || model.operations[0].status != "Succeeded" {
+		t.Errorf("operation = %+v, want finished", model.operations[0])
+	}
+}
+
+func TestDeclinedConfirmationDoesNothing(t *testing.T) {
+	model := newAKSModel(t)
+
+	model, _ = pressKeys(model, runes("s"))
+	if model.prompt == nil || !strings.Contains(model.prompt.question, "Stop cluster aks1") {
+		t.Fatalf("s on a running cluster should offer to stop it, got %+v", model.prompt)
+	}
+
+	model, cmd := pressKeys(model, runes("n"))
+	if cmd != nil || model.prompt != nil || len(model.operations) != 0 {
+		t.Error("declining the confirmation still started an operation")
+	}
+}
+
+func TestParseAutoscalerBounds(t *testing.T) {
+	tests := []struct {
+		input       string
+		wantEnabled bool
+		wantMin     int32
+		wantMax     int32
+		wantErr     bool
+	}{
+		{input: "off"},
+		{input: "2 5", wantEnabled: true, wantMin: 2, wantMax: 5},
+		{input: " 0  3 ", wantEnabled: true, wantMin: 0, wantMax: 3},
+		{input: "5 2", wantErr: true},
+		{input: "3", wantErr: true},
+		{input: "a b", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			enabled, minCount, maxCount, err := parseAutoscalerBounds(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseAutoscalerBounds(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if enabled != tt.wantEnabled || minCount != tt.wantMin || maxCount != tt.wantMax {
+				t.Errorf("parseAutoscalerBounds(%q) = %v %d %d", tt.input, enabled, minCount, maxCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,399 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// transferState tracks the download or upload shown in the transfers view.
+// transfer is nil while the files to move are still being listed.
+type transferState struct {
+	transfer    *azure.Transfer
+	description string
+	bytes       []int64
+	done        []bool
+	errs        []error
+	finished    bool
+	cancel      context.CancelFunc
+	cancelled   bool
+}
+
+func (t *transferState) running() bool {
+	return t != nil && t.transfer != nil && !t.finished
+}
+
+// handleBlobKey runs the blob actions of the storage explorer: d downloads
+// the selected blob or virtual directory, u uploads into the directory
+// being browsed and p previews the selected blob.
+func (m *Model) handleBlobKey(key string) (tea.Cmd, bool) {
+	path := m.storagePath
+	if path.Service != azure.BlobService {
+		return nil, false
+	}
+	entry, selected := m.selectedStorageEntry()
+	cwd, _ := os.Getwd()
+
+	switch key {
+	case "d":
+		if !selected || (entry.Kind != azure.StorageBlob && entry.Kind != azure.StorageDirectory) {
+			return nil, false
+		}
+		name := path.Prefix + entry.Name
+		m.askValue(fmt.Sprintf("Download %s to directory:", entry.Name), cwd, func(m *Model, dir string) tea.Cmd {
+			dir = strings.TrimSpace(dir)
+			if dir == "" {
+				return nil
+			}
+			target := filepath.Join(dir, filepath.FromSlash(strings.TrimSuffix(entry.Name, "/")))
+			_, err := os.Stat(target)
+			return m.confirmOverwrite(err == nil, target, func(m *Model, overwrite bool) tea.Cmd {
+				return m.startTransfer(fmt.Sprintf("Download %s/%s to %s", path.Container, name, dir), func(ctx context.Context, id int) tea.Cmd {
+					return azure.StartDownload(ctx, id, m.storage, path.Container, path.Prefix, name, dir, overwrite)
+				})
+			})
+		})
+		return nil, true
+
+	case "u":
+		m.askValue(fmt.Sprintf("Upload file or directory into %s/%s:", path.Container, path.Prefix), cwd+string(os.PathSeparator), func(m *Model, local string) tea.Cmd {
+			local = strings.TrimSpace(local)
+			if local == "" {
+				return nil
+			}
+			base := filepath.Base(local)
+			exists := false
+			for _, entry := range m.storageEntries {
+				exists = exists || entry.Name == base || entry.Name == base+"/"
+			}
+			return m.confirmOverwrite(exists, path.Container+"/"+path.Prefix+base, func(m *Model, overwrite bool) tea.Cmd {
+				return m.startTransfer(fmt.Sprintf("Upload %s to %s/%s", local, path.Container, path.Prefix), func(ctx context.Context, id int) tea.Cmd {
+					return azure.StartUpload(ctx, id, m.storage, path.Container, path.Prefix, local, overwrite)
+				})
+			})
+		})
+		return nil, true
+
+	case "p":
+		if !selected || entry.Kind != azure.StorageBlob {
+			return nil, false
+		}
+		return m.openBlobPreview(path.Container, path.Prefix+entry.Name), true
+	}
+	return nil, false
+}
+
+// confirmOverwrite starts a transfer that leaves existing files and blobs
+// alone, or, when target already exists, asks whether to replace what is
+// there first.
+func (m *Model) confirmOverwrite(exists bool, target string, start func(m *Model, overwrite bool) tea.Cmd) tea.Cmd {
+	if !exists {
+		return start(m, false)
+	}
+	m.askConfirm(fmt.Sprintf("%s already exists. Overwrite?", target), func(m *Model) tea.Cmd {
+		return start(m, true)
+	})
+	return nil
+}
+
+// startTransfer opens the transfers view for a new download or upload.
+// Only one transfer runs at a time.
+func (m *Model) startTransfer(description string, start func(ctx context.Context, id int) tea.Cmd) tea.Cmd {
+	if m.transfer.running() {
+		m.setFlash("a transfer is already running")
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.transferID++
+	m.transfer = &transferState{description: description, cancel: cancel}
+	m.openDetailView("transfers")
+	return start(ctx, m.transferID)
+}
+
+func (m *Model) applyTransferStarted(msg azure.TransferStartedMsg) tea.Cmd {
+	if m.transfer == nil || msg.Transfer.ID != m.transferID {
+		return nil
+	}
+	files := len(msg.Transfer.Files)
+	m.transfer.transfer = msg.Transfer
+	m.transfer.bytes = make([]int64, files)
+	m.transfer.done = make([]bool, files)
+	m.transfer.errs = make([]error, files)
+	if m.currentView == "transfers" {
+		m.loading = false
+		m.updateLayout(m.width, m.height)
+	}
+	return msg.Transfer.Next()
+}
+
+// applyTransferUpdate records the progress of one file and waits for the
+// next update until the transfer has finished.
+func (m *Model) applyTransferUpdate(msg azure.TransferUpdateMsg) tea.Cmd {
+	t := m.transfer
+	if t == nil || t.transfer == nil || msg.ID != m.transferID {
+		return nil
+	}
+	if msg.Finished {
+		t.finished = true
+		t.cancel()
+		ok, failed := t.counts()
+		verb := "Uploaded"
+		if t.transfer.Download {
+			verb = "Downloaded"
+		}
+		summary := fmt.Sprintf("%s %d of %d files", verb, ok, len(t.transfer.Files))
+		if failed > 0 {
+			summary += fmt.Sprintf(", %d failed", failed)
+		}
+		m.setFlash(summary)
+	} else if msg.Index >= 0 && msg.Index < len(t.bytes) {
+		t.bytes[msg.Index] = max(t.bytes[msg.Index], msg.Bytes)
+		if msg.Done {
+			t.done[msg.Index] = true
+			t.errs[msg.Index] = msg.Err
+		}
+	}
+	if m.currentView == "transfers" {
+		m.updateLayout(m.width, m.height)
+	}
+	if t.finished {
+		return nil
+	}
+	return t.transfer.Next()
+}
+
+// counts returns how many files finished and how many failed.
+func (t *transferState) counts() (ok, failed int) {
+	for i, done := range t.done {
+		switch {
+		case !done:
+		case t.errs[i] != nil:
+			failed++
+		default:
+			ok++
+		}
+	}
+	return ok, failed
+}
+
+// handleTransferKey makes esc cancel a running transfer after confirmation
+// and, once it is over, leave the view, reloading the directory an upload
+// went to.
+func (m *Model) handleTransferKey(key string) (tea.Cmd, bool) {
+	if key != "esc" || m.transfer == nil {
+		return nil, false
+	}
+	t := m.transfer
+	if t.transfer == nil && !t.finished {
+		// Still listing what to move.
+		t.cancel()
+		t.finished = true
+		return nil, false
+	}
+	if t.running() {
+		if t.cancelled {
+			return nil, true
+		}
+		m.askConfirm("Cancel the transfer?", func(m *Model) tea.Cmd {
+			m.transfer.cancelled = true
+			m.transfer.cancel()
+			m.setFlash("Cancelling transfer…")
+			return nil
+		})
+		return nil, true
+	}
+
+	m.closeDetailView()
+	if m.currentView == "storage" && !t.transfer.Download {
+		return m.browseStorage(m.storagePath, ""), true
+	}
+	return nil, true
+}
+
+// progressBar draws done of total as a bar width cells wide followed by a
+// percentage.
+func progressBar(done, total int64, width int) string {
+	fraction := 1.0
+	if total > 0 {
+		fraction = min(float64(done)/float64(total), 1)
+	}
+	filled := int(fraction * float64(width))
+	return strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + fmt.Sprintf(" %3d%%", int(fraction*100))
+}
+
+func (m *Model) updateTableWithTransfers() {
+	t := m.transfer
+	if t == nil || t.transfer == nil {
+		return
+	}
+
+	var moved, total int64
+	for i, file := range t.transfer.Files {
+		moved += min(t.bytes[i], file.Size)
+		total += file.Size
+	}
+	ok, failed := t.counts()
+	state := "running"
+	switch {
+	case t.finished && t.cancelled:
+		state = "cancelled"
+	case t.finished:
+		state = "finished"
+	case t.cancelled:
+		state = "cancelling"
+	}
+	m.properties = []ui.Property{
+		{Key: "Transfer", Value: t.description},
+		{Key: "State", Value: state},
+		{Key: "Files", Value: fmt.Sprintf("%d of %d done, %d failed", ok, len(t.transfer.Files), failed)},
+		{Key: "Bytes", Value: fmt.Sprintf("%s of %s", formatBytes(moved), formatBytes(total))},
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	fileWidth := int(float64(m.width) * 0.4)      // 40% of width
+	sizeWidth := int(float64(m.width) * 0.1)      // 10% of width
+	progressWidth := int(float64(m.width) * 0.25) // 25% of width
+	statusWidth := int(float64(m.width) * 0.25)   // 25% of width
+
+	columns := []table.Column{
+		{Title: "File", Width: fileWidth},
+		{Title: "Size", Width: sizeWidth},
+		{Title: "Progress", Width: progressWidth},
+		{Title: "Status", Width: statusWidth},
+	}
+	m.table.SetColumns(columns)
+
+	barWidth := max(progressWidth-6, 5)
+	rows := make([]table.Row, 0, len(t.transfer.Files))
+	for i, file := range t.transfer.Files {
+		status := "queued"
+		switch {
+		case t.done[i] && t.errs[i] != nil && (t.cancelled || errors.Is(t.errs[i], context.Canceled)):
+			status = "cancelled"
+		case t.done[i] && t.errs[i] != nil:
+			status = "failed: " + t.errs[i].Error()
+		case t.done[i]:
+			status = "done"
+		case t.bytes[i] > 0:
+			status = "transferring"
+		}
+		name := file.Blob
+		if t.transfer.Download {
+			name = file.Local
+		}
+		bar := progressBar(t.bytes[i], file.Size, barWidth)
+		if t.done[i] && t.errs[i] == nil {
+			bar = progressBar(1, 1, barWidth)
+		}
+		rows = append(rows, table.Row{name, formatBytes(file.Size), bar, status})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+func (m *Model) openBlobPreview(containerName, name string) tea.Cmd {
+	m.openDetailView("blobpreview")
+	m.properties = []ui.Property{{Key: "Blob", Value: containerName + "/" + name}}
+	m.preview = newTextPane()
+	m.previewBlob = containerName + "/" + name
+	m.updateLayout(m.width, m.height)
+	return azure.FetchBlobPreview(m.storage, containerName, name)
+}
+
+func (m *Model) applyBlobPreview(msg azure.BlobPreviewMsg) {
+	if m.currentView != "blobpreview" || msg.AccountID != m.storage.ID || msg.Container+"/"+msg.Name != m.previewBlob {
+		return
+	}
+	m.loading = false
+	m.err = nil
+
+	truncated := int64(len(msg.Data)) < msg.Size
+	showing := "all"
+	if truncated {
+		showing = fmt.Sprintf("first %s of %s", formatBytes(int64(len(msg.Data))), formatBytes(msg.Size))
+	}
+	contentType := msg.ContentType
+	if contentType == "" {
+		contentType = "-"
+	}
+	m.properties = []ui.Property{
+		{Key: "Blob", Value: m.previewBlob},
+		{Key: "Size", Value: formatBytes(msg.Size)},
+		{Key: "Content type", Value: contentType},
+		{Key: "Showing", Value: showing},
+	}
+
+	text, err := formatPreview(msg.Name, msg.ContentType, msg.Data, truncated)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.preview.setText(text)
+	m.updateLayout(m.width, m.height)
+}
+
+// formatPreview renders blob content for the preview pane: JSON is
+// indented and CSV aligned in columns. Content that is not text is
+// refused rather than dumped on the terminal.
+func formatPreview(name, contentType string, data []byte, truncated bool) (string, error) {
+	if truncated {
+		// The cut may fall inside a multi-byte character.
+		for i := 0; i < utf8.UTFMax && len(data) > 0 && !utf8.Valid(data); i++ {
+			data = data[:len(data)-1]
+		}
+	}
+	if bytes.IndexByte(data, 0) >= 0 || !utf8.Valid(data) {
+		return "", fmt.Errorf("%s is not text; download it with d instead", name)
+	}
+
+	ext := strings.ToLower(path.Ext(name))
+	switch {
+	case !truncated && (ext == ".json" || strings.Contains(contentType, "json")):
+		var indented bytes.Buffer
+		if json.Indent(&indented, data, "", "  ") == nil {
+			return indented.String(), nil
+		}
+	case ext == ".csv" || strings.HasPrefix(contentType, "text/csv"):
+		if truncated {
+			// Drop the partial last record.
+			if i := bytes.LastIndexByte(data, '\n'); i >= 0 {
+				data = data[:i+1]
+			}
+		}
+		reader := csv.NewReader(bytes.NewReader(data))
+		reader.FieldsPerRecord = -1
+		reader.LazyQuotes = true
+		records, err := reader.ReadAll()
+		if err != nil {
+			break
+		}
+		var aligned bytes.Buffer
+		w := tabwriter.NewWriter(&aligned, 0, 0, 2, ' ', 0)
+		for _, record := range records {
+			fmt.Fprintln(w, strings.Join(record, "\t"))
+		}
+		w.Flush()
+		return aligned.String(), nil
+	}
+	return string(data), nil
+}
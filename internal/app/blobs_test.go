@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+// newContainerModel returns the storage explorer inside container logs,
+// at virtual directory 2024/.
+func newContainerModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newStorageModel(t)
+	path := azure.StoragePath{Service: azure.BlobService, Container: "logs", Prefix: "2024/"}
+	model.browseStorage(path, "")
+	updated, _ := model.Update(storagePathMsg(path,
+		azure.StorageEntry{Name: "10/", Kind: azure.StorageDirectory, Size: -1},
+		azure.StorageEntry{Name: "app.json", Kind: azure.StorageBlob, Size: 12},
+	))
+	return updated.(Model)
+}
+
+func TestDownloadDirectoryWithProgress(t *testing.T) {
+	model := newContainerModel(t)
+
+	model, _ = pressKeys(model, runes("d"))
+	if model.prompt == nil || !strings.Contains(model.prompt.question, "Download 10/") {
+		t.Fatalf("d did not ask where to download the directory, got %+v", model.prompt)
+	}
+	model.prompt.input.SetValue(t.TempDir())
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "transfers" || !model.loading {
+		t.Fatalf("download opened %q (loading %v)", model.currentView, model.loading)
+	}
+	if !strings.Contains(model.transfer.description, "logs/2024/10/") {
+		t.Errorf("transfer = %q, want the directory's full prefix", model.transfer.description)
+	}
+
+	transfer := azure.NewTransfer(model.transferID, true, []azure.TransferFile{
+		{Blob: "2024/10/a.log", Local: "/tmp/x/10/a.log", Size: 100},
+		{Blob: "2024/10/b.log", Local: "/tmp/x/10/b.log", Size: 300},
+	})
+	updated, cmd := model.Update(azure.TransferStartedMsg{Transfer: transfer})
+	model = updated.(Model)
+	if cmd == nil || model.loading {
+		t.Fatal("a started transfer did not wait for progress")
+	}
+
+	updated, cmd = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: 1, Bytes: 150})
+	model = updated.(Model)
+	rows := model.table.Rows()
+	if cmd == nil || len(rows) != 2 || !strings.Contains(rows[1][2], "50%") || rows[1][3] != "transferring" {
+		t.Fatalf("rows after progress = %v", rows)
+	}
+
+	updated, _ = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: 0, Bytes: 100, Done: true})
+	model = updated.(Model)
+	updated, _ = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: 1, Bytes: 300, Done: true, Err: errors.New("disk full")})
+	model = updated.(Model)
+	updated, cmd = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: -1, Finished: true})
+	model = updated.(Model)
+	if cmd != nil || model.transfer.running() {
+		t.Error("transfer still running after it finished")
+	}
+	if !strings.Contains(model.flash, "Downloaded 1 of 2 files, 1 failed") {
+		t.Errorf("flash = %q", model.flash)
+	}
+	if rows = model.table.Rows(); rows[0][3] != "done" || rows[1][3] != "failed: disk full" {
+		t.Errorf("final rows = %v", rows)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "storage" {
+		t.Errorf("esc after the transfer returned to %q, want storage", model.currentView)
+	}
+}
+
+func TestTransferAsksBeforeOverwriting(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "10"), 0o755)
+
+	for _, answer := range []string{"n", "y"} {
+		model := newContainerModel(t)
+		model, _ = pressKeys(model, runes("d"))
+		model.prompt.input.SetValue(dir)
+		model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd != nil || model.prompt == nil || !strings.Contains(model.prompt.question, "already exists") {
+			t.Fatalf("download over an existing directory did not ask first, prompt %+v", model.prompt)
+		}
+		model, cmd = pressKeys(model, runes(answer))
+		if started := model.currentView == "transfers"; started != (answer == "y") || (cmd != nil) != started {
+			t.Errorf("answering %s to overwriting opened %q", answer, model.currentView)
+		}
+	}
+
+	model := newContainerModel(t)
+	model, _ = pressKeys(model, runes("u"))
+	model.prompt.input.SetValue(filepath.Join(dir, "app.json"))
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || !strings.Contains(model.prompt.question, "logs/2024/app.json already exists") {
+		t.Fatalf("upload over an existing blob did not ask first, prompt %+v", model.prompt)
+	}
+	model, cmd := pressKeys(model, runes("y"))
+	if cmd == nil || model.currentView != "transfers" {
+		t.Errorf("confirmed upload opened %q", model.currentView)
+	}
+
+	model = newContainerModel(t)
+	model, _ = pressKeys(model, runes("u"))
+	model.prompt.input.SetValue(filepath.Join(dir, "new.json"))
+	if model, cmd = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}); cmd == nil || model.currentView != "transfers" {
+		t.Errorf("upload of a new blob asked first, prompt %+v", model.prompt)
+	}
+}
+
+func TestCancelTransfer(t *testing.T) {
+	model := newContainerModel(t)
+
+	var ctx context.Context
+	cmd := model.startTransfer("Upload site", func(c context.Context, id int) tea.Cmd {
+		ctx = c
+		return func() tea.Msg { return nil }
+	})
+	if cmd == nil {
+		t.Fatal("startTransfer returned no command")
+	}
+	transfer := azure.NewTransfer(model.transferID, false, []azure.TransferFile{{Blob: "2024/site/index.html", Size: 10}})
+	updated, _ := model.Update(azure.TransferStartedMsg{Transfer: transfer})
+	model = updated.(Model)
+
+	if model.startTransfer("Another", nil) != nil || !strings.Contains(model.flash, "already running") {
+		t.Error("a second transfer started while one was running")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.prompt == nil || model.currentView != "transfers" {
+		t.Fatal("esc during a transfer did not ask to cancel it")
+	}
+	model, _ = pressKeys(model, runes("y"))
+	if ctx.Err() == nil {
+		t.Fatal("confirming did not cancel the transfer")
+	}
+
+	updated, _ = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: 0, Done: true, Err: context.Canceled})
+	model = updated.(Model)
+	updated, _ = model.Update(azure.TransferUpdateMsg{ID: transfer.ID, Index: -1, Finished: true})
+	model = updated.(Model)
+	if rows := model.table.Rows(); rows[0][3] != "cancelled" {
+		t.Errorf("rows = %v, want the file cancelled", rows)
+	}
+
+	model, cmd = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "storage" || cmd == nil {
+		t.Errorf("leaving a finished upload returned to %q without reloading", model.currentView)
+	}
+}
+
+func TestBlobPreview(t *testing.T) {
+	model := newContainerModel(t)
+	model.table.SetCursor(1)
+
+	model, cmd := pressKeys(model, runes("p"))
+	if cmd == nil || model.currentView != "blobpreview" || model.previewBlob != "logs/2024/app.json" {
+		t.Fatalf("p opened %q for %q", model.currentView, model.previewBlob)
+	}
+
+	updated, _ := model.Update(azure.BlobPreviewMsg{AccountID: testStorageID, Container: "logs", Name: "2024/app.json", Size: 11, Data: []byte(`{"level":1}`)})
+	model = updated.(Model)
+	if !strings.Contains(model.View(), `"level": 1`) {
+		t.Error("JSON blob was not indented in the preview")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "storage" {
+		t.Errorf("esc returned to %q, want storage", model.currentView)
+	}
+}
+
+func TestFormatPreview(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		data      string
+		truncated bool
+		want      string
+		wantErr   bool
+	}{
+		{name: "json", file: "a.json", data: `{"a":[1,2]}`, want: "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{name: "truncated json stays raw", file: "a.json", data: `{"a":[1,`, truncated: true, want: `{"a":[1,`},
+		{name: "csv", file: "a.csv", data: "id,name\n1,alpha\n22,b\n", want: "id  name\n1   alpha\n22  b\n"},
+		{name: "truncated csv drops partial row", file: "a.csv", data: "id,name\n1,alpha\n22,b", truncated: true, want: "id  name\n1   alpha\n"},
+		{name: "text", file: "a.log", data: "line 1\nline 2", want: "line 1\nline 2"},
+		{name: "cut multibyte character", file: "a.txt", data: "caf\xc3", truncated: true, want: "caf"},
+		{name: "binary", file: "a.bin", data: "PK\x03\x04\x00\x00", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatPreview(tt.file, "", []byte(tt.data), tt.truncated)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("formatPreview() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatPreview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	if got := progressBar(50, 200, 8); got != "██░░░░░░  25%" {
+		t.Errorf("progressBar(50, 200) = %q", got)
+	}
+	if got := progressBar(0, 0, 4); got != "████ 100%" {
+		t.Errorf("progressBar of an empty file = %q", got)
+	}
+}
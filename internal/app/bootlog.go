@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// bootLogFollowInterval is how often a followed serial log is re-read.
+var bootLogFollowInterval = 5 * time.Second
+
+// bootLogTickMsg asks for the next read of a followed serial log. Ticks
+// from an earlier follow session carry a stale gen and are dropped.
+type bootLogTickMsg struct {
+	gen int
+}
+
+// bootLogReadMsg is a read of the serial log started in follow session
+// gen. Reads from an earlier session are dropped so that toggling follow
+// while one is in flight does not start a second chain of reads.
+type bootLogReadMsg struct {
+	gen int
+	log azure.BootLogMsg
+}
+
+func (m *Model) openBootLog(resourceID, name string) tea.Cmd {
+	m.openDetailView("bootlog")
+	m.properties = []ui.Property{{Key: "Serial log", Value: name}}
+	m.bootLog = newTextPane()
+	m.bootLog.follow = true
+	m.bootLogVM = resourceID
+	m.bootLogURI = ""
+	m.bootLogRenewed = false
+	m.bootLogOffset = 0
+	m.bootLogGen++
+	m.updateLayout(m.width, m.height)
+	return azure.FetchBootLogURI(resourceID)
+}
+
+// readBootLog reads the serial log from the current offset, tagged with the
+// current follow session.
+func (m *Model) readBootLog() tea.Cmd {
+	gen := m.bootLogGen
+	read := azure.FetchBootLog(m.bootLogURI, m.bootLogOffset)
+	return func() tea.Msg {
+		msg := read()
+		if log, ok := msg.(azure.BootLogMsg); ok {
+			return bootLogReadMsg{gen: gen, log: log}
+		}
+		return msg
+	}
+}
+
+// applyBootLog appends a chunk of the serial log and schedules the next read
+// while following. A refused URI, which happens once its SAS expires, is
+// replaced once; reading goes on from the same offset with the new one.
+func (m *Model) applyBootLog(read bootLogReadMsg) tea.Cmd {
+	msg := read.log
+	if m.currentView != "bootlog" || read.gen != m.bootLogGen || msg.URI != m.bootLogURI || msg.From != m.bootLogOffset {
+		return nil
+	}
+	if msg.Expired {
+		if m.bootLogRenewed {
+			m.loading = false
+			m.err = errors.New("the storage account refused a new serial log URI too")
+			return nil
+		}
+		m.bootLogRenewed = true
+		return azure.FetchBootLogURI(m.bootLogVM)
+	}
+	m.loading = false
+	m.err = nil
+	m.bootLogRenewed = false
+	m.bootLogOffset += int64(len(msg.Data))
+	if len(msg.Data) > 0 {
+		m.bootLog.appendText(string(msg.Data))
+	}
+	return m.nextBootLogRead()
+}
+
+func (m *Model) nextBootLogRead() tea.Cmd {
+	if !m.bootLog.follow {
+		return nil
+	}
+	gen := m.bootLogGen
+	return tea.Tick(bootLogFollowInterval, func(time.Time) tea.Msg {
+		return bootLogTickMsg{gen: gen}
+	})
+}
+
+// handleBootLogKey toggles follow mode and passes scrolling and search keys
+// to the log pane. It reports whether the key was used.
+func (m *Model) handleBootLogKey(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if msg.String() == "f" && !m.bootLog.searching {
+		m.bootLog.follow = !m.bootLog.follow
+		m.bootLogGen++
+		if !m.bootLog.follow {
+			return nil, true
+		}
+		m.bootLog.viewport.GotoBottom()
+		return m.readBootLog(), true
+	}
+	return m.bootLog.update(msg)
+}
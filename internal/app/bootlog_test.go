@@ -0,0 +1,147 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testBootLogURI = "https://bootdiag.blob.core.windows.net/bootdiagnostics/vm1.serialconsole.log?sig=abc"
+
+func newBootLogModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	updated, _ := model.Update(azure.VMMsg{ID: testVMID, VM: newTestVM()})
+	model = updated.(Model)
+
+	model, cmd := pressKeys(model, runes("l"))
+	if cmd == nil || model.currentView != "bootlog" {
+		t.Fatalf("l in the VM view opened %q", model.currentView)
+	}
+	updated, cmd = model.Update(azure.BootLogURIMsg{VMID: testVMID, URI: testBootLogURI})
+	if cmd == nil {
+		t.Fatal("serial log URI did not start reading the log")
+	}
+	return updated.(Model)
+}
+
+// bootLogRead tags a read with the model's current follow session.
+func bootLogRead(model Model, msg azure.BootLogMsg) bootLogReadMsg {
+	return bootLogReadMsg{gen: model.bootLogGen, log: msg}
+}
+
+func TestBootLogFollow(t *testing.T) {
+	model := newBootLogModel(t)
+
+	updated, cmd := model.Update(bootLogRead(model, azure.BootLogMsg{URI: testBootLogURI, From: 0, Data: []byte("line 1\r\nline 2\npart")}))
+	model = updated.(Model)
+	if cmd == nil {
+		t.Error("following log did not schedule the next read")
+	}
+	if model.bootLogOffset != 19 {
+		t.Errorf("offset = %d, want 19", model.bootLogOffset)
+	}
+
+	// A duplicate read of the same range is dropped.
+	updated, _ = model.Update(bootLogRead(model, azure.BootLogMsg{URI: testBootLogURI, From: 0, Data: []byte("line 1\r\nline 2\npart")}))
+	model = updated.(Model)
+
+	updated, _ = model.Update(bootLogRead(model, azure.BootLogMsg{URI: testBootLogURI, From: 19, Data: []byte("ial\nline 4\n")}))
+	model = updated.(Model)
+	if got, want := model.bootLog.allLines(), []string{"line 1", "line 2", "partial", "line 4"}; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("log lines = %q, want %q", got, want)
+	}
+
+	model, cmd = pressKeys(model, runes("f"))
+	if model.bootLog.follow || cmd != nil {
+		t.Error("f did not stop following")
+	}
+	gen := model.bootLogGen
+	if _, cmd := model.Update(bootLogTickMsg{gen: gen - 1}); cmd != nil {
+		t.Error("tick from an earlier follow session read the log")
+	}
+
+	model, cmd = pressKeys(model, runes("f"))
+	if !model.bootLog.follow || cmd == nil {
+		t.Error("f did not resume following")
+	}
+	// A read started before f was pressed twice must not start a second
+	// chain of reads next to the one f just started.
+	updated, cmd = model.Update(bootLogReadMsg{gen: gen - 1, log: azure.BootLogMsg{URI: testBootLogURI, From: 30, Data: []byte("line 5\n")}})
+	model = updated.(Model)
+	if cmd != nil || model.bootLogOffset != 30 {
+		t.Errorf("read from an earlier follow session was applied (offset %d)", model.bootLogOffset)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "vm" {
+		t.Errorf("esc from serial log went to %q, want vm", model.currentView)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resources" {
+		t.Errorf("esc from VM view went to %q, want resources", model.currentView)
+	}
+}
+
+func TestBootLogRenewsRefusedURI(t *testing.T) {
+	model := newBootLogModel(t)
+	updated, _ := model.Update(bootLogRead(model, azure.BootLogMsg{URI: testBootLogURI, Data: []byte("line 1\n")}))
+	model = updated.(Model)
+
+	updated, cmd := model.Update(bootLogRead(model, azure.BootLogMsg{URI: testBootLogURI, From: 7, Expired: true}))
+	model = updated.(Model)
+	if cmd == nil || !model.bootLogRenewed {
+		t.Fatal("refused URI did not fetch a new one")
+	}
+
+	const renewed = testBootLogURI + "&se=later"
+	updated, cmd = model.Update(azure.BootLogURIMsg{VMID: testVMID, URI: renewed})
+	model = updated.(Model)
+	if cmd == nil || model.bootLogURI != renewed || model.bootLogOffset != 7 {
+		t.Fatalf("new URI %q at offset %d, want %q at 7", model.bootLogURI, model.bootLogOffset, renewed)
+	}
+
+	// A new URI that is refused as well is an error, not a loop.
+	updated, cmd = model.Update(bootLogRead(model, azure.BootLogMsg{URI: renewed, From: 7, Expired: true}))
+	model = updated.(Model)
+	if cmd != nil || model.err == nil || model.loading {
+		t.Errorf("second refusal returned cmd %v, err %v", cmd != nil, model.err)
+	}
+}
+
+func TestBootLogSearch(t *testing.T) {
+	model := newBootLogModel(t)
+	updated, _ := model.Update(bootLogRead(model, azure.BootLogMsg{
+		URI:  testBootLogURI,
+		Data: []byte("Booting\nEXT4-fs error: bad superblock\nok\nKernel panic - not syncing\next4 retry\n"),
+	}))
+	model = updated.(Model)
+
+	model, _ = pressKeys(model, runes("/"), runes("ext4"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.bootLog.follow {
+		t.Error("jumping to a match kept following")
+	}
+	if got := model.bootLog.matches; len(got) != 2 || got[0] != 1 || got[1] != 4 {
+		t.Fatalf("matches = %v, want lines 1 and 4", got)
+	}
+	if !strings.Contains(model.bootLog.status(), "match 1/2") {
+		t.Errorf("status = %q, want match 1/2", model.bootLog.status())
+	}
+
+	model, _ = pressKeys(model, runes("n"))
+	if !strings.Contains(model.bootLog.status(), "match 2/2") {
+		t.Errorf("status after n = %q, want match 2/2", model.bootLog.status())
+	}
+	model, _ = pressKeys(model, runes("N"))
+	if !strings.Contains(model.bootLog.status(), "match 1/2") {
+		t.Errorf("status after N = %q, want match 1/2", model.bootLog.status())
+	}
+}
@@ -0,0 +1,16 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+// copyToClipboard sets the clipboard with an OSC 52 escape sequence, so it
+// also works over SSH. Terminals that do not support OSC 52 ignore it; tmux
+// needs set-clipboard enabled.
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(text)
+		return nil
+	}
+}
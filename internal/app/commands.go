@@ -0,0 +1,105 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// updateCommandMode handles keys typed at the ":" prompt.
+func (m Model) updateCommandMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.commandMode = false
+		m.commandInput = ""
+		m.updateLayout(m.width, m.height)
+	case tea.KeyBackspace:
+		if len(m.commandInput) > 0 {
+			m.commandInput = m.commandInput[:len(m.commandInput)-1]
+		}
+	case tea.KeyEnter:
+		input := m.commandInput
+		m.commandMode = false
+		m.commandInput = ""
+		m.updateLayout(m.width, m.height)
+		return m.runCommand(input)
+	case tea.KeyRunes, tea.KeySpace:
+		m.commandInput += string(msg.Runes)
+	}
+	return m, nil
+}
+
+// runCommand executes a command entered at the ":" prompt.
+func (m Model) runCommand(input string) (tea.Model, tea.Cmd) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return m, nil
+	}
+
+	switch fields[0] {
+	case "whoami":
+		return m, m.openWhoami()
+	case "ops", "operations":
+		m.openOperations()
+		return m, nil
+	case "disks":
+		subscriptionID, resourceGroup := m.currentSubscriptionAndGroup()
+		return m, m.openDisks(subscriptionID, resourceGroup, "")
+	case "pips":
+		subscriptionID, resourceGroup := m.currentSubscriptionAndGroup()
+		return m, m.openPublicIPs(subscriptionID, resourceGroup, "")
+	case "privatelink", "pe":
+		subscriptionID, resourceGroup := m.currentSubscriptionAndGroup()
+		return m, m.openPrivateLink(subscriptionID, resourceGroup, "", false)
+	case "expiring":
+		days := defaultExpiringDays
+		if len(fields) > 1 {
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 {
+				m.setFlash("usage: :expiring [days]")
+				return m, nil
+			}
+			days = n
+		}
+		return m, m.openExpiring(days)
+	case "run":
+		return m, m.startRun(fields[1:])
+	case "q", "quit":
+		return m, tea.Quit
+	default:
+		m.setFlash(fmt.Sprintf("unknown command %q", fields[0]))
+		return m, nil
+	}
+}
+
+// openDetailView switches to a view that returns to the current one on esc.
+func (m *Model) openDetailView(view string) {
+	m.viewStack = append(m.viewStack, m.currentView)
+	m.currentView = view
+	m.properties = nil
+	m.loading = true
+	m.err = nil
+}
+
+// closeDetailView returns to the view a detail view was opened from.
+func (m *Model) closeDetailView() {
+	m.currentView = m.previousView()
+	m.viewStack = m.viewStack[:len(m.viewStack)-1]
+	m.properties = nil
+	// A reply still on its way is dropped by the view it was meant for, so
+	// nothing else would stop the spinner.
+	m.loading = false
+	m.err = nil
+	m.updateLayout(m.width, m.height)
+}
+
+// previousView returns the view esc goes back to from a detail view, or ""
+// outside detail views.
+func (m Model) previousView() string {
+	if len(m.viewStack) == 0 {
+		return ""
+	}
+	return m.viewStack[len(m.viewStack)-1]
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func typeCommand(t *testing.T, model Model, command string) (Model, tea.Cmd) {
+	t.Helper()
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	model = updated.(Model)
+	if !model.commandMode {
+		t.Fatal(": did not open the command prompt")
+	}
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(command)})
+	model = updated.(Model)
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	return updated.(Model), cmd
+}
+
+func TestUnknownCommand(t *testing.T) {
+	model, cmd := typeCommand(t, newMultiSubModel(), "bogus")
+	if cmd != nil || !strings.Contains(model.flash, "bogus") {
+		t.Errorf("unknown command returned cmd %v, flash %q", cmd, model.flash)
+	}
+	if model.commandMode {
+		t.Error("command prompt still open after enter")
+	}
+}
+
+func TestWhoamiCommand(t *testing.T) {
+	model := newMultiSubModel()
+	model.table.SetCursor(1)
+
+	if got := model.currentScope(); got != "/subscriptions/sub-2" {
+		t.Fatalf("currentScope() = %q, want /subscriptions/sub-2", got)
+	}
+
+	model, cmd := typeCommand(t, model, "whoami")
+	if cmd == nil || model.currentView != "whoami" || model.previousView() != "subscriptions" {
+		t.Fatalf(":whoami opened view %q from %q", model.currentView, model.previousView())
+	}
+
+	updated, _ := model.Update(azure.WhoamiMsg{
+		Principal: "jane@contoso.com",
+		ObjectID:  "oid-1",
+		TenantID:  "tenant-1",
+		Scope:     "/subscriptions/sub-2",
+		Assignments: []azure.RoleAssignment{
+			{Role: "Reader", Scope: "/subscriptions/sub-2", PrincipalType: "User", Applies: azure.AppliesHere},
+			{Role: "Owner", Scope: "/", PrincipalType: "Group", Applies: azure.AppliesInherited},
+			{Role: "Contributor", Scope: "/subscriptions/sub-2/resourceGroups/rg-app", PrincipalType: "User", Applies: azure.AppliesBelow},
+		},
+		AssignmentsErr: nil,
+	})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 3 || rows[1][3] != "inherited" || rows[2][3] != "child scope" {
+		t.Errorf("rows = %v, want Reader, inherited Owner and Contributor on a child scope", rows)
+	}
+	if !strings.Contains(model.View(), "oid-1") {
+		t.Error("view does not show the object ID")
+	}
+
+	updated, _ = model.Update(azure.WhoamiMsg{Principal: "jane@contoso.com", AssignmentsErr: errors.New("AuthorizationFailed")})
+	model = updated.(Model)
+	if !strings.Contains(model.View(), "AuthorizationFailed") {
+		t.Error("view does not report the role assignment error")
+	}
+	if rows := model.table.Rows(); len(rows) != 1 || strings.HasPrefix(rows[0][0], "No role assignments") || rows[0][1] != "AuthorizationFailed" {
+		t.Errorf("rows = %v, want the listing error rather than no assignments", rows)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.currentView != "subscriptions" || len(model.properties) != 0 {
+		t.Errorf("esc returned to %q with %d properties", model.currentView, len(model.properties))
+	}
+	if len(model.table.Rows()) != 3 {
+		t.Errorf("subscriptions table not restored, got %d rows", len(model.table.Rows()))
+	}
+}
+
+func TestEscWhileDetailViewLoads(t *testing.T) {
+	model := newMultiSubModel()
+	model, _ = typeCommand(t, model, "whoami")
+	if !model.loading {
+		t.Fatal(":whoami did not start loading")
+	}
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	model = updated.(Model)
+	if model.currentView != "subscriptions" || model.loading {
+		t.Errorf("esc while loading left view %q loading=%v", model.currentView, model.loading)
+	}
+}
@@ -0,0 +1,102 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// selectedResource returns the resource under the cursor in the resources
+// view.
+func (m Model) selectedResource() (armresources.GenericResourceExpanded, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rowResources) {
+		return armresources.GenericResourceExpanded{}, false
+	}
+	return m.rowResources[cursor], true
+}
+
+// openResource opens the detail view for the resource under the cursor, if
+// its type has one.
+func (m *Model) openResource() tea.Cmd {
+	resource, ok := m.selectedResource()
+	if !ok || resource.ID == nil || resource.Type == nil {
+		return nil
+	}
+
+	switch strings.ToLower(*resource.Type) {
+	case "microsoft.containerservice/managedclusters":
+		return m.openAKS(*resource.ID)
+	case "microsoft.compute/virtualmachines":
+		return m.openVM(*resource.ID)
+	case "microsoft.compute/virtualmachinescalesets":
+		return m.openVMSS(*resource.ID)
+	case "microsoft.network/publicipaddresses":
+		id, err := arm.ParseResourceID(*resource.ID)
+		if err != nil {
+			return nil
+		}
+		return m.openPublicIPs(id.SubscriptionID, id.ResourceGroupName, id.Name)
+	case "microsoft.network/privateendpoints", "microsoft.network/privatednszones":
+		id, err := arm.ParseResourceID(*resource.ID)
+		if err != nil {
+			return nil
+		}
+		return m.openPrivateLink(id.SubscriptionID, id.ResourceGroupName, id.Name, strings.EqualFold(*resource.Type, "microsoft.network/privatednszones"))
+	case "microsoft.network/networkinterfaces":
+		return m.openEffective(*resource.ID)
+	case "microsoft.network/virtualnetworks":
+		return m.openVNet(*resource.ID)
+	case "microsoft.network/loadbalancers":
+		return m.openLoadBalancer(*resource.ID)
+	case "microsoft.network/applicationgateways":
+		return m.openAppGateway(*resource.ID)
+	case "microsoft.network/networksecuritygroups":
+		return m.openNSG(*resource.ID)
+	case "microsoft.keyvault/vaults":
+		return m.openKeyVault(*resource.ID)
+	case "microsoft.storage/storageaccounts":
+		return m.openStorage(*resource.ID)
+	case "microsoft.compute/disks", "microsoft.compute/snapshots":
+		id, err := arm.ParseResourceID(*resource.ID)
+		if err != nil {
+			return nil
+		}
+		return m.openDisks(id.SubscriptionID, id.ResourceGroupName, id.Name)
+	}
+	return nil
+}
+
+// kubeconfigForResource starts writing a kubeconfig for the resource under
+// the cursor when it is an AKS cluster.
+func (m *Model) kubeconfigForResource() bool {
+	resource, ok := m.selectedResource()
+	if !ok || resource.ID == nil || resource.Type == nil || resource.Name == nil {
+		return false
+	}
+	if !strings.EqualFold(*resource.Type, "microsoft.containerservice/managedclusters") {
+		return false
+	}
+	m.askKubeconfig(*resource.ID, *resource.Name)
+	return true
+}
+
+// selectedVM returns the resource under the cursor when it is a VM.
+func (m Model) selectedVM() (armresources.GenericResourceExpanded, bool) {
+	resource, ok := m.selectedResource()
+	if !ok || resource.ID == nil || resource.Type == nil || resource.Name == nil {
+		return resource, false
+	}
+	return resource, strings.EqualFold(*resource.Type, "microsoft.compute/virtualmachines")
+}
+
+// sshToResource connects to the resource under the cursor when it is a VM.
+func (m *Model) sshToResource() (tea.Cmd, bool) {
+	resource, ok := m.selectedVM()
+	if !ok {
+		return nil, false
+	}
+	return m.connectSSH(*resource.ID, *resource.Name), true
+}
@@ -0,0 +1,262 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// diskRow is a disk or a snapshot shown in the disks view.
+type diskRow struct {
+	disk     *armcompute.Disk
+	snapshot *armcompute.Snapshot
+}
+
+// openDisks lists the disks and snapshots of a subscription or resource
+// group. The row named selectName, if any, is selected once loaded.
+func (m *Model) openDisks(subscriptionID, resourceGroup, selectName string) tea.Cmd {
+	if subscriptionID == "" {
+		m.setFlash(":disks needs a subscription: select one first")
+		return nil
+	}
+	m.openDetailView("disks")
+	m.disksSub = subscriptionID
+	m.disksRG = resourceGroup
+	m.disksSelect = selectName
+	return azure.FetchDisks(subscriptionID, resourceGroup)
+}
+
+func (m Model) reloadDisks() tea.Cmd {
+	return azure.FetchDisks(m.disksSub, m.disksRG)
+}
+
+// diskEncryption summarises how a disk is encrypted at rest, and whether
+// Azure Disk Encryption is enabled in the guest.
+func diskEncryption(encryption *armcompute.Encryption, ade *armcompute.EncryptionSettingsCollection) string {
+	kind := "platform key"
+	if encryption != nil && encryption.Type != nil {
+		switch *encryption.Type {
+		case armcompute.EncryptionTypeEncryptionAtRestWithCustomerKey:
+			kind = "customer key"
+		case armcompute.EncryptionTypeEncryptionAtRestWithPlatformAndCustomerKeys:
+			kind = "platform + customer key"
+		}
+	}
+	if ade != nil && ade.Enabled != nil && *ade.Enabled {
+		kind += ", ADE"
+	}
+	return kind
+}
+
+func (m *Model) updateTableWithDisks() {
+	scope := "subscription " + m.subscriptionName(m.disksSub)
+	if m.disksRG != "" {
+		scope = "resource group " + m.disksRG
+	}
+	unattached := 0
+	for _, disk := range m.disks.Disks {
+		if disk.Properties != nil && disk.Properties.DiskState != nil && *disk.Properties.DiskState == armcompute.DiskStateUnattached {
+			unattached++
+		}
+	}
+	filter := "all disks and snapshots"
+	if m.disksUnattached {
+		filter = "unattached disks only"
+	}
+	m.properties = []ui.Property{
+		{Key: "Scope", Value: scope},
+		{Key: "Disks", Value: fmt.Sprintf("%d (%d unattached)", len(m.disks.Disks), unattached)},
+		{Key: "Snapshots", Value: strconv.Itoa(len(m.disks.Snapshots))},
+		{Key: "Showing", Value: filter},
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.25)       // 25% of width
+	kindWidth := int(float64(m.width) * 0.08)       // 8% of width
+	sizeWidth := int(float64(m.width) * 0.08)       // 8% of width
+	skuWidth := int(float64(m.width) * 0.14)        // 14% of width
+	stateWidth := int(float64(m.width) * 0.1)       // 10% of width
+	ownerWidth := int(float64(m.width) * 0.17)      // 17% of width
+	encryptionWidth := int(float64(m.width) * 0.18) // 18% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Kind", Width: kindWidth},
+		{Title: "Size (GB)", Width: sizeWidth},
+		{Title: "SKU", Width: skuWidth},
+		{Title: "State", Width: stateWidth},
+		{Title: "Owner / Source", Width: ownerWidth},
+		{Title: "Encryption", Width: encryptionWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	m.rowDisks = nil
+	for _, disk := range m.disks.Disks {
+		if disk == nil {
+			continue
+		}
+		sku, size, state, encryption := "-", "-", "-", "-"
+		if disk.SKU != nil {
+			sku = orDash(disk.SKU.Name)
+		}
+		if props := disk.Properties; props != nil {
+			size = intOrDash(props.DiskSizeGB)
+			state = orDash(props.DiskState)
+			encryption = diskEncryption(props.Encryption, props.EncryptionSettingsCollection)
+		}
+		if m.disksUnattached && state != string(armcompute.DiskStateUnattached) {
+			continue
+		}
+		owner := "-"
+		if disk.ManagedBy != nil {
+			owner = resourceName(*disk.ManagedBy)
+		}
+		rows = append(rows, table.Row{orDash(disk.Name), "Disk", size, sku, state, owner, encryption})
+		m.rowDisks = append(m.rowDisks, diskRow{disk: disk})
+	}
+	if !m.disksUnattached {
+		for _, snapshot := range m.disks.Snapshots {
+			if snapshot == nil {
+				continue
+			}
+			sku, size, source, encryption := "-", "-", "-", "-"
+			if snapshot.SKU != nil {
+				sku = orDash(snapshot.SKU.Name)
+			}
+			if props := snapshot.Properties; props != nil {
+				size = intOrDash(props.DiskSizeGB)
+				if props.CreationData != nil && props.CreationData.SourceResourceID != nil {
+					source = resourceName(*props.CreationData.SourceResourceID)
+				}
+				encryption = diskEncryption(props.Encryption, props.EncryptionSettingsCollection)
+				if props.Incremental != nil && *props.Incremental {
+					sku += " (incremental)"
+				}
+			}
+			rows = append(rows, table.Row{orDash(snapshot.Name), "Snapshot", size, sku, "-", source, encryption})
+			m.rowDisks = append(m.rowDisks, diskRow{snapshot: snapshot})
+		}
+	}
+
+	if len(rows) == 0 {
+		message := "No disks or snapshots"
+		if m.disksUnattached {
+			message = "No unattached disks"
+		}
+		rows = append(rows, table.Row{message, "-", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+	if m.disksSelect != "" {
+		for i, row := range rows {
+			if strings.EqualFold(row[0], m.disksSelect) {
+				m.table.SetCursor(i)
+			}
+		}
+	}
+}
+
+// selectedDiskRow returns the disk or snapshot under the cursor.
+func (m Model) selectedDiskRow() (diskRow, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rowDisks) {
+		return diskRow{}, false
+	}
+	return m.rowDisks[cursor], true
+}
+
+// handleDisksKey runs the disk and snapshot actions of the disks view. It
+// reports whether the key was an action.
+func (m *Model) handleDisksKey(key string) (tea.Cmd, bool) {
+	if key == "u" {
+		m.disksUnattached = !m.disksUnattached
+		m.disksSelect = ""
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return nil, true
+	}
+
+	row, ok := m.selectedDiskRow()
+	if !ok {
+		return nil, false
+	}
+
+	if disk := row.disk; disk != nil && disk.ID != nil && disk.Name != nil {
+		diskID, diskName := *disk.ID, *disk.Name
+		switch key {
+		case "p":
+			initial := fmt.Sprintf("%s-snap-%s", diskName, time.Now().Format("20060102"))
+			m.askValue(fmt.Sprintf("Snapshot %s as:", diskName), initial, func(m *Model, value string) tea.Cmd {
+				name := strings.TrimSpace(value)
+				if name == "" {
+					return nil
+				}
+				m.disksSelect = name
+				return m.startOperation(fmt.Sprintf("Snapshot disk %s to %s", diskName, name), func(id int) tea.Cmd {
+					return azure.SnapshotDisk(id, diskID, name)
+				})
+			})
+			return nil, true
+
+		case "r":
+			current := int64(0)
+			if disk.Properties != nil && disk.Properties.DiskSizeGB != nil {
+				current = int64(*disk.Properties.DiskSizeGB)
+			}
+			m.askValue(fmt.Sprintf("Resize %s to size in GB:", diskName), strconv.FormatInt(current, 10), func(m *Model, value string) tea.Cmd {
+				size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+				if err != nil || size <= current {
+					m.setFlash(fmt.Sprintf("invalid size %q: disks can only grow beyond %d GB", value, current))
+					return nil
+				}
+				m.askConfirm(fmt.Sprintf("Resize %s from %d to %d GB?", diskName, current, size), func(m *Model) tea.Cmd {
+					return m.startOperation(fmt.Sprintf("Resize disk %s to %d GB", diskName, size), func(id int) tea.Cmd {
+						return azure.ResizeDisk(id, diskID, int32(size))
+					})
+				})
+				return nil
+			})
+			return nil, true
+		}
+	}
+
+	if snapshot := row.snapshot; snapshot != nil && snapshot.ID != nil && snapshot.Name != nil && key == "n" {
+		snapshotID, snapshotName := *snapshot.ID, *snapshot.Name
+		m.askValue(fmt.Sprintf("New disk from %s named:", snapshotName), strings.TrimSuffix(snapshotName, "-snap")+"-restored", func(m *Model, value string) tea.Cmd {
+			name := strings.TrimSpace(value)
+			if name == "" {
+				return nil
+			}
+			m.askValue(fmt.Sprintf("SKU of %s:", name), string(armcompute.DiskStorageAccountTypesStandardSSDLRS), func(m *Model, value string) tea.Cmd {
+				sku := armcompute.DiskStorageAccountTypes(strings.TrimSpace(value))
+				if !slices.Contains(armcompute.PossibleDiskStorageAccountTypesValues(), sku) {
+					m.setFlash(fmt.Sprintf("unknown disk SKU %q", value))
+					return nil
+				}
+				m.disksSelect = name
+				return m.startOperation(fmt.Sprintf("Create disk %s from snapshot %s", name, snapshotName), func(id int) tea.Cmd {
+					return azure.CreateDiskFromSnapshot(id, snapshotID, name, sku)
+				})
+			})
+			return nil
+		})
+		return nil, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,173 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const (
+	testDiskID     = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/disks/data1"
+	testSnapshotID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/snapshots/data1-snap"
+)
+
+func newTestDisks() azure.DisksMsg {
+	return azure.DisksMsg{
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-a",
+		Disks: []*armcompute.Disk{
+			{
+				ID:        to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/disks/os1"),
+				Name:      to.Ptr("os1"),
+				ManagedBy: to.Ptr(testVMID),
+				SKU:       &armcompute.DiskSKU{Name: to.Ptr(armcompute.DiskStorageAccountTypesPremiumLRS)},
+				Properties: &armcompute.DiskProperties{
+					DiskSizeGB: to.Ptr[int32](128),
+					DiskState:  to.Ptr(armcompute.DiskStateAttached),
+					Encryption: &armcompute.Encryption{Type: to.Ptr(armcompute.EncryptionTypeEncryptionAtRestWithCustomerKey)},
+				},
+			},
+			{
+				ID:   to.Ptr(testDiskID),
+				Name: to.Ptr("data1"),
+				SKU:  &armcompute.DiskSKU{Name: to.Ptr(armcompute.DiskStorageAccountTypesStandardSSDLRS)},
+				Properties: &armcompute.DiskProperties{
+					DiskSizeGB: to.Ptr[int32](64),
+					DiskState:  to.Ptr(armcompute.DiskStateUnattached),
+				},
+			},
+		},
+		Snapshots: []*armcompute.Snapshot{
+			{
+				ID:   to.Ptr(testSnapshotID),
+				Name: to.Ptr("data1-snap"),
+				SKU:  &armcompute.SnapshotSKU{Name: to.Ptr(armcompute.SnapshotStorageAccountTypesStandardLRS)},
+				Properties: &armcompute.SnapshotProperties{
+					DiskSizeGB:   to.Ptr[int32](64),
+					Incremental:  to.Ptr(true),
+					CreationData: &armcompute.CreationData{SourceResourceID: to.Ptr(testDiskID)},
+				},
+			},
+		},
+	}
+}
+
+func newDisksModel(t *testing.T) Model {
+	t.Helper()
+
+	model, cmd := typeCommand(t, newResourcesModel(), "disks")
+	if cmd == nil || model.currentView != "disks" || model.disksSub != "sub-1" || model.disksRG != "rg-a" {
+		t.Fatalf(":disks opened %q for %s/%s", model.currentView, model.disksSub, model.disksRG)
+	}
+	updated, _ := model.Update(newTestDisks())
+	return updated.(Model)
+}
+
+func TestDisksView(t *testing.T) {
+	model := newDisksModel(t)
+
+	rows := model.table.Rows()
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want 2 disks and 1 snapshot", len(rows))
+	}
+	if rows[0][5] != "vm1" || rows[0][6] != "customer key" {
+		t.Errorf("os disk row = %v, want owner vm1 and customer key", rows[0])
+	}
+	if rows[2][1] != "Snapshot" || rows[2][5] != "data1" || !strings.Contains(rows[2][3], "incremental") {
+		t.Errorf("snapshot row = %v", rows[2])
+	}
+	if !strings.Contains(model.View(), "2 (1 unattached)") {
+		t.Error("view does not count unattached disks")
+	}
+
+	model, _ = pressKeys(model, runes("u"))
+	rows = model.table.Rows()
+	if len(rows) != 1 || rows[0][0] != "data1" {
+		t.Errorf("unattached filter rows = %v, want data1 only", rows)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resources" {
+		t.Errorf("esc returned to %q, want resources", model.currentView)
+	}
+}
+
+func TestDisksReplyAfterEsc(t *testing.T) {
+	model, _ := typeCommand(t, newResourcesModel(), "disks")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+
+	updated, _ := model.Update(newTestDisks())
+	model = updated.(Model)
+	if model.currentView != "resources" || model.loading {
+		t.Errorf("late disks reply left view %q loading=%v", model.currentView, model.loading)
+	}
+}
+
+func TestOpenDiskFromResources(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testSnapshotID), Name: to.Ptr("data1-snap"), Type: to.Ptr("Microsoft.Compute/snapshots")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "disks" {
+		t.Fatalf("enter on snapshot row opened %q", model.currentView)
+	}
+
+	updated, _ := model.Update(newTestDisks())
+	model = updated.(Model)
+	if got := model.table.SelectedRow()[0]; got != "data1-snap" {
+		t.Errorf("selected row = %q, want data1-snap", got)
+	}
+}
+
+func TestResizeDisk(t *testing.T) {
+	model := newDisksModel(t)
+	model.table.SetCursor(1)
+
+	model, _ = pressKeys(model, runes("r"))
+	if model.prompt == nil || model.prompt.input.Value() != "64" {
+		t.Fatal("r did not prompt with the current size")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, runes("32"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt != nil || !strings.Contains(model.flash, "only grow") {
+		t.Fatalf("shrinking a disk was not refused, flash %q", model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("r"), tea.KeyMsg{Type: tea.KeyBackspace}, tea.KeyMsg{Type: tea.KeyBackspace}, runes("256"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || !strings.Contains(model.prompt.question, "data1 from 64 to 256 GB") {
+		t.Fatalf("expected resize confirmation, got %+v", model.prompt)
+	}
+	model, cmd := pressKeys(model, runes("y"))
+	if cmd == nil || len(model.operations) != 1 {
+		t.Error("confirming the resize did not start an operation")
+	}
+}
+
+func TestDiskFromSnapshot(t *testing.T) {
+	model := newDisksModel(t)
+	model.table.SetCursor(2)
+
+	model, _ = pressKeys(model, runes("n"))
+	if model.prompt == nil || model.prompt.input.Value() != "data1-restored" {
+		t.Fatalf("n did not prompt for the new disk name, got %+v", model.prompt)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || model.prompt.input.Value() != "StandardSSD_LRS" {
+		t.Fatalf("expected SKU prompt, got %+v", model.prompt)
+	}
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || len(model.operations) != 1 || !strings.Contains(model.operations[0].description, "data1-restored") {
+		t.Errorf("creating the disk did not start an operation: %+v", model.operations)
+	}
+
+	model.table.SetCursor(1)
+	model, _ = pressKeys(model, runes("n"))
+	if model.prompt != nil {
+		t.Error("n on a disk row should do nothing")
+	}
+}
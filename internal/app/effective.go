@@ -0,0 +1,318 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// effectiveRule is one security rule in force on a NIC, with the NSG it
+// comes from and where in the evaluation order that NSG sits.
+type effectiveRule struct {
+	rule   *armnetwork.EffectiveNetworkSecurityRule
+	source string
+	order  int
+}
+
+func (m *Model) openEffective(resourceID string) tea.Cmd {
+	m.openDetailView("effective")
+	m.effectiveID = resourceID
+	m.effectiveRoutes = false
+	return azure.FetchEffectiveNetwork(resourceID)
+}
+
+// effectiveForResource opens the effective rules of the VM or NIC under the
+// cursor in the resources view.
+func (m *Model) effectiveForResource() (tea.Cmd, bool) {
+	resource, ok := m.selectedResource()
+	if !ok || resource.ID == nil || resource.Type == nil {
+		return nil, false
+	}
+	switch strings.ToLower(*resource.Type) {
+	case "microsoft.compute/virtualmachines", "microsoft.network/networkinterfaces":
+		return m.openEffective(*resource.ID), true
+	}
+	return nil, false
+}
+
+// effectiveForVM opens the effective rules of the VM in the VM view, asking
+// which interface to use when it has several.
+func (m *Model) effectiveForVM() tea.Cmd {
+	vm := m.vm.VM
+	var nics []string
+	if vm.Properties != nil && vm.Properties.NetworkProfile != nil {
+		for _, ref := range vm.Properties.NetworkProfile.NetworkInterfaces {
+			if ref != nil && ref.ID != nil {
+				nics = append(nics, *ref.ID)
+			}
+		}
+	}
+	if len(nics) < 2 {
+		return m.openEffective(m.vmID)
+	}
+	var choices []choice
+	for i, nicID := range nics {
+		if i == 9 {
+			break
+		}
+		choices = append(choices, choice{key: fmt.Sprint(i + 1), label: resourceName(nicID), run: func(m *Model) tea.Cmd {
+			return m.openEffective(nicID)
+		}})
+	}
+	m.askChoice("Effective rules and routes of interface:", choices)
+	return nil
+}
+
+func (m *Model) applyEffective(msg azure.EffectiveNetworkMsg) {
+	if m.currentView != "effective" || msg.ResourceID != m.effectiveID {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.effective = msg
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+}
+
+// effectiveSource names the NSG a group of effective rules comes from and
+// what it is associated with.
+func effectiveSource(group *armnetwork.EffectiveNetworkSecurityGroup) string {
+	name := "-"
+	if group.NetworkSecurityGroup != nil && group.NetworkSecurityGroup.ID != nil {
+		name = resourceName(*group.NetworkSecurityGroup.ID)
+	}
+	if association := group.Association; association != nil {
+		switch {
+		case association.Subnet != nil && association.Subnet.ID != nil:
+			return name + " (subnet " + resourceName(*association.Subnet.ID) + ")"
+		case association.NetworkInterface != nil:
+			return name + " (NIC)"
+		case association.NetworkManager != nil && association.NetworkManager.ID != nil:
+			return resourceName(*association.NetworkManager.ID) + " (network manager)"
+		}
+	}
+	return name
+}
+
+// effectiveRules merges the rules of every NSG in force in the order Azure
+// evaluates them: inbound traffic meets the subnet's NSG before the NIC's,
+// outbound traffic the NIC's before the subnet's, and admin rules from a
+// network manager come first either way.
+func (m Model) effectiveRules() []effectiveRule {
+	var rules []effectiveRule
+	for _, group := range m.effective.SecurityGroups {
+		if group == nil {
+			continue
+		}
+		source := effectiveSource(group)
+		for _, rule := range group.EffectiveSecurityRules {
+			if rule == nil {
+				continue
+			}
+			order := 0
+			if association := group.Association; association != nil && association.NetworkManager == nil {
+				inbound := orDash(rule.Direction) == string(armnetwork.SecurityRuleDirectionInbound)
+				if (association.Subnet != nil) == inbound {
+					order = 1
+				} else {
+					order = 2
+				}
+			}
+			rules = append(rules, effectiveRule{rule: rule, source: source, order: order})
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if da, db := orDash(a.rule.Direction), orDash(b.rule.Direction); da != db {
+			return da == string(armnetwork.SecurityRuleDirectionInbound)
+		}
+		if a.order != b.order {
+			return a.order < b.order
+		}
+		return int32OrZero(a.rule.Priority) < int32OrZero(b.rule.Priority)
+	})
+	return rules
+}
+
+// effectiveRuleName strips the securityRules/ or defaultSecurityRules/
+// prefix effective rules are named with, marking default rules.
+func effectiveRuleName(name *string) string {
+	value := orDash(name)
+	if rest, ok := strings.CutPrefix(value, "defaultSecurityRules/"); ok {
+		return rest + " (default)"
+	}
+	if _, rest, ok := strings.Cut(value, "/"); ok {
+		return rest
+	}
+	return value
+}
+
+func (m *Model) updateTableWithEffective() {
+	msg := m.effective
+	nic, vm := orDash(msg.NIC.Name), "-"
+	if props := msg.NIC.Properties; props != nil && props.VirtualMachine != nil && props.VirtualMachine.ID != nil {
+		vm = resourceName(*props.VirtualMachine.ID)
+	}
+	subnet, routeTable := "-", "none"
+	if msg.Subnet != "" {
+		subnet = subnetName(msg.Subnet)
+	}
+	if msg.RouteTable != "" {
+		routeTable = resourceName(msg.RouteTable)
+	}
+	var groups []string
+	for _, group := range msg.SecurityGroups {
+		if group != nil {
+			groups = append(groups, effectiveSource(group))
+		}
+	}
+	if len(groups) == 0 {
+		groups = []string{"none"}
+	}
+	showing := "security rules (tab: routes)"
+	if m.effectiveRoutes {
+		showing = "routes (tab: security rules)"
+	}
+	m.properties = []ui.Property{
+		{Key: "Network interface", Value: nic},
+		{Key: "Virtual machine", Value: vm},
+		{Key: "Subnet", Value: subnet},
+		{Key: "Security groups", Value: strings.Join(groups, ", ")},
+		{Key: "Route table", Value: routeTable},
+		{Key: "Showing", Value: showing},
+	}
+
+	if m.effectiveRoutes {
+		if msg.RoutesErr != nil {
+			m.properties = append(m.properties, ui.Property{Key: "Routes", Value: styles.ErrorStyle.Render(msg.RoutesErr.Error())})
+		}
+		m.updateTableWithEffectiveRoutes()
+		return
+	}
+	if msg.RulesErr != nil {
+		m.properties = append(m.properties, ui.Property{Key: "Security rules", Value: styles.ErrorStyle.Render(msg.RulesErr.Error())})
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	directionWidth := int(float64(m.width) * 0.08)   // 8% of width
+	priorityWidth := int(float64(m.width) * 0.07)    // 7% of width
+	nameWidth := int(float64(m.width) * 0.17)        // 17% of width
+	protocolWidth := int(float64(m.width) * 0.06)    // 6% of width
+	sourceWidth := int(float64(m.width) * 0.14)      // 14% of width
+	destinationWidth := int(float64(m.width) * 0.14) // 14% of width
+	portWidth := int(float64(m.width) * 0.08)        // 8% of width
+	accessWidth := int(float64(m.width) * 0.07)      // 7% of width
+	nsgWidth := int(float64(m.width) * 0.19)         // 19% of width
+
+	columns := []table.Column{
+		{Title: "Direction", Width: directionWidth},
+		{Title: "Priority", Width: priorityWidth},
+		{Title: "Name", Width: nameWidth},
+		{Title: "Protocol", Width: protocolWidth},
+		{Title: "Source", Width: sourceWidth},
+		{Title: "Destination", Width: destinationWidth},
+		{Title: "Dst Ports", Width: portWidth},
+		{Title: "Action", Width: accessWidth},
+		{Title: "NSG", Width: nsgWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, entry := range m.effectiveRules() {
+		rule := entry.rule
+		rows = append(rows, table.Row{
+			orDash(rule.Direction),
+			intOrDash(rule.Priority),
+			effectiveRuleName(rule.Name),
+			orDash(rule.Protocol),
+			rulePorts(rule.SourceAddressPrefix, rule.SourceAddressPrefixes),
+			rulePorts(rule.DestinationAddressPrefix, rule.DestinationAddressPrefixes),
+			rulePorts(rule.DestinationPortRange, rule.DestinationPortRanges),
+			orDash(rule.Access),
+			entry.source,
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No security rules", "-", "-", "-", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+func (m *Model) updateTableWithEffectiveRoutes() {
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	sourceWidth := int(float64(m.width) * 0.16)  // 16% of width
+	stateWidth := int(float64(m.width) * 0.08)   // 8% of width
+	prefixWidth := int(float64(m.width) * 0.2)   // 20% of width
+	hopTypeWidth := int(float64(m.width) * 0.18) // 18% of width
+	hopIPWidth := int(float64(m.width) * 0.14)   // 14% of width
+	nameWidth := int(float64(m.width) * 0.24)    // 24% of width
+
+	columns := []table.Column{
+		{Title: "Source", Width: sourceWidth},
+		{Title: "State", Width: stateWidth},
+		{Title: "Address Prefix", Width: prefixWidth},
+		{Title: "Next Hop Type", Width: hopTypeWidth},
+		{Title: "Next Hop IP", Width: hopIPWidth},
+		{Title: "Route", Width: nameWidth},
+	}
+	m.table.SetColumns(columns)
+
+	routeTable := resourceName(m.effective.RouteTable)
+	var rows []table.Row
+	for _, route := range m.effective.Routes {
+		if route == nil {
+			continue
+		}
+		// User routes come from the route table on the NIC's subnet.
+		source, name := orDash(route.Source), orDash(route.Name)
+		if source == string(armnetwork.EffectiveRouteSourceUser) && m.effective.RouteTable != "" {
+			source += " (" + routeTable + ")"
+			name = routeTable + "/" + name
+		}
+		rows = append(rows, table.Row{
+			source,
+			orDash(route.State),
+			joinList(route.AddressPrefix),
+			orDash(route.NextHopType),
+			joinOrDash(route.NextHopIPAddress),
+			name,
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No routes", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+// handleEffectiveKey switches the effective view between security rules
+// and routes.
+func (m *Model) handleEffectiveKey(key string) (tea.Cmd, bool) {
+	if key != "tab" {
+		return nil, false
+	}
+	m.effectiveRoutes = !m.effectiveRoutes
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+	return nil, true
+}
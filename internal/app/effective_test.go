@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testNICID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/networkInterfaces/vm1-nic"
+
+func newEffectiveRule(name string, direction armnetwork.SecurityRuleDirection, priority int32, access armnetwork.SecurityRuleAccess) *armnetwork.EffectiveNetworkSecurityRule {
+	return &armnetwork.EffectiveNetworkSecurityRule{
+		Name:                     to.Ptr(name),
+		Direction:                to.Ptr(direction),
+		Priority:                 to.Ptr(priority),
+		Access:                   to.Ptr(access),
+		Protocol:                 to.Ptr(armnetwork.EffectiveSecurityRuleProtocolTCP),
+		SourceAddressPrefix:      to.Ptr("0.0.0.0/0"),
+		DestinationAddressPrefix: to.Ptr("0.0.0.0/0"),
+		DestinationPortRange:     to.Ptr("22-22"),
+	}
+}
+
+func newTestEffectiveNetwork(resourceID string) azure.EffectiveNetworkMsg {
+	rg := "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/"
+	inbound, outbound := armnetwork.SecurityRuleDirectionInbound, armnetwork.SecurityRuleDirectionOutbound
+	allow, deny := armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleAccessDeny
+	return azure.EffectiveNetworkMsg{
+		ResourceID: resourceID,
+		NIC: armnetwork.Interface{
+			Name:       to.Ptr("vm1-nic"),
+			Properties: &armnetwork.InterfacePropertiesFormat{VirtualMachine: &armnetwork.SubResource{ID: to.Ptr(testVMID)}},
+		},
+		Subnet:     rg + "virtualNetworks/vnet-hub/subnets/web",
+		RouteTable: rg + "routeTables/rt-web",
+		SecurityGroups: []*armnetwork.EffectiveNetworkSecurityGroup{
+			{
+				NetworkSecurityGroup: &armnetwork.SubResource{ID: to.Ptr(rg + "networkSecurityGroups/nsg-vm")},
+				Association:          &armnetwork.EffectiveNetworkSecurityGroupAssociation{NetworkInterface: &armnetwork.SubResource{ID: to.Ptr(testNICID)}},
+				EffectiveSecurityRules: []*armnetwork.EffectiveNetworkSecurityRule{
+					newEffectiveRule("securityRules/deny-ssh", inbound, 100, deny),
+					newEffectiveRule("securityRules/deny-smtp", outbound, 100, deny),
+				},
+			},
+			{
+				NetworkSecurityGroup: &armnetwork.SubResource{ID: to.Ptr(rg + "networkSecurityGroups/nsg-web")},
+				Association:          &armnetwork.EffectiveNetworkSecurityGroupAssociation{Subnet: &armnetwork.SubResource{ID: to.Ptr(rg + "virtualNetworks/vnet-hub/subnets/web")}},
+				EffectiveSecurityRules: []*armnetwork.EffectiveNetworkSecurityRule{
+					newEffectiveRule("defaultSecurityRules/DenyAllInBound", inbound, 65500, deny),
+					newEffectiveRule("securityRules/allow-ssh", inbound, 300, allow),
+					newEffectiveRule("defaultSecurityRules/AllowInternetOutBound", outbound, 65001, allow),
+				},
+			},
+		},
+		Routes: []*armnetwork.EffectiveRoute{
+			{
+				Source:        to.Ptr(armnetwork.EffectiveRouteSourceDefault),
+				State:         to.Ptr(armnetwork.EffectiveRouteStateActive),
+				AddressPrefix: []*string{to.Ptr("10.0.0.0/16")},
+				NextHopType:   to.Ptr(armnetwork.RouteNextHopTypeVnetLocal),
+			},
+			{
+				Name:             to.Ptr("to-firewall"),
+				Source:           to.Ptr(armnetwork.EffectiveRouteSourceUser),
+				State:            to.Ptr(armnetwork.EffectiveRouteStateActive),
+				AddressPrefix:    []*string{to.Ptr("0.0.0.0/0")},
+				NextHopType:      to.Ptr(armnetwork.RouteNextHopTypeVirtualAppliance),
+				NextHopIPAddress: []*string{to.Ptr("10.0.0.4")},
+			},
+		},
+	}
+}
+
+func TestEffectiveRulesFromVMRow(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+	model, cmd := pressKeys(model, runes("e"))
+	if cmd == nil || model.currentView != "effective" || model.effectiveID != testVMID {
+		t.Fatalf("e on VM row opened %q (%s)", model.currentView, model.effectiveID)
+	}
+
+	updated, _ := model.Update(newTestEffectiveNetwork(testVMID))
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Security groups"); got != "nsg-vm (NIC), nsg-web (subnet web)" {
+		t.Errorf("Security groups = %q", got)
+	}
+	if got := propertyValue(t, model, "Virtual machine"); got != "vm1" {
+		t.Errorf("Virtual machine = %q", got)
+	}
+
+	var order []string
+	for _, row := range model.table.Rows() {
+		order = append(order, row[2]+"@"+row[8])
+	}
+	want := "allow-ssh@nsg-web (subnet web) DenyAllInBound (default)@nsg-web (subnet web) deny-ssh@nsg-vm (NIC) " +
+		"deny-smtp@nsg-vm (NIC) AllowInternetOutBound (default)@nsg-web (subnet web)"
+	if got := strings.Join(order, " "); got != want {
+		t.Errorf("rules in evaluation order:\n%s\nwant\n%s", got, want)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyTab})
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[1][0] != "User (rt-web)" || rows[1][4] != "10.0.0.4" || rows[1][5] != "rt-web/to-firewall" {
+		t.Errorf("route rows = %v", rows)
+	}
+	if got := propertyValue(t, model, "Showing"); !strings.HasPrefix(got, "routes") {
+		t.Errorf("Showing = %q after tab", got)
+	}
+}
+
+func TestEffectiveRulesShowOneSideWhenTheOtherFails(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testNICID), Name: to.Ptr("vm1-nic"), Type: to.Ptr("Microsoft.Network/networkInterfaces")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "effective" || model.effectiveID != testNICID {
+		t.Fatalf("enter on NIC row opened %q (%s)", model.currentView, model.effectiveID)
+	}
+
+	msg := newTestEffectiveNetwork(testNICID)
+	msg.SecurityGroups = nil
+	msg.RulesErr = errors.New("NetworkInterfaceNotAttachedToRunningVm")
+	updated, _ := model.Update(msg)
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Security rules"); !strings.Contains(got, "NetworkInterfaceNotAttachedToRunningVm") {
+		t.Errorf("Security rules = %q, want the error", got)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyTab})
+	if rows := model.table.Rows(); len(rows) != 2 {
+		t.Errorf("routes were not shown next to the failed rules: %v", rows)
+	}
+}
+
+func TestEffectiveRulesAskForNICOfVM(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	vm := newTestVM()
+	vm.Properties.NetworkProfile = &armcompute.NetworkProfile{NetworkInterfaces: []*armcompute.NetworkInterfaceReference{
+		{ID: to.Ptr(testNICID)},
+		{ID: to.Ptr(testNICID + "2")},
+	}}
+	updated, _ := model.Update(azure.VMMsg{ID: testVMID, VM: vm})
+	model = updated.(Model)
+
+	model, _ = pressKeys(model, runes("e"))
+	if model.prompt == nil || len(model.prompt.choices) != 2 {
+		t.Fatalf("e on a VM with two NICs prompted %+v", model.prompt)
+	}
+	model, cmd := pressKeys(model, runes("2"))
+	if cmd == nil || model.currentView != "effective" || model.effectiveID != testNICID+"2" {
+		t.Errorf("choosing the second NIC opened %q (%s)", model.currentView, model.effectiveID)
+	}
+}
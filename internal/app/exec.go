@@ -0,0 +1,21 @@
+package app
+
+import (
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// execFinishedMsg reports that a program launched with tea.ExecProcess
+// exited and azr has the terminal back.
+type execFinishedMsg struct {
+	name string
+	err  error
+}
+
+// launch suspends azr while cmd runs in the terminal.
+func launch(name string, cmd *exec.Cmd) tea.Cmd {
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return execFinishedMsg{name: name, err: err}
+	})
+}
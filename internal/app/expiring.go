@@ -0,0 +1,188 @@
+package app
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// defaultExpiringDays is the window :expiring scans without an argument.
+const defaultExpiringDays = azure.ExpiryWarningDays
+
+// openExpiring scans the marked subscriptions, or the selected one, for
+// items expiring within days.
+func (m *Model) openExpiring(days int) tea.Cmd {
+	subscriptionIDs := m.markedSubscriptionIDs()
+	if len(subscriptionIDs) == 0 {
+		if subscriptionID, _ := m.currentSubscriptionAndGroup(); subscriptionID != "" {
+			subscriptionIDs = []string{subscriptionID}
+		}
+	}
+	if len(subscriptionIDs) == 0 {
+		m.setFlash(":expiring needs a subscription: select one or mark several first")
+		return nil
+	}
+	m.openDetailView("expiring")
+	m.expiringSubs = subscriptionIDs
+	m.expiringDays = days
+	m.expiring = azure.ExpiringMsg{}
+	return azure.FetchExpiring(subscriptionIDs, days)
+}
+
+func (m *Model) applyExpiring(msg azure.ExpiringMsg) {
+	if m.currentView != "expiring" || msg.Days != m.expiringDays || !slices.Equal(msg.SubscriptionIDs, m.expiringSubs) {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.expiring = msg
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+}
+
+// handleExpiringKey opens the vault of the selected item on enter and scans
+// again on r.
+func (m *Model) handleExpiringKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "enter":
+		cursor := m.table.Cursor()
+		if cursor < 0 || cursor >= len(m.expiring.Items) {
+			return nil, true
+		}
+		item := m.expiring.Items[cursor]
+		if item.Vault == "" {
+			m.setFlash(item.Name + " is not in a key vault")
+			return nil, true
+		}
+		cmd := m.openKeyVault(item.Resource)
+		m.vaultKind = item.Kind + "s"
+		m.vaultSelect = item.Name
+		return cmd, true
+	case "r":
+		m.loading = true
+		m.err = nil
+		return azure.FetchExpiring(m.expiringSubs, m.expiringDays), true
+	}
+	return nil, false
+}
+
+func (m *Model) updateTableWithExpiring() {
+	names := make([]string, 0, len(m.expiringSubs))
+	for _, subscriptionID := range m.expiringSubs {
+		names = append(names, m.subscriptionName(subscriptionID))
+	}
+	expired := 0
+	for _, item := range m.expiring.Items {
+		if item.DaysLeft < 0 {
+			expired++
+		}
+	}
+	m.properties = []ui.Property{
+		{Key: "Subscriptions", Value: strings.Join(names, ", ")},
+		{Key: "Window", Value: fmt.Sprintf("%d days (:expiring <days> changes it)", m.expiringDays)},
+		{Key: "Expiring", Value: strconv.Itoa(len(m.expiring.Items) - expired)},
+		{Key: "Expired", Value: strconv.Itoa(expired)},
+	}
+	ids := make([]string, 0, len(m.expiring.Errors))
+	for id := range m.expiring.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		name := resourceName(id)
+		if !strings.Contains(id, "/") {
+			name = m.subscriptionName(id)
+		}
+		m.properties = append(m.properties, ui.Property{Key: name, Value: styles.ErrorStyle.Render(m.expiring.Errors[id].Error())})
+	}
+	unlisted := make([]string, 0, len(m.expiring.Unlisted))
+	for id := range m.expiring.Unlisted {
+		unlisted = append(unlisted, id)
+	}
+	sort.Strings(unlisted)
+	for _, id := range unlisted {
+		vault, kind := path.Split(id)
+		m.properties = append(m.properties, ui.Property{
+			Key:   resourceName(strings.TrimSuffix(vault, "/")),
+			Value: styles.WarningStyle.Render(fmt.Sprintf("%s not listed: %v", kind, m.expiring.Unlisted[id])),
+		})
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	daysWidth := int(float64(m.width) * 0.14)         // 14% of width
+	expiresWidth := int(float64(m.width) * 0.12)      // 12% of width
+	kindWidth := int(float64(m.width) * 0.14)         // 14% of width
+	nameWidth := int(float64(m.width) * 0.2)          // 20% of width
+	vaultWidth := int(float64(m.width) * 0.14)        // 14% of width
+	subscriptionWidth := int(float64(m.width) * 0.18) // 18% of width
+	enabledWidth := int(float64(m.width) * 0.08)      // 8% of width
+
+	columns := []table.Column{
+		{Title: "Days Left", Width: daysWidth},
+		{Title: "Expires", Width: expiresWidth},
+		{Title: "Kind", Width: kindWidth},
+		{Title: "Name", Width: nameWidth},
+		{Title: "Vault", Width: vaultWidth},
+		{Title: "Subscription", Width: subscriptionWidth},
+		{Title: "Enabled", Width: enabledWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, item := range m.expiring.Items {
+		vault := item.Vault
+		if vault == "" {
+			vault = "-"
+		}
+		rows = append(rows, table.Row{
+			daysLeftCell(item, daysWidth),
+			item.Expires.Local().Format("2006-01-02"),
+			item.Kind,
+			item.Name,
+			vault,
+			m.subscriptionName(item.SubscriptionID),
+			yesNo(&item.Enabled),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"-", "-", "-", fmt.Sprintf("Nothing expires within %d days", m.expiringDays), "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+// daysLeftCell shows the days left and status of an item, colored by
+// status. The table truncates cells by their byte length, so colors are
+// left out when the escape sequences would not fit.
+func daysLeftCell(item azure.ExpiringItem, width int) string {
+	text := fmt.Sprintf("%d (%s)", item.DaysLeft, item.Status)
+	var style lipgloss.Style
+	switch item.Status {
+	case "expired", "critical":
+		style = styles.ErrorStyle
+	case "warning":
+		style = styles.WarningStyle
+	default:
+		return text
+	}
+	if colored := style.Render(text); len(colored) <= width {
+		return colored
+	}
+	return text
+}
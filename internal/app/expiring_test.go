@@ -0,0 +1,72 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func TestExpiringView(t *testing.T) {
+	model := newMultiSubModel()
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	model, _ = pressKeys(model, space, runes("j"), space)
+
+	model, cmd := typeCommand(t, model, "expiring 14")
+	if cmd == nil || model.currentView != "expiring" || model.expiringDays != 14 || len(model.expiringSubs) != 2 {
+		t.Fatalf(":expiring 14 opened %q for %v over %d days", model.currentView, model.expiringSubs, model.expiringDays)
+	}
+
+	expires := time.Now().AddDate(0, 0, 3)
+	updated, _ := model.Update(azure.ExpiringMsg{
+		SubscriptionIDs: []string{"sub-1", "sub-2"},
+		Days:            14,
+		Items: []azure.ExpiringItem{
+			{SubscriptionID: "sub-2", Resource: "/subscriptions/sub-2/resourceGroups/rg-web/providers/Microsoft.Web/certificates/www", Kind: azure.KindAppServiceCertificate, Name: "www", Enabled: true, Expires: expires.AddDate(0, 0, -5), DaysLeft: -2, Status: "expired"},
+			{SubscriptionID: "sub-1", Resource: testVaultID, Vault: "kv-app", Kind: "certificate", Name: "tls", Enabled: true, Expires: expires, DaysLeft: 3, Status: "critical"},
+		},
+		Errors:   map[string]error{"sub-2": errors.New("AuthorizationFailed")},
+		Unlisted: map[string]error{testVaultID + "/keys": errors.New("Forbidden: no keys list permission")},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Expired"); got != "1" {
+		t.Errorf("Expired = %q", got)
+	}
+	if got := propertyValue(t, model, "dev"); !strings.Contains(got, "AuthorizationFailed") {
+		t.Errorf("dev = %q, want the subscription error", got)
+	}
+	if got := propertyValue(t, model, "kv-app"); !strings.Contains(got, "keys not listed: Forbidden") {
+		t.Errorf("kv-app = %q, want the kind it could not list", got)
+	}
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[0][0] != "-2 (expired)" || rows[0][4] != "-" || rows[0][5] != "dev" || rows[1][0] != "3 (critical)" || rows[1][4] != "kv-app" {
+		t.Fatalf("rows = %v", rows)
+	}
+
+	if flashed, _ := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}); flashed.currentView != "expiring" || flashed.flash == "" {
+		t.Errorf("enter on an App Service certificate opened %q", flashed.currentView)
+	}
+	model, cmd = pressKeys(model, runes("j"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "keyvault" || model.vaultKind != azure.VaultCertificates || model.vaultSelect != "tls" {
+		t.Fatalf("enter on tls opened %q on %s/%s", model.currentView, model.vaultKind, model.vaultSelect)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "expiring" {
+		t.Errorf("esc went back to %q", model.currentView)
+	}
+}
+
+func TestExpiringCommandUsage(t *testing.T) {
+	model, cmd := typeCommand(t, newResourcesModel(), "expiring soon")
+	if cmd != nil || model.currentView != "resources" || model.flash != "usage: :expiring [days]" {
+		t.Errorf(":expiring soon opened %q with flash %q", model.currentView, model.flash)
+	}
+	model, cmd = typeCommand(t, model, "expiring")
+	if cmd == nil || model.expiringDays != defaultExpiringDays || len(model.expiringSubs) != 1 || model.expiringSubs[0] != "sub-1" {
+		t.Errorf(":expiring scanned %v over %d days", model.expiringSubs, model.expiringDays)
+	}
+}
@@ -0,0 +1,63 @@
+package app
+
+import (
+	"strconv"
+	"strings"
+)
+
+// orDash returns the value behind p, or "-" when it is unset or empty. It
+// covers plain strings as well as the string enums of the Azure SDKs.
+func orDash[T ~string](p *T) string {
+	if p == nil || *p == "" {
+		return "-"
+	}
+	return string(*p)
+}
+
+// intOrDash formats the number behind p, or "-" when it is unset.
+func intOrDash[T ~int32 | ~int64](p *T) string {
+	if p == nil {
+		return "-"
+	}
+	return strconv.FormatInt(int64(*p), 10)
+}
+
+// yesNo formats an optional flag.
+func yesNo(p *bool) string {
+	if p != nil && *p {
+		return "yes"
+	}
+	return "no"
+}
+
+// joinOrDash joins the values behind ps, or returns "-" when there are none.
+func joinOrDash[T ~string](ps []*T) string {
+	var parts []string
+	for _, p := range ps {
+		if p != nil && *p != "" {
+			parts = append(parts, string(*p))
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatBytes formats a size in bytes with binary units, or "-" when it is
+// negative, which listings use for unknown.
+func formatBytes(n int64) string {
+	if n < 0 {
+		return "-"
+	}
+	const unit = 1024
+	if n < unit {
+		return strconv.FormatInt(n, 10) + " B"
+	}
+	value, exp := float64(n)/unit, 0
+	for value >= unit && exp < 4 {
+		value /= unit
+		exp++
+	}
+	return strconv.FormatFloat(value, 'f', 1, 64) + " " + string("KMGTP"[exp]) + "iB"
+}
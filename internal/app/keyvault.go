@@ -0,0 +1,323 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// secretRevealTimeout is how long a revealed secret value stays on screen.
+var secretRevealTimeout = 30 * time.Second
+
+// secretHideMsg hides the secret revealed as gen. Secrets revealed since
+// carry a newer gen and stay.
+type secretHideMsg struct {
+	gen int
+}
+
+var vaultKindNames = map[string]string{
+	azure.VaultSecrets:      "Secrets",
+	azure.VaultKeys:         "Keys",
+	azure.VaultCertificates: "Certificates",
+}
+
+func (m *Model) openKeyVault(vaultID string) tea.Cmd {
+	m.openDetailView("keyvault")
+	m.vaultID = vaultID
+	m.vault = azure.KeyVaultMsg{}
+	m.vaultKind = azure.VaultSecrets
+	m.vaultSelect = ""
+	m.secret = nil
+	return azure.FetchKeyVault(vaultID)
+}
+
+func (m *Model) applyKeyVault(msg azure.KeyVaultMsg) {
+	if m.currentView != "keyvault" || msg.ID != m.vaultID {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.vault = msg
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+}
+
+func (m *Model) applyVaultVersions(msg azure.VaultVersionsMsg) {
+	if m.currentView != "vaultversions" || msg.ID != m.vaultID || msg.Kind != m.vaultKind || msg.Name != m.vaultItem.Name {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.vaultVersions = msg.Versions
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+}
+
+// applySecretValue copies a secret value read for c, or shows it until
+// secretRevealTimeout passes.
+func (m *Model) applySecretValue(msg azure.SecretValueMsg) tea.Cmd {
+	if (m.currentView != "keyvault" && m.currentView != "vaultversions") || msg.ID != m.vaultID {
+		return nil
+	}
+	if msg.Copy {
+		m.setFlash(fmt.Sprintf("Copied the value of %s to the clipboard", secretLabel(msg)))
+		return copyToClipboard(msg.Value)
+	}
+	m.secret = &msg
+	m.secretGen++
+	m.updateLayout(m.width, m.height)
+	gen := m.secretGen
+	return tea.Tick(secretRevealTimeout, func(time.Time) tea.Msg {
+		return secretHideMsg{gen: gen}
+	})
+}
+
+func (m *Model) hideSecret(msg secretHideMsg) {
+	if msg.gen != m.secretGen || m.secret == nil {
+		return
+	}
+	m.secret = nil
+	m.updateLayout(m.width, m.height)
+}
+
+// selectedVaultItem returns the item or version under the cursor.
+func (m Model) selectedVaultItem() (azure.VaultItem, bool) {
+	items := m.vault.Items[m.vaultKind]
+	if m.currentView == "vaultversions" {
+		items = m.vaultVersions
+	}
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(items) {
+		return azure.VaultItem{}, false
+	}
+	return items[cursor], true
+}
+
+// handleKeyVaultKey switches between secrets, keys and certificates on tab,
+// opens the versions of an item on enter, reveals a secret value on v and
+// copies it, or the ID of a key or certificate, on c.
+func (m *Model) handleKeyVaultKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "tab":
+		if m.currentView != "keyvault" {
+			return nil, false
+		}
+		for i, kind := range azure.VaultKinds {
+			if kind == m.vaultKind {
+				m.vaultKind = azure.VaultKinds[(i+1)%len(azure.VaultKinds)]
+				break
+			}
+		}
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return nil, true
+	case "enter":
+		if m.currentView != "keyvault" {
+			return nil, false
+		}
+		item, ok := m.selectedVaultItem()
+		if !ok {
+			return nil, true
+		}
+		m.vaultItem = item
+		m.vaultSelect = item.Name // Back on this item after esc
+		m.vaultVersions = nil
+		m.openDetailView("vaultversions")
+		m.table.SetCursor(0)
+		return azure.FetchVaultVersions(m.vaultID, m.vault.URI, m.vaultKind, item.Name), true
+	case "r":
+		if m.currentView != "keyvault" {
+			return nil, false
+		}
+		if item, ok := m.selectedVaultItem(); ok {
+			m.vaultSelect = item.Name
+		}
+		m.loading = true
+		m.err = nil
+		return azure.FetchKeyVault(m.vaultID), true
+	case "v":
+		item, ok := m.selectedVaultItem()
+		if !ok || m.vaultKind != azure.VaultSecrets {
+			return nil, ok
+		}
+		if m.secret != nil && m.secret.Name == item.Name && m.secret.Version == item.Version {
+			m.secret = nil
+			m.updateLayout(m.width, m.height)
+			return nil, true
+		}
+		return azure.FetchSecretValue(m.vaultID, m.vault.URI, item.Name, item.Version, false), true
+	case "c":
+		item, ok := m.selectedVaultItem()
+		if !ok {
+			return nil, false
+		}
+		if m.vaultKind != azure.VaultSecrets {
+			m.setFlash(fmt.Sprintf("Copied the ID of %s to the clipboard", item.Name))
+			return copyToClipboard(item.ID), true
+		}
+		return azure.FetchSecretValue(m.vaultID, m.vault.URI, item.Name, item.Version, true), true
+	}
+	return nil, false
+}
+
+func (m *Model) updateTableWithKeyVault() {
+	m.properties = []ui.Property{
+		{Key: "Key vault", Value: resourceName(m.vaultID)},
+		{Key: "URI", Value: m.vault.URI},
+	}
+	for _, kind := range azure.VaultKinds {
+		value := strconv.Itoa(len(m.vault.Items[kind]))
+		if err := m.vault.Errors[kind]; err != nil {
+			value = styles.ErrorStyle.Render(err.Error())
+		}
+		m.properties = append(m.properties, ui.Property{Key: vaultKindNames[kind], Value: value})
+	}
+	m.properties = append(m.properties, ui.Property{Key: "Showing", Value: strings.ToLower(vaultKindNames[m.vaultKind]) + " (tab: next kind, enter: versions)"})
+	m.appendSecretProperty()
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.3)     // 30% of width
+	enabledWidth := int(float64(m.width) * 0.08) // 8% of width
+	expiresWidth := int(float64(m.width) * 0.22) // 22% of width
+	updatedWidth := int(float64(m.width) * 0.15) // 15% of width
+	detailWidth := int(float64(m.width) * 0.25)  // 25% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Enabled", Width: enabledWidth},
+		{Title: "Expires", Width: expiresWidth},
+		{Title: "Updated", Width: updatedWidth},
+		{Title: "Detail", Width: detailWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, item := range m.vault.Items[m.vaultKind] {
+		rows = append(rows, table.Row{item.Name, yesNo(&item.Enabled), expiryLabel(item.Expires), formatVaultTime(item.Updated), vaultItemDetail(item)})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No " + strings.ToLower(vaultKindNames[m.vaultKind]), "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+	if m.vaultSelect != "" {
+		for i, row := range rows {
+			if row[0] == m.vaultSelect {
+				m.table.SetCursor(i)
+			}
+		}
+		m.vaultSelect = ""
+	}
+}
+
+func (m *Model) updateTableWithVaultVersions() {
+	kind := strings.TrimSuffix(vaultKindNames[m.vaultKind], "s")
+	m.properties = []ui.Property{
+		{Key: "Key vault", Value: resourceName(m.vaultID)},
+		{Key: kind, Value: m.vaultItem.Name},
+		{Key: "Versions", Value: strconv.Itoa(len(m.vaultVersions))},
+	}
+	m.appendSecretProperty()
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	versionWidth := int(float64(m.width) * 0.32) // 32% of width
+	enabledWidth := int(float64(m.width) * 0.08) // 8% of width
+	expiresWidth := int(float64(m.width) * 0.22) // 22% of width
+	createdWidth := int(float64(m.width) * 0.15) // 15% of width
+	updatedWidth := int(float64(m.width) * 0.15) // 15% of width
+
+	columns := []table.Column{
+		{Title: "Version", Width: versionWidth},
+		{Title: "Enabled", Width: enabledWidth},
+		{Title: "Expires", Width: expiresWidth},
+		{Title: "Created", Width: createdWidth},
+		{Title: "Updated", Width: updatedWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, version := range m.vaultVersions {
+		rows = append(rows, table.Row{version.Version, yesNo(&version.Enabled), expiryLabel(version.Expires), formatVaultTime(version.Created), formatVaultTime(version.Updated)})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No versions", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+// appendSecretProperty shows the revealed secret value, if any.
+func (m *Model) appendSecretProperty() {
+	if m.secret == nil {
+		return
+	}
+	m.properties = append(m.properties, ui.Property{
+		Key:   "Value of " + secretLabel(*m.secret),
+		Value: styles.WarningStyle.Render(m.secret.Value) + fmt.Sprintf(" (hidden after %s, v hides it now)", secretRevealTimeout),
+	})
+}
+
+func secretLabel(msg azure.SecretValueMsg) string {
+	if msg.Version == "" {
+		return msg.Name
+	}
+	return msg.Name + "/" + msg.Version
+}
+
+// expiryLabel shows an expiry date with the days left, or "-" for items
+// that do not expire.
+func expiryLabel(expires time.Time) string {
+	if expires.IsZero() {
+		return "-"
+	}
+	date := expires.Local().Format("2006-01-02")
+	days := int(time.Until(expires).Hours() / 24)
+	switch {
+	case time.Until(expires) <= 0:
+		return date + " (expired)"
+	case days == 0:
+		return date + " (today)"
+	default:
+		return fmt.Sprintf("%s (in %dd)", date, days)
+	}
+}
+
+func formatVaultTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func vaultItemDetail(item azure.VaultItem) string {
+	var details []string
+	if item.ContentType != "" {
+		details = append(details, item.ContentType)
+	}
+	if item.Managed {
+		details = append(details, "managed by a certificate")
+	}
+	if len(details) == 0 {
+		return "-"
+	}
+	return strings.Join(details, ", ")
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testVaultID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.KeyVault/vaults/kv-app"
+
+func TestKeyVaultBrowser(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVaultID), Name: to.Ptr("kv-app"), Type: to.Ptr("Microsoft.KeyVault/vaults")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "keyvault" || model.vaultID != testVaultID {
+		t.Fatalf("enter on key vault row opened %q", model.currentView)
+	}
+
+	expires := time.Now().Add(10*24*time.Hour + time.Hour)
+	updated, _ := model.Update(azure.KeyVaultMsg{
+		ID:  testVaultID,
+		URI: "https://kv-app.vault.azure.net/",
+		Items: map[string][]azure.VaultItem{
+			azure.VaultSecrets: {
+				{ID: "https://kv-app.vault.azure.net/secrets/api-key", Name: "api-key", Enabled: false},
+				{ID: "https://kv-app.vault.azure.net/secrets/db-password", Name: "db-password", Enabled: true, Expires: expires},
+			},
+			azure.VaultKeys: {{ID: "https://kv-app.vault.azure.net/keys/signing", Name: "signing", Enabled: true, Managed: true}},
+		},
+		Errors: map[string]error{azure.VaultCertificates: errors.New("Forbidden: no list permission")},
+	})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[0][1] != "no" || rows[1][0] != "db-password" || !strings.HasSuffix(rows[1][2], "(in 10d)") {
+		t.Fatalf("secret rows = %v", rows)
+	}
+	if got := propertyValue(t, model, "Certificates"); !strings.Contains(got, "Forbidden") {
+		t.Errorf("Certificates = %q, want the listing error", got)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyTab})
+	if rows = model.table.Rows(); len(rows) != 1 || rows[0][0] != "signing" || rows[0][4] != "managed by a certificate" {
+		t.Fatalf("key rows = %v", rows)
+	}
+	if copied, cmd := pressKeys(model, runes("c")); cmd == nil || copied.flash != "Copied the ID of signing to the clipboard" {
+		t.Errorf("c on a key flashed %q", copied.flash)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyTab}, runes("j"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.currentView != "vaultversions" || model.vaultItem.Name != "db-password" {
+		t.Fatalf("enter on db-password opened %q for %q", model.currentView, model.vaultItem.Name)
+	}
+
+	updated, _ = model.Update(azure.VaultVersionsMsg{ID: testVaultID, Kind: azure.VaultSecrets, Name: "db-password", Versions: []azure.VaultItem{
+		{Name: "db-password", Version: "v2", Enabled: true},
+		{Name: "db-password", Version: "v1", Enabled: false},
+	}})
+	model = updated.(Model)
+	if rows = model.table.Rows(); len(rows) != 2 || rows[1][0] != "v1" || rows[1][1] != "no" {
+		t.Fatalf("version rows = %v", rows)
+	}
+
+	model, cmd = pressKeys(model, runes("j"), runes("v"))
+	if cmd == nil {
+		t.Fatal("v did not read the secret version")
+	}
+	updated, cmd = model.Update(azure.SecretValueMsg{ID: testVaultID, Name: "db-password", Version: "v1", Value: "hunter2"})
+	model = updated.(Model)
+	if cmd == nil || !strings.Contains(propertyValue(t, model, "Value of db-password/v1"), "hunter2") {
+		t.Fatalf("secret not revealed with a timer to hide it: %v", model.properties)
+	}
+	updated, _ = model.Update(secretHideMsg{gen: model.secretGen})
+	model = updated.(Model)
+	for _, property := range model.properties {
+		if strings.Contains(property.Value, "hunter2") {
+			t.Fatalf("secret still shown after the timeout: %v", model.properties)
+		}
+	}
+
+	model, cmd = pressKeys(model, runes("c"))
+	if cmd == nil {
+		t.Fatal("c did not read the secret version")
+	}
+	updated, cmd = model.Update(azure.SecretValueMsg{ID: testVaultID, Name: "db-password", Version: "v1", Value: "hunter2", Copy: true})
+	model = updated.(Model)
+	if cmd == nil || model.secret != nil || !strings.Contains(model.flash, "Copied the value of db-password/v1") {
+		t.Errorf("c revealed the secret or did not copy it: flash %q", model.flash)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "keyvault" || model.table.Cursor() != 1 {
+		t.Errorf("esc went to %q with cursor %d, want db-password in the vault", model.currentView, model.table.Cursor())
+	}
+}
+
+func TestKeyVaultRevealAndCopyInterleaved(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVaultID), Name: to.Ptr("kv-app"), Type: to.Ptr("Microsoft.KeyVault/vaults")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	updated, _ := model.Update(azure.KeyVaultMsg{
+		ID:    testVaultID,
+		URI:   "https://kv-app.vault.azure.net/",
+		Items: map[string][]azure.VaultItem{azure.VaultSecrets: {{Name: "api-key", Enabled: true}, {Name: "db-password", Enabled: true}}},
+	})
+	model = updated.(Model)
+
+	// v on api-key, then c on db-password before either value comes back.
+	model, reveal := pressKeys(model, runes("v"))
+	model, copied := pressKeys(model, runes("j"), runes("c"))
+	if reveal == nil || copied == nil {
+		t.Fatal("v and c did not both read a secret")
+	}
+
+	// The replies carry their own intent, whatever order they arrive in.
+	updated, cmd := model.Update(azure.SecretValueMsg{ID: testVaultID, Name: "db-password", Value: "copy-me", Copy: true})
+	model = updated.(Model)
+	if cmd == nil || model.secret != nil || model.flash != "Copied the value of db-password to the clipboard" {
+		t.Fatalf("the c reply was shown or not copied: secret %+v, flash %q", model.secret, model.flash)
+	}
+	updated, cmd = model.Update(azure.SecretValueMsg{ID: testVaultID, Name: "api-key", Value: "show-me"})
+	model = updated.(Model)
+	if cmd == nil || model.secret == nil || model.secret.Value != "show-me" {
+		t.Fatalf("the v reply was not shown: secret %+v", model.secret)
+	}
+	for _, property := range model.properties {
+		if strings.Contains(property.Value, "copy-me") {
+			t.Errorf("the copied value is on screen: %v", model.properties)
+		}
+	}
+}
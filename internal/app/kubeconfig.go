@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/kubeconfig"
+)
+
+// askKubeconfig asks for user or admin credentials of a cluster and the
+// kubeconfig file to merge them into.
+func (m *Model) askKubeconfig(resourceID, clusterName string) {
+	write := func(admin bool) func(m *Model) tea.Cmd {
+		return func(m *Model) tea.Cmd {
+			contextName := clusterName
+			if admin {
+				contextName += "-admin"
+			}
+			path, err := kubeconfig.DefaultPath()
+			if err != nil {
+				path = ""
+			}
+			m.askValue(fmt.Sprintf("Merge context %s into kubeconfig:", contextName), path, func(m *Model, value string) tea.Cmd {
+				value = strings.TrimSpace(value)
+				if value == "" {
+					return nil
+				}
+				m.setFlash(fmt.Sprintf("Fetching credentials for %s...", clusterName))
+				return azure.WriteAKSKubeconfig(resourceID, admin, value, contextName)
+			})
+			return nil
+		}
+	}
+
+	m.askChoice(fmt.Sprintf("Credentials for %s:", clusterName), []choice{
+		{key: "u", label: "user", run: write(false)},
+		{key: "a", label: "admin", run: write(true)},
+	})
+}
+
+// offerLaunch asks whether to open k9s or a shell against a freshly written
+// kubeconfig context.
+func (m *Model) offerLaunch(msg azure.KubeconfigMsg) {
+	m.askChoice(fmt.Sprintf("Wrote context %s to %s. Open", msg.Context, msg.Path), []choice{
+		{key: "k", label: "k9s", run: func(*Model) tea.Cmd {
+			return launch("k9s", k9sCommand(msg.Path, msg.Context))
+		}},
+		{key: "s", label: "shell", run: func(*Model) tea.Cmd {
+			return launch("shell", shellCommand(msg.Path))
+		}},
+	})
+}
+
+// k9sCommand runs k9s against a kubeconfig context.
+func k9sCommand(path, contextName string) *exec.Cmd {
+	return exec.Command("k9s", "--kubeconfig", path, "--context", contextName)
+}
+
+// shellCommand runs the user's shell with KUBECONFIG pointing at path, so
+// kubectl picks up the context that was just made current.
+func shellCommand(path string) *exec.Cmd {
+	shell := os.Getenv("SHELL")
+	if shell == "" {
+		shell = "/bin/sh"
+	}
+	cmd := exec.Command(shell)
+	cmd.Env = append(os.Environ(), "KUBECONFIG="+path)
+	return cmd
+}
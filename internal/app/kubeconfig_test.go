@@ -0,0 +1,86 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func TestKubeconfigFromResourcesView(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/tmp/azr-test/config:/tmp/other")
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testAKSID), Name: to.Ptr("aks1"), Type: to.Ptr("Microsoft.ContainerService/managedClusters")},
+	)
+
+	model, _ = pressKeys(model, runes("K"))
+	if model.prompt == nil || !strings.Contains(model.prompt.View(), "a: admin") {
+		t.Fatalf("K on an AKS row did not ask for the credential kind")
+	}
+
+	model, _ = pressKeys(model, runes("a"))
+	if model.prompt == nil || model.prompt.input.Value() != "/tmp/azr-test/config" {
+		t.Fatalf("admin credentials did not ask for the path, prompt = %+v", model.prompt)
+	}
+	if !strings.Contains(model.prompt.question, "aks1-admin") {
+		t.Errorf("path prompt %q does not name the admin context", model.prompt.question)
+	}
+
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.prompt != nil {
+		t.Fatal("confirming the path did not start writing the kubeconfig")
+	}
+}
+
+func TestKubeconfigIgnoresOtherResources(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Web/sites/app1"), Name: to.Ptr("app1"), Type: to.Ptr("Microsoft.Web/sites")},
+	)
+
+	model, _ = pressKeys(model, runes("K"))
+	if model.prompt != nil {
+		t.Errorf("K on a web app opened prompt %q", model.prompt.View())
+	}
+}
+
+func TestKubeconfigWrittenOffersLaunch(t *testing.T) {
+	model := newAKSModel(t)
+
+	updated, _ := model.Update(azure.KubeconfigMsg{Path: "/tmp/kube", Context: "aks1"})
+	model = updated.(Model)
+	if model.prompt == nil || !strings.Contains(model.prompt.View(), "k: k9s") {
+		t.Fatal("writing the kubeconfig did not offer to launch k9s")
+	}
+
+	model, cmd := pressKeys(model, runes("k"))
+	if cmd == nil || model.prompt != nil {
+		t.Error("choosing k9s did not launch it")
+	}
+
+	updated, _ = model.Update(execFinishedMsg{name: "k9s", err: errors.New("executable file not found")})
+	model = updated.(Model)
+	if !strings.Contains(model.flash, "executable file not found") {
+		t.Errorf("flash = %q, want the launch error", model.flash)
+	}
+	if model.currentView != "aks" {
+		t.Errorf("returning from k9s left the AKS view for %q", model.currentView)
+	}
+}
+
+func TestLaunchCommands(t *testing.T) {
+	k9s := k9sCommand("/tmp/kube", "aks1")
+	if want := []string{"k9s", "--kubeconfig", "/tmp/kube", "--context", "aks1"}; !slices.Equal(k9s.Args, want) {
+		t.Errorf("k9s args = %v, want %v", k9s.Args, want)
+	}
+
+	t.Setenv("SHELL", "/bin/bash")
+	shell := shellCommand("/tmp/kube")
+	if shell.Args[0] != "/bin/bash" || !slices.Contains(shell.Env, "KUBECONFIG=/tmp/kube") {
+		t.Errorf("shell = %v with env KUBECONFIG missing", shell.Args)
+	}
+}
@@ -0,0 +1,594 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+func (m *Model) openLoadBalancer(resourceID string) tea.Cmd {
+	m.openDetailView("lb")
+	m.lbID = resourceID
+	m.topology = newTextPane()
+	m.updateLayout(m.width, m.height)
+	return azure.FetchLoadBalancer(resourceID)
+}
+
+func (m *Model) openAppGateway(resourceID string) tea.Cmd {
+	m.openDetailView("appgw")
+	m.appgwID = resourceID
+	m.appgwHealth = nil
+	m.topology = newTextPane()
+	m.updateLayout(m.width, m.height)
+	return azure.FetchAppGateway(resourceID)
+}
+
+// handleBalancerKey reloads a load balancer, or probes an application
+// gateway's backends again, on r. Other keys go to the tree.
+func (m *Model) handleBalancerKey(key string) (tea.Cmd, bool) {
+	if key != "r" {
+		return nil, false
+	}
+	if m.currentView == "lb" {
+		m.loading = true
+		return azure.FetchLoadBalancer(m.lbID), true
+	}
+	if m.appgwHealth == nil {
+		return nil, true // Already probing
+	}
+	m.appgwHealth = nil
+	m.applyAppGateway()
+	return azure.FetchAppGatewayHealth(m.appgwID), true
+}
+
+func (m *Model) applyLoadBalancer(msg azure.LoadBalancerMsg) {
+	if m.currentView != "lb" || msg.ID != m.lbID {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.lb = msg
+
+	lb := msg.LoadBalancer
+	sku := "-"
+	if lb.SKU != nil {
+		sku = orDash(lb.SKU.Name)
+		if lb.SKU.Tier != nil {
+			sku += " (" + string(*lb.SKU.Tier) + ")"
+		}
+	}
+	up := 0
+	for _, member := range msg.Members {
+		if member.Availability >= 100 {
+			up++
+		}
+	}
+	health := fmt.Sprintf("%d of %d members up", up, len(msg.Members))
+	if msg.HealthErr != nil {
+		health = styles.ErrorStyle.Render(msg.HealthErr.Error())
+	}
+	m.properties = []ui.Property{
+		{Key: "Load balancer", Value: orDash(lb.Name)},
+		{Key: "Location", Value: orDash(lb.Location)},
+		{Key: "SKU", Value: sku},
+		{Key: "Backend health", Value: health},
+	}
+
+	m.topology.setText(ui.RenderTree(loadBalancerTree(msg)))
+	m.updateLayout(m.width, m.height)
+}
+
+// loadBalancerTree lays out a load balancer from frontends through rules to
+// the backend pools they send traffic to, with the health of each member.
+func loadBalancerTree(msg azure.LoadBalancerMsg) ui.TreeNode {
+	lb := msg.LoadBalancer
+	root := ui.TreeNode{Label: fmt.Sprintf("%s (%s)", orDash(lb.Name), orDash(lb.Location))}
+	props := lb.Properties
+	if props == nil {
+		return root
+	}
+
+	frontends := ui.TreeNode{Label: fmt.Sprintf("Frontends (%d)", len(props.FrontendIPConfigurations))}
+	for _, frontend := range props.FrontendIPConfigurations {
+		if frontend == nil {
+			continue
+		}
+		label := orDash(frontend.Name)
+		if fp := frontend.Properties; fp != nil {
+			switch {
+			case fp.PublicIPAddress != nil && fp.PublicIPAddress.ID != nil:
+				label += "  " + frontendAddress(msg.FrontendIPs, msg.FrontendErrs, frontend.ID) + " (" + resourceName(*fp.PublicIPAddress.ID) + ")"
+			case fp.Subnet != nil && fp.Subnet.ID != nil:
+				label += "  " + orDash(fp.PrivateIPAddress) + " in " + subnetName(*fp.Subnet.ID)
+			}
+		}
+		frontends.Children = append(frontends.Children, ui.TreeNode{Label: label})
+	}
+	root.Children = append(root.Children, frontends)
+
+	rules := ui.TreeNode{Label: fmt.Sprintf("Load balancing rules (%d)", len(props.LoadBalancingRules))}
+	for _, rule := range props.LoadBalancingRules {
+		if rule != nil {
+			rules.Children = append(rules.Children, ui.TreeNode{Label: loadBalancingRuleLabel(rule)})
+		}
+	}
+	root.Children = append(root.Children, rules)
+
+	if len(props.InboundNatRules) > 0 {
+		nat := ui.TreeNode{Label: fmt.Sprintf("Inbound NAT rules (%d)", len(props.InboundNatRules))}
+		for _, rule := range props.InboundNatRules {
+			if rule != nil {
+				nat.Children = append(nat.Children, ui.TreeNode{Label: natRuleLabel(rule)})
+			}
+		}
+		root.Children = append(root.Children, nat)
+	}
+
+	pools := ui.TreeNode{Label: fmt.Sprintf("Backend pools (%d)", len(props.BackendAddressPools))}
+	for _, pool := range props.BackendAddressPools {
+		if pool == nil {
+			continue
+		}
+		node := ui.TreeNode{Label: orDash(pool.Name)}
+		for _, member := range msg.Members {
+			if pool.ID != nil && strings.EqualFold(member.Pool, *pool.ID) {
+				node.Children = append(node.Children, ui.TreeNode{Label: memberLabel(member)})
+			}
+		}
+		if len(node.Children) == 0 {
+			node.Label += "  (empty)"
+		}
+		pools.Children = append(pools.Children, node)
+	}
+	root.Children = append(root.Children, pools)
+
+	probes := ui.TreeNode{Label: fmt.Sprintf("Health probes (%d)", len(props.Probes))}
+	for _, probe := range props.Probes {
+		if probe != nil {
+			probes.Children = append(probes.Children, ui.TreeNode{Label: probeLabel(probe)})
+		}
+	}
+	root.Children = append(root.Children, probes)
+	return root
+}
+
+// frontendAddress returns the public address fetched for a frontend, or
+// why it could not be read.
+func frontendAddress(addresses map[string]string, errs map[string]error, frontendID *string) string {
+	if frontendID == nil {
+		return "-"
+	}
+	if address := addresses[strings.ToLower(*frontendID)]; address != "" {
+		return address
+	}
+	if err := errs[strings.ToLower(*frontendID)]; err != nil {
+		return "- (" + err.Error() + ")"
+	}
+	return "-"
+}
+
+// subResourceName names the child resource a reference points at, or
+// returns fallback when there is none.
+func subResourceName(ref *armnetwork.SubResource, fallback string) string {
+	if ref == nil || ref.ID == nil {
+		return fallback
+	}
+	return resourceName(*ref.ID)
+}
+
+func loadBalancingRuleLabel(rule *armnetwork.LoadBalancingRule) string {
+	label := orDash(rule.Name)
+	props := rule.Properties
+	if props == nil {
+		return label
+	}
+	pools := []string{}
+	if props.BackendAddressPool != nil {
+		pools = append(pools, subResourceName(props.BackendAddressPool, "-"))
+	}
+	for _, pool := range props.BackendAddressPools {
+		if name := subResourceName(pool, ""); name != "" && !strings.EqualFold(name, subResourceName(props.BackendAddressPool, "")) {
+			pools = append(pools, name)
+		}
+	}
+	if len(pools) == 0 {
+		pools = append(pools, "no backend pool")
+	}
+	label += fmt.Sprintf("  %s %s:%s → %s:%s",
+		strings.ToUpper(orDash(props.Protocol)),
+		subResourceName(props.FrontendIPConfiguration, "-"), intOrDash(props.FrontendPort),
+		strings.Join(pools, ", "), intOrDash(props.BackendPort))
+	label += ", probe " + subResourceName(props.Probe, "none")
+	if props.EnableFloatingIP != nil && *props.EnableFloatingIP {
+		label += ", floating IP"
+	}
+	return label
+}
+
+func natRuleLabel(rule *armnetwork.InboundNatRule) string {
+	label := orDash(rule.Name)
+	props := rule.Properties
+	if props == nil {
+		return label
+	}
+	frontendPort := intOrDash(props.FrontendPort)
+	if props.FrontendPortRangeStart != nil && props.FrontendPortRangeEnd != nil {
+		frontendPort = fmt.Sprintf("%d-%d", *props.FrontendPortRangeStart, *props.FrontendPortRangeEnd)
+	}
+	target := subResourceName(props.BackendAddressPool, "not mapped")
+	if props.BackendIPConfiguration != nil && props.BackendIPConfiguration.ID != nil {
+		target = memberName(*props.BackendIPConfiguration.ID)
+	}
+	return label + fmt.Sprintf("  %s %s:%s → %s:%s",
+		strings.ToUpper(orDash(props.Protocol)), subResourceName(props.FrontendIPConfiguration, "-"), frontendPort,
+		target, intOrDash(props.BackendPort))
+}
+
+func probeLabel(probe *armnetwork.Probe) string {
+	label := orDash(probe.Name)
+	props := probe.Properties
+	if props == nil {
+		return label
+	}
+	label += fmt.Sprintf("  %s :%s", strings.ToUpper(orDash(props.Protocol)), intOrDash(props.Port))
+	if props.RequestPath != nil {
+		label += *props.RequestPath
+	}
+	if props.IntervalInSeconds != nil {
+		label += fmt.Sprintf(", every %ds", *props.IntervalInSeconds)
+	}
+	threshold := props.ProbeThreshold
+	if threshold == nil {
+		threshold = props.NumberOfProbes
+	}
+	if threshold != nil {
+		label += fmt.Sprintf(", down after %d failures", *threshold)
+	}
+	if len(props.LoadBalancingRules) == 0 {
+		label += " (not used by any rule)"
+	}
+	return label
+}
+
+// memberName names a backend by its network interface, or by its scale set
+// and instance.
+func memberName(ipConfigurationID string) string {
+	id, err := arm.ParseResourceID(ipConfigurationID)
+	if err != nil || id.Parent == nil {
+		return resourceName(ipConfigurationID)
+	}
+	nic := id.Parent
+	if instance := nic.Parent; instance != nil && instance.Parent != nil && strings.EqualFold(instance.Parent.ResourceType.String(), "Microsoft.Compute/virtualMachineScaleSets") {
+		return instance.Parent.Name + " instance " + instance.Name
+	}
+	return nic.Name
+}
+
+func memberLabel(member azure.BackendMember) string {
+	label := orDash(&member.IP)
+	if member.Target != "" {
+		label = memberName(member.Target) + "  " + label
+	}
+	switch {
+	case member.Availability < 0:
+		return label + "  health unknown"
+	case member.Availability >= 100:
+		return label + "  up"
+	case member.Availability <= 0:
+		return label + "  DOWN"
+	}
+	return label + fmt.Sprintf("  degraded (%.0f%% of probes answered)", member.Availability)
+}
+
+// applyAppGateway shows the application gateway and, once probed, the
+// health of its backends.
+func (m *Model) applyAppGateway() {
+	gateway := m.appgw.Gateway
+	sku, state := "-", "-"
+	if props := gateway.Properties; props != nil {
+		if props.SKU != nil {
+			sku = orDash(props.SKU.Name)
+			if props.SKU.Capacity != nil {
+				sku += fmt.Sprintf(", %d instances", *props.SKU.Capacity)
+			}
+		}
+		state = orDash(props.OperationalState)
+	}
+	health := "probing backends..."
+	if m.appgwHealth != nil {
+		if m.appgwHealth.Err != nil {
+			health = styles.ErrorStyle.Render(m.appgwHealth.Err.Error())
+		} else {
+			health = appGatewayHealthSummary(m.appgwHealth.Health)
+		}
+	}
+	m.properties = []ui.Property{
+		{Key: "Application gateway", Value: orDash(gateway.Name)},
+		{Key: "Location", Value: orDash(gateway.Location)},
+		{Key: "SKU", Value: sku},
+		{Key: "State", Value: state},
+		{Key: "Backend health", Value: health},
+	}
+
+	m.topology.setText(ui.RenderTree(appGatewayTree(m.appgw, m.appgwHealth)))
+	m.updateLayout(m.width, m.height)
+}
+
+// appGatewayHealthSummary counts backend servers by health, over every pool
+// and backend setting they are probed with.
+func appGatewayHealthSummary(health armnetwork.ApplicationGatewayBackendHealth) string {
+	counts := map[string]int{}
+	var order []string
+	for _, pool := range health.BackendAddressPools {
+		if pool == nil {
+			continue
+		}
+		for _, settings := range pool.BackendHTTPSettingsCollection {
+			if settings == nil {
+				continue
+			}
+			for _, server := range settings.Servers {
+				if server == nil {
+					continue
+				}
+				state := strings.ToLower(orDash(server.Health))
+				if _, ok := counts[state]; !ok {
+					order = append(order, state)
+				}
+				counts[state]++
+			}
+		}
+	}
+	if len(order) == 0 {
+		return "no backend servers"
+	}
+	parts := make([]string, 0, len(order))
+	for _, state := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[state], state))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// appGatewayTree lays out an application gateway from frontends through
+// listeners and routing rules to its backend pools, with the health of each
+// backend server per backend setting when it has been probed.
+func appGatewayTree(msg azure.AppGatewayMsg, health *azure.AppGatewayHealthMsg) ui.TreeNode {
+	gateway := msg.Gateway
+	root := ui.TreeNode{Label: fmt.Sprintf("%s (%s)", orDash(gateway.Name), orDash(gateway.Location))}
+	props := gateway.Properties
+	if props == nil {
+		return root
+	}
+
+	ports := map[string]string{}
+	for _, port := range props.FrontendPorts {
+		if port != nil && port.ID != nil && port.Properties != nil {
+			ports[strings.ToLower(*port.ID)] = intOrDash(port.Properties.Port)
+		}
+	}
+
+	frontends := ui.TreeNode{Label: fmt.Sprintf("Frontends (%d)", len(props.FrontendIPConfigurations))}
+	for _, frontend := range props.FrontendIPConfigurations {
+		if frontend == nil {
+			continue
+		}
+		label := orDash(frontend.Name)
+		if fp := frontend.Properties; fp != nil {
+			switch {
+			case fp.PublicIPAddress != nil && fp.PublicIPAddress.ID != nil:
+				label += "  " + frontendAddress(msg.FrontendIPs, msg.FrontendErrs, frontend.ID) + " (" + resourceName(*fp.PublicIPAddress.ID) + ")"
+			case fp.PrivateIPAddress != nil:
+				label += "  " + *fp.PrivateIPAddress
+			}
+		}
+		frontends.Children = append(frontends.Children, ui.TreeNode{Label: label})
+	}
+	root.Children = append(root.Children, frontends)
+
+	listeners := ui.TreeNode{Label: fmt.Sprintf("Listeners (%d)", len(props.HTTPListeners))}
+	for _, listener := range props.HTTPListeners {
+		if listener == nil {
+			continue
+		}
+		label := orDash(listener.Name)
+		if lp := listener.Properties; lp != nil {
+			port := "-"
+			if lp.FrontendPort != nil && lp.FrontendPort.ID != nil {
+				if p, ok := ports[strings.ToLower(*lp.FrontendPort.ID)]; ok {
+					port = p
+				}
+			}
+			label += fmt.Sprintf("  %s %s:%s", strings.ToUpper(orDash(lp.Protocol)), subResourceName(lp.FrontendIPConfiguration, "-"), port)
+			hosts := joinOrDash(lp.HostNames)
+			if hosts == "-" {
+				hosts = orDash(lp.HostName)
+			}
+			if hosts != "-" {
+				label += ", hosts " + hosts
+			}
+			if lp.SSLCertificate != nil {
+				label += ", certificate " + subResourceName(lp.SSLCertificate, "-")
+			}
+		}
+		listeners.Children = append(listeners.Children, ui.TreeNode{Label: label})
+	}
+	root.Children = append(root.Children, listeners)
+
+	rules := ui.TreeNode{Label: fmt.Sprintf("Routing rules (%d)", len(props.RequestRoutingRules))}
+	for _, rule := range props.RequestRoutingRules {
+		if rule != nil {
+			rules.Children = append(rules.Children, ui.TreeNode{Label: routingRuleLabel(rule)})
+		}
+	}
+	root.Children = append(root.Children, rules)
+
+	root.Children = append(root.Children, appGatewayPools(props, health))
+
+	settings := ui.TreeNode{Label: fmt.Sprintf("Backend settings (%d)", len(props.BackendHTTPSettingsCollection))}
+	for _, setting := range props.BackendHTTPSettingsCollection {
+		if setting == nil {
+			continue
+		}
+		label := orDash(setting.Name)
+		if sp := setting.Properties; sp != nil {
+			label += fmt.Sprintf("  %s :%s", strings.ToUpper(orDash(sp.Protocol)), intOrDash(sp.Port))
+			if sp.RequestTimeout != nil {
+				label += fmt.Sprintf(", timeout %ds", *sp.RequestTimeout)
+			}
+			switch {
+			case sp.PickHostNameFromBackendAddress != nil && *sp.PickHostNameFromBackendAddress:
+				label += ", host from backend"
+			case sp.HostName != nil:
+				label += ", host " + *sp.HostName
+			}
+			label += ", probe " + subResourceName(sp.Probe, "default")
+		}
+		settings.Children = append(settings.Children, ui.TreeNode{Label: label})
+	}
+	root.Children = append(root.Children, settings)
+
+	probes := ui.TreeNode{Label: fmt.Sprintf("Health probes (%d)", len(props.Probes))}
+	for _, probe := range props.Probes {
+		if probe == nil {
+			continue
+		}
+		label := orDash(probe.Name)
+		if pp := probe.Properties; pp != nil {
+			host := orDash(pp.Host)
+			if pp.PickHostNameFromBackendHTTPSettings != nil && *pp.PickHostNameFromBackendHTTPSettings {
+				host = "from backend settings"
+			}
+			label += fmt.Sprintf("  %s %s, host %s", strings.ToUpper(orDash(pp.Protocol)), orDash(pp.Path), host)
+			if pp.Interval != nil {
+				label += fmt.Sprintf(", every %ds", *pp.Interval)
+			}
+			if pp.UnhealthyThreshold != nil {
+				label += fmt.Sprintf(", down after %d failures", *pp.UnhealthyThreshold)
+			}
+		}
+		probes.Children = append(probes.Children, ui.TreeNode{Label: label})
+	}
+	root.Children = append(root.Children, probes)
+	return root
+}
+
+func routingRuleLabel(rule *armnetwork.ApplicationGatewayRequestRoutingRule) string {
+	label := orDash(rule.Name)
+	props := rule.Properties
+	if props == nil {
+		return label
+	}
+	if props.Priority != nil {
+		label += " (priority " + strconv.Itoa(int(*props.Priority)) + ")"
+	}
+	label += "  " + subResourceName(props.HTTPListener, "-") + " → "
+	switch {
+	case props.RedirectConfiguration != nil:
+		label += "redirect " + subResourceName(props.RedirectConfiguration, "-")
+	case props.URLPathMap != nil:
+		label += "path map " + subResourceName(props.URLPathMap, "-")
+	default:
+		label += subResourceName(props.BackendAddressPool, "-") + " with " + subResourceName(props.BackendHTTPSettings, "-")
+	}
+	return label
+}
+
+// appGatewayPools lists each backend pool's targets. Once probed, targets
+// are the servers the gateway reported, with their health under each
+// backend setting and, when not healthy, the probe's explanation.
+func appGatewayPools(props *armnetwork.ApplicationGatewayPropertiesFormat, health *azure.AppGatewayHealthMsg) ui.TreeNode {
+	probed := map[string]*armnetwork.ApplicationGatewayBackendHealthPool{}
+	if health != nil && health.Err == nil {
+		for _, pool := range health.Health.BackendAddressPools {
+			if pool != nil && pool.BackendAddressPool != nil && pool.BackendAddressPool.ID != nil {
+				probed[strings.ToLower(*pool.BackendAddressPool.ID)] = pool
+			}
+		}
+	}
+
+	pools := ui.TreeNode{Label: fmt.Sprintf("Backend pools (%d)", len(props.BackendAddressPools))}
+	for _, pool := range props.BackendAddressPools {
+		if pool == nil {
+			continue
+		}
+		node := ui.TreeNode{Label: orDash(pool.Name)}
+		if result, ok := probed[strings.ToLower(orDash(pool.ID))]; ok {
+			node.Children = probedServers(result)
+		} else if pool.Properties != nil {
+			for _, address := range pool.Properties.BackendAddresses {
+				if address == nil {
+					continue
+				}
+				target := orDash(address.Fqdn)
+				if target == "-" {
+					target = orDash(address.IPAddress)
+				}
+				node.Children = append(node.Children, ui.TreeNode{Label: target})
+			}
+			for _, config := range pool.Properties.BackendIPConfigurations {
+				if config != nil && config.ID != nil {
+					node.Children = append(node.Children, ui.TreeNode{Label: memberName(*config.ID)})
+				}
+			}
+		}
+		if len(node.Children) == 0 {
+			node.Label += "  (empty)"
+		}
+		pools.Children = append(pools.Children, node)
+	}
+	return pools
+}
+
+func probedServers(pool *armnetwork.ApplicationGatewayBackendHealthPool) []ui.TreeNode {
+	var order []string
+	servers := map[string]*ui.TreeNode{}
+	for _, settings := range pool.BackendHTTPSettingsCollection {
+		if settings == nil {
+			continue
+		}
+		settingsName := "-"
+		if settings.BackendHTTPSettings != nil {
+			settingsName = orDash(settings.BackendHTTPSettings.Name)
+			if settingsName == "-" && settings.BackendHTTPSettings.ID != nil {
+				settingsName = resourceName(*settings.BackendHTTPSettings.ID)
+			}
+		}
+		for _, server := range settings.Servers {
+			if server == nil {
+				continue
+			}
+			address := orDash(server.Address)
+			node, ok := servers[address]
+			if !ok {
+				label := address
+				if server.IPConfiguration != nil && server.IPConfiguration.ID != nil {
+					label = memberName(*server.IPConfiguration.ID) + "  " + address
+				}
+				node = &ui.TreeNode{Label: label}
+				servers[address] = node
+				order = append(order, address)
+			}
+			state := orDash(server.Health)
+			if state != string(armnetwork.ApplicationGatewayBackendHealthServerHealthUp) {
+				state = strings.ToUpper(state)
+			}
+			child := ui.TreeNode{Label: settingsName + ": " + state}
+			if server.HealthProbeLog != nil && *server.HealthProbeLog != "" && state != string(armnetwork.ApplicationGatewayBackendHealthServerHealthUp) {
+				child.Children = append(child.Children, ui.TreeNode{Label: strings.Join(strings.Fields(*server.HealthProbeLog), " ")})
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	nodes := make([]ui.TreeNode, 0, len(order))
+	for _, address := range order {
+		nodes = append(nodes, *servers[address])
+	}
+	return nodes
+}
@@ -0,0 +1,316 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const (
+	testNetworkRG = "/subscriptions/sub-1/resourceGroups/rg-a/providers/"
+	testLBID      = testNetworkRG + "Microsoft.Network/loadBalancers/lb-web"
+	testAppGwID   = testNetworkRG + "Microsoft.Network/applicationGateways/agw-web"
+)
+
+func TestOpenLoadBalancer(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testLBID), Name: to.Ptr("lb-web"), Type: to.Ptr("Microsoft.Network/loadBalancers")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "lb" || model.lbID != testLBID {
+		t.Fatalf("enter on load balancer row opened %q (%s)", model.currentView, model.lbID)
+	}
+
+	frontendID := testLBID + "/frontendIPConfigurations/fe-public"
+	poolID := testLBID + "/backendAddressPools/pool-web"
+	vmNIC := testNetworkRG + "Microsoft.Network/networkInterfaces/vm1-nic/ipConfigurations/ipconfig1"
+	vmssNIC := testNetworkRG + "Microsoft.Compute/virtualMachineScaleSets/vmss-web/virtualMachines/3/networkInterfaces/nic/ipConfigurations/ip"
+	updated, _ := model.Update(azure.LoadBalancerMsg{
+		ID: testLBID,
+		LoadBalancer: armnetwork.LoadBalancer{
+			Name:     to.Ptr("lb-web"),
+			Location: to.Ptr("westeurope"),
+			SKU:      &armnetwork.LoadBalancerSKU{Name: to.Ptr(armnetwork.LoadBalancerSKUNameStandard), Tier: to.Ptr(armnetwork.LoadBalancerSKUTierRegional)},
+			Properties: &armnetwork.LoadBalancerPropertiesFormat{
+				FrontendIPConfigurations: []*armnetwork.FrontendIPConfiguration{{
+					ID:   to.Ptr(frontendID),
+					Name: to.Ptr("fe-public"),
+					Properties: &armnetwork.FrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &armnetwork.PublicIPAddress{ID: to.Ptr(testNetworkRG + "Microsoft.Network/publicIPAddresses/pip-lb")},
+					},
+				}},
+				BackendAddressPools: []*armnetwork.BackendAddressPool{
+					{ID: to.Ptr(poolID), Name: to.Ptr("pool-web")},
+					{ID: to.Ptr(testLBID + "/backendAddressPools/pool-old"), Name: to.Ptr("pool-old")},
+				},
+				LoadBalancingRules: []*armnetwork.LoadBalancingRule{{
+					Name: to.Ptr("http"),
+					Properties: &armnetwork.LoadBalancingRulePropertiesFormat{
+						Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: to.Ptr(frontendID)},
+						FrontendPort:            to.Ptr[int32](80),
+						BackendAddressPool:      &armnetwork.SubResource{ID: to.Ptr(poolID)},
+						BackendPort:             to.Ptr[int32](8080),
+						Probe:                   &armnetwork.SubResource{ID: to.Ptr(testLBID + "/probes/probe-http")},
+					},
+				}},
+				InboundNatRules: []*armnetwork.InboundNatRule{{
+					Name: to.Ptr("ssh-vm1"),
+					Properties: &armnetwork.InboundNatRulePropertiesFormat{
+						Protocol:                to.Ptr(armnetwork.TransportProtocolTCP),
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: to.Ptr(frontendID)},
+						FrontendPort:            to.Ptr[int32](50001),
+						BackendPort:             to.Ptr[int32](22),
+						BackendIPConfiguration:  &armnetwork.InterfaceIPConfiguration{ID: to.Ptr(vmNIC)},
+					},
+				}},
+				Probes: []*armnetwork.Probe{{
+					Name: to.Ptr("probe-http"),
+					Properties: &armnetwork.ProbePropertiesFormat{
+						Protocol:           to.Ptr(armnetwork.ProbeProtocolHTTP),
+						Port:               to.Ptr[int32](8080),
+						RequestPath:        to.Ptr("/healthz"),
+						IntervalInSeconds:  to.Ptr[int32](5),
+						ProbeThreshold:     to.Ptr[int32](2),
+						LoadBalancingRules: []*armnetwork.SubResource{{ID: to.Ptr(testLBID + "/loadBalancingRules/http")}},
+					},
+				}},
+			},
+		},
+		FrontendIPs: map[string]string{strings.ToLower(frontendID): "20.1.2.4"},
+		Members: []azure.BackendMember{
+			{Pool: poolID, Target: vmNIC, IP: "10.0.1.4", Availability: 100},
+			{Pool: poolID, Target: vmssNIC, IP: "10.0.1.7", Availability: 0},
+			{Pool: poolID, IP: "10.0.1.9", Availability: 60},
+		},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Backend health"); got != "1 of 3 members up" {
+		t.Errorf("Backend health = %q", got)
+	}
+	want := `lb-web (westeurope)
+├── Frontends (1)
+│   └── fe-public  20.1.2.4 (pip-lb)
+├── Load balancing rules (1)
+│   └── http  TCP fe-public:80 → pool-web:8080, probe probe-http
+├── Inbound NAT rules (1)
+│   └── ssh-vm1  TCP fe-public:50001 → vm1-nic:22
+├── Backend pools (2)
+│   ├── pool-web
+│   │   ├── vm1-nic  10.0.1.4  up
+│   │   ├── vmss-web instance 3  10.0.1.7  DOWN
+│   │   └── 10.0.1.9  degraded (60% of probes answered)
+│   └── pool-old  (empty)
+└── Health probes (1)
+    └── probe-http  HTTP :8080/healthz, every 5s, down after 2 failures`
+	if got := strings.Join(model.topology.allLines(), "\n"); got != want {
+		t.Errorf("topology =\n%s\nwant\n%s", got, want)
+	}
+
+	model, cmd = pressKeys(model, runes("r"))
+	if cmd == nil || !model.loading {
+		t.Error("r did not reload the load balancer")
+	}
+}
+
+func TestOpenAppGatewayProbesBackends(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testAppGwID), Name: to.Ptr("agw-web"), Type: to.Ptr("Microsoft.Network/applicationGateways")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "appgw" || model.appgwID != testAppGwID {
+		t.Fatalf("enter on application gateway row opened %q (%s)", model.currentView, model.appgwID)
+	}
+
+	poolID := testAppGwID + "/backendAddressPools/pool-web"
+	settingsID := testAppGwID + "/backendHttpSettingsCollection/settings-https"
+	updated, cmd := model.Update(azure.AppGatewayMsg{
+		ID: testAppGwID,
+		Gateway: armnetwork.ApplicationGateway{
+			Name:     to.Ptr("agw-web"),
+			Location: to.Ptr("westeurope"),
+			Properties: &armnetwork.ApplicationGatewayPropertiesFormat{
+				SKU:              &armnetwork.ApplicationGatewaySKU{Name: to.Ptr(armnetwork.ApplicationGatewaySKUNameWAFV2), Capacity: to.Ptr[int32](2)},
+				OperationalState: to.Ptr(armnetwork.ApplicationGatewayOperationalStateRunning),
+				FrontendPorts: []*armnetwork.ApplicationGatewayFrontendPort{{
+					ID:         to.Ptr(testAppGwID + "/frontendPorts/port-443"),
+					Properties: &armnetwork.ApplicationGatewayFrontendPortPropertiesFormat{Port: to.Ptr[int32](443)},
+				}},
+				FrontendIPConfigurations: []*armnetwork.ApplicationGatewayFrontendIPConfiguration{{
+					ID:   to.Ptr(testAppGwID + "/frontendIPConfigurations/fe-public"),
+					Name: to.Ptr("fe-public"),
+					Properties: &armnetwork.ApplicationGatewayFrontendIPConfigurationPropertiesFormat{
+						PublicIPAddress: &armnetwork.SubResource{ID: to.Ptr(testNetworkRG + "Microsoft.Network/publicIPAddresses/pip-agw")},
+					},
+				}},
+				HTTPListeners: []*armnetwork.ApplicationGatewayHTTPListener{{
+					Name: to.Ptr("listener-https"),
+					Properties: &armnetwork.ApplicationGatewayHTTPListenerPropertiesFormat{
+						Protocol:                to.Ptr(armnetwork.ApplicationGatewayProtocolHTTPS),
+						FrontendIPConfiguration: &armnetwork.SubResource{ID: to.Ptr(testAppGwID + "/frontendIPConfigurations/fe-public")},
+						FrontendPort:            &armnetwork.SubResource{ID: to.Ptr(testAppGwID + "/frontendPorts/port-443")},
+						HostNames:               []*string{to.Ptr("www.example.com")},
+						SSLCertificate:          &armnetwork.SubResource{ID: to.Ptr(testAppGwID + "/sslCertificates/cert-www")},
+					},
+				}},
+				RequestRoutingRules: []*armnetwork.ApplicationGatewayRequestRoutingRule{{
+					Name: to.Ptr("rule-https"),
+					Properties: &armnetwork.ApplicationGatewayRequestRoutingRulePropertiesFormat{
+						Priority:            to.Ptr[int32](100),
+						HTTPListener:        &armnetwork.SubResource{ID: to.Ptr(testAppGwID + "/httpListeners/listener-https")},
+						BackendAddressPool:  &armnetwork.SubResource{ID: to.Ptr(poolID)},
+						BackendHTTPSettings: &armnetwork.SubResource{ID: to.Ptr(settingsID)},
+					},
+				}},
+				BackendAddressPools: []*armnetwork.ApplicationGatewayBackendAddressPool{{
+					ID:   to.Ptr(poolID),
+					Name: to.Ptr("pool-web"),
+					Properties: &armnetwork.ApplicationGatewayBackendAddressPoolPropertiesFormat{
+						BackendAddresses: []*armnetwork.ApplicationGatewayBackendAddress{
+							{IPAddress: to.Ptr("10.0.1.4")},
+							{Fqdn: to.Ptr("app.azurewebsites.net")},
+						},
+					},
+				}},
+				BackendHTTPSettingsCollection: []*armnetwork.ApplicationGatewayBackendHTTPSettings{{
+					Name: to.Ptr("settings-https"),
+					Properties: &armnetwork.ApplicationGatewayBackendHTTPSettingsPropertiesFormat{
+						Protocol:                       to.Ptr(armnetwork.ApplicationGatewayProtocolHTTPS),
+						Port:                           to.Ptr[int32](443),
+						RequestTimeout:                 to.Ptr[int32](30),
+						PickHostNameFromBackendAddress: to.Ptr(true),
+						Probe:                          &armnetwork.SubResource{ID: to.Ptr(testAppGwID + "/probes/probe-https")},
+					},
+				}},
+				Probes: []*armnetwork.ApplicationGatewayProbe{{
+					Name: to.Ptr("probe-https"),
+					Properties: &armnetwork.ApplicationGatewayProbePropertiesFormat{
+						Protocol:                            to.Ptr(armnetwork.ApplicationGatewayProtocolHTTPS),
+						Path:                                to.Ptr("/health"),
+						PickHostNameFromBackendHTTPSettings: to.Ptr(true),
+						Interval:                            to.Ptr[int32](30),
+						UnhealthyThreshold:                  to.Ptr[int32](3),
+					},
+				}},
+			},
+		},
+		FrontendIPs: map[string]string{strings.ToLower(testAppGwID + "/frontendIPConfigurations/fe-public"): "20.1.2.5"},
+	})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Fatal("the gateway's backends were not probed")
+	}
+	if got := propertyValue(t, model, "Backend health"); got != "probing backends..." {
+		t.Errorf("Backend health while probing = %q", got)
+	}
+	if got := propertyValue(t, model, "SKU"); got != "WAF_v2, 2 instances" {
+		t.Errorf("SKU = %q", got)
+	}
+	if lines := model.topology.allLines(); !strings.Contains(strings.Join(lines, "\n"), "│       ├── 10.0.1.4\n│       └── app.azurewebsites.net") {
+		t.Errorf("configured backends missing before probing:\n%s", strings.Join(lines, "\n"))
+	}
+	if _, cmd = pressKeys(model, runes("r")); cmd != nil {
+		t.Error("r probed again while a probe was running")
+	}
+
+	updated, _ = model.Update(azure.AppGatewayHealthMsg{
+		ID: testAppGwID,
+		Health: armnetwork.ApplicationGatewayBackendHealth{
+			BackendAddressPools: []*armnetwork.ApplicationGatewayBackendHealthPool{{
+				BackendAddressPool: &armnetwork.ApplicationGatewayBackendAddressPool{ID: to.Ptr(poolID)},
+				BackendHTTPSettingsCollection: []*armnetwork.ApplicationGatewayBackendHealthHTTPSettings{{
+					BackendHTTPSettings: &armnetwork.ApplicationGatewayBackendHTTPSettings{ID: to.Ptr(settingsID)},
+					Servers: []*armnetwork.ApplicationGatewayBackendHealthServer{
+						{Address: to.Ptr("10.0.1.4"), Health: to.Ptr(armnetwork.ApplicationGatewayBackendHealthServerHealthUp)},
+						{
+							Address:        to.Ptr("app.azurewebsites.net"),
+							Health:         to.Ptr(armnetwork.ApplicationGatewayBackendHealthServerHealthDown),
+							HealthProbeLog: to.Ptr("Received invalid status code: 404 in the backend server's HTTP response.\nExpected 200-399."),
+						},
+					},
+				}},
+			}},
+		},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Backend health"); got != "1 up, 1 down" {
+		t.Errorf("Backend health = %q", got)
+	}
+	want := `agw-web (westeurope)
+├── Frontends (1)
+│   └── fe-public  20.1.2.5 (pip-agw)
+├── Listeners (1)
+│   └── listener-https  HTTPS fe-public:443, hosts www.example.com, certificate cert-www
+├── Routing rules (1)
+│   └── rule-https (priority 100)  listener-https → pool-web with settings-https
+├── Backend pools (1)
+│   └── pool-web
+│       ├── 10.0.1.4
+│       │   └── settings-https: Up
+│       └── app.azurewebsites.net
+│           └── settings-https: DOWN
+│               └── Received invalid status code: 404 in the backend server's HTTP response. Expected 200-399.
+├── Backend settings (1)
+│   └── settings-https  HTTPS :443, timeout 30s, host from backend, probe probe-https
+└── Health probes (1)
+    └── probe-https  HTTPS /health, host from backend settings, every 30s, down after 3 failures`
+	if got := strings.Join(model.topology.allLines(), "\n"); got != want {
+		t.Errorf("topology =\n%s\nwant\n%s", got, want)
+	}
+
+	model, cmd = pressKeys(model, runes("r"))
+	if cmd == nil || model.appgwHealth != nil {
+		t.Error("r did not probe the backends again")
+	}
+}
+
+func TestAppGatewayRepliesAfterEsc(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testAppGwID), Name: to.Ptr("agw-web"), Type: to.Ptr("Microsoft.Network/applicationGateways")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEsc})
+
+	for _, msg := range []tea.Msg{
+		azure.AppGatewayMsg{ID: testAppGwID, Gateway: armnetwork.ApplicationGateway{Name: to.Ptr("agw-web")}},
+		azure.AppGatewayHealthMsg{ID: testAppGwID},
+	} {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		if cmd != nil || model.currentView != "resources" || model.loading {
+			t.Errorf("late %T left view %q loading=%v", msg, model.currentView, model.loading)
+		}
+	}
+}
+
+func TestFrontendAddress(t *testing.T) {
+	public := testLBID + "/frontendIPConfigurations/fe-public"
+	failed := testLBID + "/frontendIPConfigurations/fe-other"
+	addresses := map[string]string{strings.ToLower(public): "20.1.2.4"}
+	errs := map[string]error{strings.ToLower(failed): errors.New("AuthorizationFailed")}
+
+	tests := []struct {
+		name       string
+		frontendID *string
+		want       string
+	}{
+		{name: "resolved", frontendID: to.Ptr(public), want: "20.1.2.4"},
+		{name: "unreadable", frontendID: to.Ptr(failed), want: "- (AuthorizationFailed)"},
+		{name: "unallocated", frontendID: to.Ptr(testLBID + "/frontendIPConfigurations/fe-new"), want: "-"},
+		{name: "no ID", want: "-"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := frontendAddress(addresses, errs, tt.frontendID); got != tt.want {
+				t.Errorf("frontendAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,20 +5,28 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
 	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
 )
 
 type Model struct {
+	cfg                  config.Config
 	table                table.Model
 	spinner              spinner.Model
 	loading              bool
 	width                int
 	height               int
 	header               string
+	authPrompt           string
 	subscriptions        []armsubscription.Subscription
+	tenants              []armsubscription.TenantIDDescription
+	tenantID             string
+	subscriptionTenants  map[string]string
 	resourceGroups       map[string][]armresources.ResourceGroup
 	resources            map[string][]armresources.GenericResourceExpanded
 	currentView          string
@@ -30,15 +38,150 @@ type Model struct {
 	showTabs             bool
 	searchMode           bool
 	searchQuery          string
+	commandMode          bool
+	commandInput         string
+
+	// Detail views show a property panel above the table and return to the
+	// view they were opened from on esc. Detail views can open further
+	// detail views, so the views to return to are kept as a stack.
+	viewStack  []string
+	properties []ui.Property
+	whoami     azure.WhoamiMsg
+	aksID      string
+	aksCluster azure.AKSClusterMsg
+	vmID       string
+	vm         azure.VMMsg
+	vmssID     string
+	vmss       azure.VMSSMsg
+
+	// Disks view; rowDisks holds the disk or snapshot behind each row.
+	disksSub        string
+	disksRG         string
+	disks           azure.DisksMsg
+	disksUnattached bool
+	disksSelect     string
+	rowDisks        []diskRow
+
+	// NSG view; rowRules holds the rule behind each row.
+	nsgID       string
+	nsg         azure.NSGMsg
+	nsgDefaults bool
+	nsgSelect   string
+	rowRules    []nsgRow
+
+	// Virtual network view
+	vnetID   string
+	vnet     azure.VNetMsg
+	topology textPane
+
+	// Load balancer and application gateway views, drawn in topology;
+	// appgwHealth is nil while the gateway probes its backends.
+	lbID        string
+	lb          azure.LoadBalancerMsg
+	appgwID     string
+	appgw       azure.AppGatewayMsg
+	appgwHealth *azure.AppGatewayHealthMsg
+
+	// Public IPs view; exposure is nil until the report is first computed.
+	pipsSub    string
+	pipsRG     string
+	pips       azure.PublicIPsMsg
+	pipsSelect string
+	pipsReport bool
+	exposure   *azure.ExposureMsg
+
+	// Private endpoints and DNS zones view; dnsIssues are keyed by
+	// lower-cased endpoint ID. dnsZone is the zone whose records are shown.
+	plSub       string
+	plRG        string
+	privateLink azure.PrivateLinkMsg
+	plZones     bool
+	plSelect    string
+	dnsIssues   map[string][]azure.DNSIssue
+	dnsZone     azure.PrivateZone
+
+	// Key vault browser; vaultVersions are the versions of vaultItem.
+	// secret is the revealed secret value, hidden again by the tick of
+	// secretGen.
+	vaultID       string
+	vault         azure.KeyVaultMsg
+	vaultKind     string
+	vaultSelect   string
+	vaultItem     azure.VaultItem
+	vaultVersions []azure.VaultItem
+	secret        *azure.SecretValueMsg
+	secretGen     int
+
+	// Expiry radar over expiringSubs, for items expiring within
+	// expiringDays.
+	expiringSubs []string
+	expiringDays int
+	expiring     azure.ExpiringMsg
+
+	// Effective security rules and routes view
+	effectiveID     string
+	effective       azure.EffectiveNetworkMsg
+	effectiveRoutes bool
+
+	// Storage explorer; storageEntries are the rows at storagePath.
+	storage        azure.StorageAccount
+	storagePath    azure.StoragePath
+	storageEntries []azure.StorageEntry
+	storageErrors  map[string]error
+	storageSelect  string
+
+	// Blob transfers and previews.
+	transfer    *transferState
+	transferID  int
+	preview     textPane
+	previewBlob string
+	sas         azure.SASMsg
+
+	// :run script editor and results. markedVMs is keyed by lower-cased
+	// resource ID.
+	markedVMs     map[string]runTarget
+	runEditor     textarea.Model
+	runTargetList []runTarget
+	runScriptText string
+	runID         int
+	runOutput     textPane
+
+	// Serial log viewer; bootLogOffset is how much of the blob has been read.
+	// bootLogRenewed is set while a refused URI is being replaced.
+	bootLog        textPane
+	bootLogVM      string
+	bootLogURI     string
+	bootLogOffset  int64
+	bootLogGen     int
+	bootLogRenewed bool
+
+	prompt          *prompt
+	flash           string
+	operations      []operation
+	nextOperationID int
+
+	// Aggregated views across several marked subscriptions
+	markedSubs       map[string]bool
+	multiSub         bool
+	subResources     map[string][]armresources.GenericResourceExpanded
+	subErrors        map[string]error
+	rowSubscriptions []string
+
+	// rowResources holds the resource behind each row of the resources view.
+	rowResources []armresources.GenericResourceExpanded
 }
 
-func New() Model {
+func New(cfg config.Config) Model {
 	return Model{
+		cfg:                  cfg,
 		table:                initTable(),
 		spinner:              initSpinner(),
 		loading:              true,
 		resourceGroups:       make(map[string][]armresources.ResourceGroup),
 		resources:            make(map[string][]armresources.GenericResourceExpanded),
+		markedSubs:           make(map[string]bool),
+		markedVMs:            make(map[string]runTarget),
+		subResources:         make(map[string][]armresources.GenericResourceExpanded),
 		currentView:          "subscriptions",
 		currentTab:           "All",
 		selectedResourceType: "",
@@ -100,6 +243,18 @@ func (m *Model) updateLayout(width, height int) {
 	} else {
 		tableHeight -= 4 // Just header and footer for other views
 	}
+	if m.multiSub {
+		tableHeight -= len(m.subErrors) // One warning line per failed subscription
+	}
+	if len(m.properties) > 0 {
+		tableHeight -= len(m.properties) + 1 // Property panel and spacing
+	}
+	if m.commandMode || m.prompt != nil {
+		tableHeight -= 2 // Command or action prompt
+	}
+	if m.flash != "" {
+		tableHeight-- // Flash message
+	}
 	if tableHeight < 3 {
 		tableHeight = 3 // Minimum height for table
 	}
@@ -112,6 +267,51 @@ func (m *Model) updateLayout(width, height int) {
 	switch m.currentView {
 	case "subscriptions":
 		m.updateTableWithSubscriptions()
+	case "tenants":
+		m.updateTableWithTenants()
+	case "whoami":
+		m.updateTableWithWhoami()
+	case "aks":
+		m.updateTableWithAKS()
+	case "vm":
+		m.updateTableWithVM()
+	case "vmss":
+		m.updateTableWithVMSS()
+	case "disks":
+		m.updateTableWithDisks()
+	case "nsg":
+		m.updateTableWithNSG()
+	case "effective":
+		m.updateTableWithEffective()
+	case "pips":
+		m.updateTableWithPublicIPs()
+	case "privatelink":
+		m.updateTableWithPrivateLink()
+	case "dnszone":
+		m.updateTableWithDNSZone()
+	case "keyvault":
+		m.updateTableWithKeyVault()
+	case "vaultversions":
+		m.updateTableWithVaultVersions()
+	case "expiring":
+		m.updateTableWithExpiring()
+	case "storage":
+		m.updateTableWithStorage()
+	case "transfers":
+		m.updateTableWithTransfers()
+	case "blobpreview":
+		m.preview.setSize(width, tableHeight-1)
+	case "vnet", "lb", "appgw":
+		m.topology.setSize(width, tableHeight-1)
+	case "bootlog":
+		m.bootLog.setSize(width, tableHeight-1) // Status line above the log
+	case "runscript":
+		m.runEditor.SetWidth(width)
+		m.runEditor.SetHeight(tableHeight)
+	case "runresults":
+		m.runOutput.setSize(width, tableHeight-1)
+	case "operations":
+		m.updateTableWithOperations()
 	case "resourcegroups":
 		m.updateTableWithResourceGroups()
 	case "resources":
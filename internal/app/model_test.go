@@ -2,10 +2,12 @@ package app
 
 import (
 	"testing"
+
+	"github.com/mbaykara/azurermcli/internal/config"
 )
 
 func TestNewModel(t *testing.T) {
-	model := New()
+	model := New(config.Config{})
 
 	// Test initial state
 	if model.currentView != "subscriptions" {
@@ -71,7 +73,7 @@ func TestUpdateLayout(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			model := New()
+			model := New(config.Config{})
 			model.currentView = tt.currentView
 			model.searchMode = tt.searchMode
 
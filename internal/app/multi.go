@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+// markedPrefix is shown in front of subscriptions marked for aggregated views.
+const markedPrefix = "✓ "
+
+// toggleMarkedSubscription marks or unmarks the subscription under the cursor,
+// keeping the cursor where it is.
+func (m *Model) toggleMarkedSubscription() {
+	selected := m.table.SelectedRow()
+	if len(selected) < 2 {
+		return
+	}
+
+	subID := selected[1]
+	if m.markedSubs[subID] {
+		delete(m.markedSubs, subID)
+	} else {
+		m.markedSubs[subID] = true
+	}
+
+	cursor := m.table.Cursor()
+	m.updateTableWithSubscriptions()
+	m.table.SetCursor(cursor)
+}
+
+// markedSubscriptionIDs returns the marked subscriptions in the order they are
+// listed in the subscriptions view.
+func (m Model) markedSubscriptionIDs() []string {
+	var ids []string
+	for _, sub := range m.subscriptions {
+		if m.markedSubs[*sub.SubscriptionID] {
+			ids = append(ids, *sub.SubscriptionID)
+		}
+	}
+	return ids
+}
+
+// subscriptionName resolves a subscription ID to its display name.
+func (m Model) subscriptionName(subID string) string {
+	for _, sub := range m.subscriptions {
+		if *sub.SubscriptionID == subID && sub.DisplayName != nil {
+			return *sub.DisplayName
+		}
+	}
+	return subID
+}
+
+// aggregatedResources reports whether the resources view spans all resource
+// groups of the marked subscriptions rather than a single resource group.
+func (m Model) aggregatedResources() bool {
+	return m.multiSub && m.selectedRG == ""
+}
+
+// reloadResources fetches the resources shown in the resources view.
+func (m Model) reloadResources() tea.Cmd {
+	if m.aggregatedResources() {
+		return azure.FetchResourcesMulti(m.markedSubscriptionIDs())
+	}
+	return azure.FetchResources(m.selectedSub, m.selectedRG)
+}
+
+// subscriptionWarnings describes the subscriptions that failed to load in an
+// aggregated view, one line per subscription.
+func (m Model) subscriptionWarnings() []string {
+	var warnings []string
+	for subID, err := range m.subErrors {
+		warnings = append(warnings, fmt.Sprintf("%s: %v", m.subscriptionName(subID), err))
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+func (m *Model) updateTableWithAggregatedResourceGroups() {
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.35)     // 35% of width
+	subWidth := int(float64(m.width) * 0.35)      // 35% of width
+	locationWidth := int(float64(m.width) * 0.15) // 15% of width
+	statusWidth := int(float64(m.width) * 0.15)   // 15% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Subscription", Width: subWidth},
+		{Title: "Location", Width: locationWidth},
+		{Title: "Status", Width: statusWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	m.rowSubscriptions = nil
+	for _, subID := range m.markedSubscriptionIDs() {
+		for _, group := range m.resourceGroups[subID] {
+			rows = append(rows, table.Row{
+				*group.Name,
+				m.subscriptionName(subID),
+				*group.Location,
+				"Available",
+			})
+			m.rowSubscriptions = append(m.rowSubscriptions, subID)
+		}
+	}
+	m.table.SetRows(rows)
+	if len(rows) > 0 {
+		m.table.SetCursor(0)
+	}
+}
+
+func (m *Model) updateTableWithAggregatedResources() {
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.3)    // 30% of width
+	subWidth := int(float64(m.width) * 0.25)    // 25% of width
+	typeWidth := int(float64(m.width) * 0.3)    // 30% of width
+	statusWidth := int(float64(m.width) * 0.15) // 15% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Subscription", Width: subWidth},
+		{Title: "Type", Width: typeWidth},
+		{Title: "Status", Width: statusWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	m.rowSubscriptions = nil
+	m.rowResources = nil
+	for _, subID := range m.markedSubscriptionIDs() {
+		for _, resource := range m.subResources[subID] {
+			matchesTab := m.selectedResourceType == "All" || matchResourceType(*resource.Type, m.selectedResourceType)
+			matchesSearch := !m.searchMode || strings.Contains(strings.ToLower(*resource.Name), strings.ToLower(m.searchQuery))
+			if !matchesTab || !matchesSearch {
+				continue
+			}
+
+			resourceType := *resource.Type
+			if m.selectedResourceType != "All" {
+				resourceType = formatResourceType(resourceType)
+			}
+			rows = append(rows, table.Row{
+				m.resourceRowName(*resource.Name, resource.ID),
+				m.subscriptionName(subID),
+				resourceType,
+				getResourceStatus(resource),
+			})
+			m.rowSubscriptions = append(m.rowSubscriptions, subID)
+			m.rowResources = append(m.rowResources, resource)
+		}
+	}
+
+	if len(rows) == 0 {
+		message := fmt.Sprintf("No %s found in the selected subscriptions", strings.ToLower(m.selectedResourceType))
+		if m.searchMode {
+			message = fmt.Sprintf("No matches for '%s'", m.searchQuery)
+		}
+		rows = append(rows, table.Row{message, "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
@@ -0,0 +1,133 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
+)
+
+func newTestSubscription(name, id string) armsubscription.Subscription {
+	return armsubscription.Subscription{
+		DisplayName:    to.Ptr(name),
+		SubscriptionID: to.Ptr(id),
+		State:          to.Ptr(armsubscription.SubscriptionStateEnabled),
+	}
+}
+
+func newMultiSubModel() Model {
+	model := New(config.Config{})
+	model.loading = false
+	model.updateLayout(120, 40)
+	updated, _ := model.Update(azure.SubscriptionsMsg{Subs: []armsubscription.Subscription{
+		newTestSubscription("prod", "sub-1"),
+		newTestSubscription("dev", "sub-2"),
+		newTestSubscription("test", "sub-3"),
+	}})
+	return updated.(Model)
+}
+
+func TestToggleMarkedSubscription(t *testing.T) {
+	model := newMultiSubModel()
+
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	updated, _ := model.Update(space)
+	model = updated.(Model)
+
+	if !model.markedSubs["sub-1"] {
+		t.Fatalf("sub-1 not marked after space")
+	}
+	if got := model.table.SelectedRow()[0]; got != markedPrefix+"prod" {
+		t.Errorf("marked row name = %q, want %q", got, markedPrefix+"prod")
+	}
+
+	model.table.SetCursor(2)
+	updated, _ = model.Update(space)
+	model = updated.(Model)
+	if model.table.Cursor() != 2 {
+		t.Errorf("cursor = %d after toggle, want 2", model.table.Cursor())
+	}
+
+	got := model.markedSubscriptionIDs()
+	if len(got) != 2 || got[0] != "sub-1" || got[1] != "sub-3" {
+		t.Errorf("markedSubscriptionIDs() = %v, want [sub-1 sub-3]", got)
+	}
+
+	updated, _ = model.Update(space)
+	model = updated.(Model)
+	if model.markedSubs["sub-3"] {
+		t.Error("sub-3 still marked after second toggle")
+	}
+}
+
+func TestAggregatedResourceGroups(t *testing.T) {
+	model := newMultiSubModel()
+	model.markedSubs["sub-1"] = true
+	model.markedSubs["sub-2"] = true
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if cmd == nil || !model.multiSub || model.currentView != "resourcegroups" {
+		t.Fatalf("enter with marked subscriptions did not open aggregated resource groups")
+	}
+
+	updated, _ = model.Update(azure.MultiResourceGroupsMsg{
+		Groups: map[string][]armresources.ResourceGroup{
+			"sub-1": {{Name: to.Ptr("rg-a"), Location: to.Ptr("westeurope")}},
+		},
+		Errors: map[string]error{"sub-2": errors.New("forbidden")},
+	})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	if rows[0][0] != "rg-a" || rows[0][1] != "prod" {
+		t.Errorf("row = %v, want rg-a in prod", rows[0])
+	}
+
+	warnings := model.subscriptionWarnings()
+	if len(warnings) != 1 || warnings[0] != "dev: forbidden" {
+		t.Errorf("subscriptionWarnings() = %v, want [dev: forbidden]", warnings)
+	}
+
+	updated, _ = model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if model.selectedSub != "sub-1" || model.selectedRG != "rg-a" {
+		t.Errorf("selected = %s/%s, want sub-1/rg-a", model.selectedSub, model.selectedRG)
+	}
+}
+
+func TestAggregatedResources(t *testing.T) {
+	model := newMultiSubModel()
+	model.markedSubs["sub-1"] = true
+	model.markedSubs["sub-2"] = true
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'a'}})
+	model = updated.(Model)
+	if !model.aggregatedResources() {
+		t.Fatal("a did not open the aggregated resources view")
+	}
+
+	updated, _ = model.Update(azure.MultiResourcesMsg{
+		Resources: map[string][]armresources.GenericResourceExpanded{
+			"sub-1": {{Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")}},
+			"sub-2": {{Name: to.Ptr("vnet1"), Type: to.Ptr("Microsoft.Network/virtualNetworks")}},
+		},
+	})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(rows))
+	}
+	if rows[1][0] != "vnet1" || rows[1][1] != "dev" {
+		t.Errorf("row = %v, want vnet1 in dev", rows[1])
+	}
+}
@@ -0,0 +1,583 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// Custom security rules take priorities in this range; the default rules
+// sit above it.
+const (
+	minRulePriority = 100
+	maxRulePriority = 4096
+)
+
+// nsgRow is one rule of the NSG view.
+type nsgRow struct {
+	rule      *armnetwork.SecurityRule
+	isDefault bool
+}
+
+func (m *Model) openNSG(resourceID string) tea.Cmd {
+	m.openDetailView("nsg")
+	m.nsgID = resourceID
+	m.nsgSelect = ""
+	return azure.FetchNSG(resourceID)
+}
+
+// nsgRules returns the custom rules of the NSG, and its default rules too
+// when withDefaults is set, by direction and then priority.
+func (m Model) nsgRules(withDefaults bool) []nsgRow {
+	var rows []nsgRow
+	props := m.nsg.Group.Properties
+	if props == nil {
+		return nil
+	}
+	for _, rule := range props.SecurityRules {
+		if rule != nil && rule.Properties != nil {
+			rows = append(rows, nsgRow{rule: rule})
+		}
+	}
+	if withDefaults {
+		for _, rule := range props.DefaultSecurityRules {
+			if rule != nil && rule.Properties != nil {
+				rows = append(rows, nsgRow{rule: rule, isDefault: true})
+			}
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].rule.Properties, rows[j].rule.Properties
+		if da, db := orDash(a.Direction), orDash(b.Direction); da != db {
+			return da == string(armnetwork.SecurityRuleDirectionInbound)
+		}
+		return int32OrZero(a.Priority) < int32OrZero(b.Priority)
+	})
+	return rows
+}
+
+func int32OrZero(p *int32) int32 {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
+
+// subnetName names a subnet by its virtual network and itself.
+func subnetName(subnetID string) string {
+	id, err := arm.ParseResourceID(subnetID)
+	if err != nil || id.Parent == nil {
+		return resourceName(subnetID)
+	}
+	return id.Parent.Name + "/" + id.Name
+}
+
+func (m *Model) updateTableWithNSG() {
+	group := m.nsg.Group
+	inbound, outbound := 0, 0
+	for _, row := range m.nsgRules(false) {
+		if orDash(row.rule.Properties.Direction) == string(armnetwork.SecurityRuleDirectionInbound) {
+			inbound++
+		} else {
+			outbound++
+		}
+	}
+	subnets, nics := "-", "-"
+	if props := group.Properties; props != nil {
+		var names []string
+		for _, subnet := range props.Subnets {
+			if subnet != nil && subnet.ID != nil {
+				names = append(names, subnetName(*subnet.ID))
+			}
+		}
+		if len(names) > 0 {
+			subnets = strings.Join(names, ", ")
+		}
+		names = nil
+		for _, nic := range props.NetworkInterfaces {
+			if nic != nil && nic.ID != nil {
+				names = append(names, resourceName(*nic.ID))
+			}
+		}
+		if len(names) > 0 {
+			nics = strings.Join(names, ", ")
+		}
+	}
+	defaults := "hidden"
+	if m.nsgDefaults {
+		defaults = "shown"
+	}
+	m.properties = []ui.Property{
+		{Key: "Network security group", Value: orDash(group.Name)},
+		{Key: "Location", Value: orDash(group.Location)},
+		{Key: "Rules", Value: fmt.Sprintf("%d inbound, %d outbound", inbound, outbound)},
+		{Key: "Subnets", Value: subnets},
+		{Key: "Network interfaces", Value: nics},
+		{Key: "Default rules", Value: defaults},
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	directionWidth := int(float64(m.width) * 0.08)   // 8% of width
+	priorityWidth := int(float64(m.width) * 0.07)    // 7% of width
+	nameWidth := int(float64(m.width) * 0.2)         // 20% of width
+	protocolWidth := int(float64(m.width) * 0.07)    // 7% of width
+	sourceWidth := int(float64(m.width) * 0.17)      // 17% of width
+	sourcePortWidth := int(float64(m.width) * 0.08)  // 8% of width
+	destinationWidth := int(float64(m.width) * 0.17) // 17% of width
+	portWidth := int(float64(m.width) * 0.08)        // 8% of width
+	accessWidth := int(float64(m.width) * 0.08)      // 8% of width
+
+	columns := []table.Column{
+		{Title: "Direction", Width: directionWidth},
+		{Title: "Priority", Width: priorityWidth},
+		{Title: "Name", Width: nameWidth},
+		{Title: "Protocol", Width: protocolWidth},
+		{Title: "Source", Width: sourceWidth},
+		{Title: "Src Ports", Width: sourcePortWidth},
+		{Title: "Destination", Width: destinationWidth},
+		{Title: "Dst Ports", Width: portWidth},
+		{Title: "Action", Width: accessWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	m.rowRules = m.nsgRules(m.nsgDefaults)
+	for _, row := range m.rowRules {
+		props := row.rule.Properties
+		name := orDash(row.rule.Name)
+		if row.isDefault {
+			name += " (default)"
+		}
+		rows = append(rows, table.Row{
+			orDash(props.Direction),
+			intOrDash(props.Priority),
+			name,
+			orDash(props.Protocol),
+			ruleSource(props),
+			rulePorts(props.SourcePortRange, props.SourcePortRanges),
+			ruleDestination(props),
+			rulePorts(props.DestinationPortRange, props.DestinationPortRanges),
+			orDash(props.Access),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No rules", "-", "-", "-", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+	if m.nsgSelect != "" {
+		for i, row := range m.rowRules {
+			if !row.isDefault && strings.EqualFold(orDash(row.rule.Name), m.nsgSelect) {
+				m.table.SetCursor(i)
+			}
+		}
+	}
+}
+
+// ruleAddresses formats a rule side: its prefix, its prefixes or, failing
+// both, its application security groups.
+func ruleAddresses(prefix *string, prefixes []*string, groups []*armnetwork.ApplicationSecurityGroup) string {
+	if len(prefixes) > 0 {
+		return joinList(prefixes)
+	}
+	if prefix != nil && *prefix != "" {
+		return *prefix
+	}
+	var names []string
+	for _, group := range groups {
+		if group != nil && group.ID != nil {
+			names = append(names, "asg:"+resourceName(*group.ID))
+		}
+	}
+	if len(names) == 0 {
+		return "-"
+	}
+	return strings.Join(names, ",")
+}
+
+func ruleSource(props *armnetwork.SecurityRulePropertiesFormat) string {
+	return ruleAddresses(props.SourceAddressPrefix, props.SourceAddressPrefixes, props.SourceApplicationSecurityGroups)
+}
+
+func ruleDestination(props *armnetwork.SecurityRulePropertiesFormat) string {
+	return ruleAddresses(props.DestinationAddressPrefix, props.DestinationAddressPrefixes, props.DestinationApplicationSecurityGroups)
+}
+
+func rulePorts(port *string, ports []*string) string {
+	if len(ports) > 0 {
+		return joinList(ports)
+	}
+	return orDash(port)
+}
+
+// joinList joins values with commas and no spaces, the way rule specs take
+// them.
+func joinList(values []*string) string {
+	var parts []string
+	for _, v := range values {
+		if v != nil && *v != "" {
+			parts = append(parts, *v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ruleSpecKeys are the fields of a rule spec, in the order they are written.
+var ruleSpecKeys = []string{"name", "direction", "priority", "access", "protocol", "source", "sourcePorts", "destination", "ports"}
+
+// formatRuleSpec writes a rule as the one-line spec the add and edit
+// prompts take, such as
+//
+//	name=allow-https direction=Inbound priority=200 access=Allow protocol=Tcp source=* sourcePorts=* destination=* ports=443
+func formatRuleSpec(rule *armnetwork.SecurityRule) string {
+	props := rule.Properties
+	values := map[string]string{
+		"name":        orDash(rule.Name),
+		"direction":   orDash(props.Direction),
+		"priority":    intOrDash(props.Priority),
+		"access":      orDash(props.Access),
+		"protocol":    orDash(props.Protocol),
+		"source":      ruleSource(props),
+		"sourcePorts": rulePorts(props.SourcePortRange, props.SourcePortRanges),
+		"destination": ruleDestination(props),
+		"ports":       rulePorts(props.DestinationPortRange, props.DestinationPortRanges),
+	}
+	parts := make([]string, 0, len(ruleSpecKeys))
+	for _, key := range ruleSpecKeys {
+		parts = append(parts, key+"="+values[key])
+	}
+	return strings.Join(parts, " ")
+}
+
+// parseRuleSpec applies a rule spec to a copy of base, so that fields the
+// spec leaves as they were, such as application security groups or the
+// description, survive an edit.
+func parseRuleSpec(spec string, base *armnetwork.SecurityRule) (armnetwork.SecurityRule, error) {
+	values := map[string]string{}
+	for _, field := range strings.Fields(spec) {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok || value == "" {
+			return armnetwork.SecurityRule{}, fmt.Errorf("%q is not key=value", field)
+		}
+		known := false
+		for _, k := range ruleSpecKeys {
+			if strings.EqualFold(k, key) {
+				key, known = k, true
+			}
+		}
+		if !known {
+			return armnetwork.SecurityRule{}, fmt.Errorf("unknown field %q, use %s", key, strings.Join(ruleSpecKeys, ", "))
+		}
+		values[key] = value
+	}
+
+	rule := armnetwork.SecurityRule{Properties: &armnetwork.SecurityRulePropertiesFormat{}}
+	if base != nil && base.Properties != nil {
+		props := *base.Properties
+		props.ProvisioningState = nil
+		rule.Name = base.Name
+		rule.Properties = &props
+	}
+	props := rule.Properties
+	previous := map[string]string{}
+	if base != nil && base.Properties != nil {
+		for _, field := range strings.Fields(formatRuleSpec(base)) {
+			key, value, _ := strings.Cut(field, "=")
+			previous[key] = value
+		}
+	}
+
+	if name, ok := values["name"]; ok {
+		rule.Name = to.Ptr(name)
+	}
+	if rule.Name == nil {
+		return rule, fmt.Errorf("the rule needs a name")
+	}
+
+	if value, ok := values["direction"]; ok {
+		direction, err := matchEnum(value, armnetwork.PossibleSecurityRuleDirectionValues())
+		if err != nil {
+			return rule, fmt.Errorf("direction: %w", err)
+		}
+		props.Direction = &direction
+	}
+	if value, ok := values["access"]; ok {
+		access, err := matchEnum(value, armnetwork.PossibleSecurityRuleAccessValues())
+		if err != nil {
+			return rule, fmt.Errorf("access: %w", err)
+		}
+		props.Access = &access
+	}
+	if value, ok := values["protocol"]; ok {
+		protocol, err := matchEnum(value, armnetwork.PossibleSecurityRuleProtocolValues())
+		if err != nil {
+			return rule, fmt.Errorf("protocol: %w", err)
+		}
+		props.Protocol = &protocol
+	}
+	if value, ok := values["priority"]; ok {
+		priority, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return rule, fmt.Errorf("priority %q is not a number", value)
+		}
+		props.Priority = to.Ptr(int32(priority))
+	}
+	if props.Direction == nil || props.Access == nil || props.Priority == nil {
+		return rule, fmt.Errorf("the rule needs a direction, an access and a priority")
+	}
+	if priority := *props.Priority; priority < minRulePriority || priority > maxRulePriority {
+		return rule, fmt.Errorf("priority %d is outside %d-%d", priority, minRulePriority, maxRulePriority)
+	}
+	if props.Protocol == nil {
+		props.Protocol = to.Ptr(armnetwork.SecurityRuleProtocolAsterisk)
+	}
+
+	// Sides the spec changes are rewritten from scratch; untouched ones keep
+	// whatever they had, application security groups included.
+	if value := values["source"]; value != "" && value != previous["source"] {
+		props.SourceAddressPrefix, props.SourceAddressPrefixes = splitList(value)
+		props.SourceApplicationSecurityGroups = nil
+	}
+	if value := values["destination"]; value != "" && value != previous["destination"] {
+		props.DestinationAddressPrefix, props.DestinationAddressPrefixes = splitList(value)
+		props.DestinationApplicationSecurityGroups = nil
+	}
+	if value := values["sourcePorts"]; value != "" && value != previous["sourcePorts"] {
+		props.SourcePortRange, props.SourcePortRanges = splitList(value)
+	}
+	if value := values["ports"]; value != "" && value != previous["ports"] {
+		props.DestinationPortRange, props.DestinationPortRanges = splitList(value)
+	}
+	for _, side := range []struct {
+		name   string
+		single *string
+		list   []*string
+		groups []*armnetwork.ApplicationSecurityGroup
+	}{
+		{"source", props.SourceAddressPrefix, props.SourceAddressPrefixes, props.SourceApplicationSecurityGroups},
+		{"destination", props.DestinationAddressPrefix, props.DestinationAddressPrefixes, props.DestinationApplicationSecurityGroups},
+		{"sourcePorts", props.SourcePortRange, props.SourcePortRanges, nil},
+		{"ports", props.DestinationPortRange, props.DestinationPortRanges, nil},
+	} {
+		if side.single == nil && len(side.list) == 0 && len(side.groups) == 0 {
+			if side.name == "sourcePorts" {
+				props.SourcePortRange = to.Ptr("*")
+				continue
+			}
+			return rule, fmt.Errorf("the rule needs %s", side.name)
+		}
+		for _, value := range append([]*string{side.single}, side.list...) {
+			if value != nil && strings.HasPrefix(*value, "asg:") {
+				return rule, fmt.Errorf("%s: application security groups cannot be set here", side.name)
+			}
+		}
+	}
+	return rule, nil
+}
+
+// splitList turns a comma-separated value into the single or the plural
+// field of a rule side.
+func splitList(value string) (*string, []*string) {
+	parts := strings.Split(value, ",")
+	if len(parts) == 1 {
+		return to.Ptr(parts[0]), nil
+	}
+	list := make([]*string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, to.Ptr(part))
+		}
+	}
+	return nil, list
+}
+
+// matchEnum finds value among the values of an SDK enum, ignoring case.
+func matchEnum[T ~string](value string, possible []T) (T, error) {
+	names := make([]string, 0, len(possible))
+	for _, p := range possible {
+		if strings.EqualFold(string(p), value) {
+			return p, nil
+		}
+		names = append(names, string(p))
+	}
+	return "", fmt.Errorf("%q is not one of %s", value, strings.Join(names, ", "))
+}
+
+// ruleConflict reports why rule cannot be saved next to the NSG's other
+// custom rules: a name taken by another rule when adding, or a priority
+// already used in the same direction. editing names the rule being edited.
+func (m Model) ruleConflict(rule armnetwork.SecurityRule, editing string) error {
+	name := *rule.Name
+	direction := orDash(rule.Properties.Direction)
+	priority := *rule.Properties.Priority
+	used := map[int32]bool{}
+	for _, row := range m.nsgRules(false) {
+		other := orDash(row.rule.Name)
+		if strings.EqualFold(other, editing) {
+			continue
+		}
+		if strings.EqualFold(other, name) {
+			return fmt.Errorf("a rule named %s already exists", other)
+		}
+		if orDash(row.rule.Properties.Direction) != direction {
+			continue
+		}
+		used[int32OrZero(row.rule.Properties.Priority)] = true
+	}
+	if !used[priority] {
+		return nil
+	}
+	owner := ""
+	for _, row := range m.nsgRules(false) {
+		if orDash(row.rule.Properties.Direction) == direction && int32OrZero(row.rule.Properties.Priority) == priority && !strings.EqualFold(orDash(row.rule.Name), editing) {
+			owner = orDash(row.rule.Name)
+		}
+	}
+	for free := priority + 1; free <= maxRulePriority; free++ {
+		if !used[free] {
+			return fmt.Errorf("priority %d is already used by %s rule %s; the next free one is %d", priority, strings.ToLower(direction), owner, free)
+		}
+	}
+	return fmt.Errorf("priority %d is already used by %s rule %s", priority, strings.ToLower(direction), owner)
+}
+
+// nextRulePriority suggests a priority for a new rule: ten above the last
+// custom rule of the direction, or the lowest allowed one.
+func (m Model) nextRulePriority(direction string) int32 {
+	next := int32(minRulePriority)
+	for _, row := range m.nsgRules(false) {
+		if orDash(row.rule.Properties.Direction) == direction {
+			if priority := int32OrZero(row.rule.Properties.Priority) + 10; priority > next && priority <= maxRulePriority {
+				next = priority
+			}
+		}
+	}
+	return next
+}
+
+// selectedNSGRow returns the rule under the cursor.
+func (m Model) selectedNSGRow() (nsgRow, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.rowRules) {
+		return nsgRow{}, false
+	}
+	return m.rowRules[cursor], true
+}
+
+// handleNSGKey runs the rule actions of the NSG view. It reports whether
+// the key was an action.
+func (m *Model) handleNSGKey(key string) (tea.Cmd, bool) {
+	if m.nsg.Group.Name == nil {
+		return nil, false
+	}
+	nsgID, nsgName := m.nsgID, *m.nsg.Group.Name
+
+	switch key {
+	case "t":
+		m.nsgDefaults = !m.nsgDefaults
+		m.nsgSelect = ""
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return nil, true
+
+	case "a":
+		direction := string(armnetwork.SecurityRuleDirectionInbound)
+		if row, ok := m.selectedNSGRow(); ok {
+			direction = orDash(row.rule.Properties.Direction)
+		}
+		template := &armnetwork.SecurityRule{
+			Name: to.Ptr("new-rule"),
+			Properties: &armnetwork.SecurityRulePropertiesFormat{
+				Direction:                to.Ptr(armnetwork.SecurityRuleDirection(direction)),
+				Priority:                 to.Ptr(m.nextRulePriority(direction)),
+				Access:                   to.Ptr(armnetwork.SecurityRuleAccessAllow),
+				Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+				SourceAddressPrefix:      to.Ptr("*"),
+				SourcePortRange:          to.Ptr("*"),
+				DestinationAddressPrefix: to.Ptr("*"),
+				DestinationPortRange:     to.Ptr("443"),
+			},
+		}
+		m.askRule(fmt.Sprintf("Add rule to %s:", nsgName), formatRuleSpec(template), nil, nsgID, nsgName)
+		return nil, true
+	}
+
+	row, ok := m.selectedNSGRow()
+	if !ok || row.rule.Name == nil {
+		return nil, false
+	}
+	switch key {
+	case "e":
+		if row.isDefault {
+			m.setFlash("Default rules cannot be changed; override them with a custom rule of lower priority")
+			return nil, true
+		}
+		m.askRule(fmt.Sprintf("Edit rule %s:", *row.rule.Name), formatRuleSpec(row.rule), row.rule, nsgID, nsgName)
+		return nil, true
+
+	case "d":
+		if row.isDefault {
+			m.setFlash("Default rules cannot be deleted")
+			return nil, true
+		}
+		name := *row.rule.Name
+		m.askConfirm(fmt.Sprintf("Delete rule %s from %s?", name, nsgName), func(m *Model) tea.Cmd {
+			return m.startOperation(fmt.Sprintf("Delete rule %s from %s", name, nsgName), func(id int) tea.Cmd {
+				return azure.DeleteNSGRule(id, nsgID, name)
+			})
+		})
+		return nil, true
+	}
+	return nil, false
+}
+
+// askRule asks for a rule spec, checks it against the other rules and saves
+// it after confirmation. base is the rule being edited, or nil for a new one.
+func (m *Model) askRule(question, initial string, base *armnetwork.SecurityRule, nsgID, nsgName string) {
+	editing := ""
+	if base != nil {
+		editing = *base.Name
+	}
+	m.askValue(question, initial, func(m *Model, value string) tea.Cmd {
+		rule, err := parseRuleSpec(value, base)
+		if err != nil {
+			m.setFlash("invalid rule: " + err.Error())
+			return nil
+		}
+		if editing != "" && !strings.EqualFold(*rule.Name, editing) {
+			m.setFlash("Rules cannot be renamed; add a new rule and delete this one")
+			return nil
+		}
+		if err := m.ruleConflict(rule, editing); err != nil {
+			m.setFlash(err.Error())
+			return nil
+		}
+		verb := "Add"
+		if editing != "" {
+			verb = "Update"
+		}
+		summary := fmt.Sprintf("%s %s rule %s (priority %d)", strings.ToLower(orDash(rule.Properties.Access)), strings.ToLower(orDash(rule.Properties.Direction)), *rule.Name, *rule.Properties.Priority)
+		m.askConfirm(fmt.Sprintf("%s %s in %s?", verb, summary, nsgName), func(m *Model) tea.Cmd {
+			m.nsgSelect = *rule.Name
+			return m.startOperation(fmt.Sprintf("%s %s in %s", verb, summary, nsgName), func(id int) tea.Cmd {
+				return azure.PutNSGRule(id, nsgID, rule)
+			})
+		})
+		return nil
+	})
+}
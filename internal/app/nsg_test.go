@@ -0,0 +1,201 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testNSGID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/networkSecurityGroups/nsg-web"
+
+func newTestRule(name string, direction armnetwork.SecurityRuleDirection, priority int32, access armnetwork.SecurityRuleAccess, ports ...string) *armnetwork.SecurityRule {
+	rule := &armnetwork.SecurityRule{
+		Name: to.Ptr(name),
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Direction:                to.Ptr(direction),
+			Priority:                 to.Ptr(priority),
+			Access:                   to.Ptr(access),
+			Protocol:                 to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+			SourceAddressPrefix:      to.Ptr("*"),
+			SourcePortRange:          to.Ptr("*"),
+			DestinationAddressPrefix: to.Ptr("*"),
+		},
+	}
+	rule.Properties.DestinationPortRange, rule.Properties.DestinationPortRanges = splitList(strings.Join(ports, ","))
+	return rule
+}
+
+func newNSGModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testNSGID), Name: to.Ptr("nsg-web"), Type: to.Ptr("Microsoft.Network/networkSecurityGroups")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "nsg" || model.nsgID != testNSGID {
+		t.Fatalf("enter on NSG row opened %q (%s)", model.currentView, model.nsgID)
+	}
+
+	ssh := newTestRule("allow-ssh", armnetwork.SecurityRuleDirectionInbound, 300, armnetwork.SecurityRuleAccessAllow, "22")
+	ssh.Properties.SourceAddressPrefixes = []*string{to.Ptr("10.0.0.0/8"), to.Ptr("192.168.0.0/16")}
+	ssh.Properties.SourceAddressPrefix = nil
+	updated, _ := model.Update(azure.NSGMsg{
+		ID: testNSGID,
+		Group: armnetwork.SecurityGroup{
+			Name:     to.Ptr("nsg-web"),
+			Location: to.Ptr("westeurope"),
+			Properties: &armnetwork.SecurityGroupPropertiesFormat{
+				SecurityRules: []*armnetwork.SecurityRule{
+					newTestRule("deny-smtp", armnetwork.SecurityRuleDirectionOutbound, 100, armnetwork.SecurityRuleAccessDeny, "25"),
+					ssh,
+					newTestRule("allow-web", armnetwork.SecurityRuleDirectionInbound, 200, armnetwork.SecurityRuleAccessAllow, "80", "443"),
+				},
+				DefaultSecurityRules: []*armnetwork.SecurityRule{
+					newTestRule("DenyAllInBound", armnetwork.SecurityRuleDirectionInbound, 65500, armnetwork.SecurityRuleAccessDeny, "*"),
+				},
+				Subnets: []*armnetwork.Subnet{
+					{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/virtualNetworks/vnet-hub/subnets/web")},
+				},
+				NetworkInterfaces: []*armnetwork.Interface{
+					{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/networkInterfaces/vm1-nic")},
+				},
+			},
+		},
+	})
+	return updated.(Model)
+}
+
+func TestOpenNSG(t *testing.T) {
+	model := newNSGModel(t)
+
+	if got := propertyValue(t, model, "Subnets"); got != "vnet-hub/web" {
+		t.Errorf("Subnets = %q", got)
+	}
+	if got := propertyValue(t, model, "Network interfaces"); got != "vm1-nic" {
+		t.Errorf("Network interfaces = %q", got)
+	}
+
+	rows := model.table.Rows()
+	var names []string
+	for _, row := range rows {
+		names = append(names, row[2])
+	}
+	if got := strings.Join(names, " "); got != "allow-web allow-ssh deny-smtp" {
+		t.Fatalf("rules in order %q, want inbound by priority first", got)
+	}
+	if rows[0][7] != "80,443" || rows[1][4] != "10.0.0.0/8,192.168.0.0/16" || rows[2][8] != "Deny" {
+		t.Errorf("rows = %v", rows)
+	}
+
+	model, _ = pressKeys(model, runes("t"))
+	rows = model.table.Rows()
+	if len(rows) != 4 || rows[2][2] != "DenyAllInBound (default)" {
+		t.Errorf("rows with default rules = %v", rows)
+	}
+	model.table.SetCursor(2)
+	model, _ = pressKeys(model, runes("d"))
+	if model.prompt != nil || !strings.Contains(model.flash, "cannot be deleted") {
+		t.Errorf("deleting a default rule: prompt %+v, flash %q", model.prompt, model.flash)
+	}
+}
+
+func TestNSGReplyAfterEsc(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testNSGID), Name: to.Ptr("nsg-web"), Type: to.Ptr("Microsoft.Network/networkSecurityGroups")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEsc})
+
+	updated, _ := model.Update(azure.NSGMsg{ID: testNSGID, Group: armnetwork.SecurityGroup{Name: to.Ptr("nsg-web")}})
+	model = updated.(Model)
+	if model.currentView != "resources" || model.loading {
+		t.Errorf("late NSG reply left view %q loading=%v", model.currentView, model.loading)
+	}
+}
+
+func TestAddNSGRuleDetectsPriorityConflicts(t *testing.T) {
+	model := newNSGModel(t)
+
+	model, _ = pressKeys(model, runes("a"))
+	if model.prompt == nil || !strings.Contains(model.prompt.input.Value(), "direction=Inbound priority=310") {
+		t.Fatalf("add prompt = %+v, want the next inbound priority", model.prompt)
+	}
+
+	model.prompt.input.SetValue("name=allow-rdp direction=inbound priority=200 access=allow protocol=tcp source=* destination=* ports=3389")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if want := "priority 200 is already used by inbound rule allow-web; the next free one is 201"; model.flash != want {
+		t.Fatalf("flash = %q, want %q", model.flash, want)
+	}
+
+	model, _ = pressKeys(model, runes("a"))
+	model.prompt.input.SetValue("name=allow-web direction=outbound priority=200 access=allow source=* destination=* ports=80")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(model.flash, "a rule named allow-web already exists") {
+		t.Fatalf("flash = %q, want a name conflict", model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("a"))
+	model.prompt.input.SetValue("name=allow-rdp direction=inbound priority=200 access=allow source=* destination=* ports=3389 tos=1")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(model.flash, `unknown field "tos"`) {
+		t.Fatalf("flash = %q, want an unknown field", model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("a"))
+	model.prompt.input.SetValue("name=allow-rdp direction=outbound priority=200 access=allow protocol=tcp source=10.1.0.0/16 destination=* ports=3389")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || model.prompt.question != "Add allow outbound rule allow-rdp (priority 200) in nsg-web? (y/n)" {
+		t.Fatalf("expected confirmation, got %+v (flash %q)", model.prompt, model.flash)
+	}
+	model, cmd := pressKeys(model, runes("y"))
+	if cmd == nil || len(model.operations) != 1 || model.nsgSelect != "allow-rdp" {
+		t.Fatal("confirming the rule did not start an operation")
+	}
+
+	updated, cmd := model.Update(azure.OperationUpdateMsg{ID: 1, Status: "Succeeded", Done: true})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Error("finished operation did not refresh the NSG")
+	}
+}
+
+func TestEditNSGRuleKeepsUntouchedFields(t *testing.T) {
+	model := newNSGModel(t)
+	model.table.SetCursor(1)
+
+	model, _ = pressKeys(model, runes("e"))
+	want := "name=allow-ssh direction=Inbound priority=300 access=Allow protocol=Tcp source=10.0.0.0/8,192.168.0.0/16 sourcePorts=* destination=* ports=22"
+	if model.prompt == nil || model.prompt.input.Value() != want {
+		t.Fatalf("edit prompt = %q, want %q", model.prompt.input.Value(), want)
+	}
+
+	row, _ := model.selectedNSGRow()
+	row.rule.Properties.Description = to.Ptr("jump hosts")
+	rule, err := parseRuleSpec(strings.Replace(want, "ports=22", "ports=2222", 1), row.rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	props := rule.Properties
+	if *props.DestinationPortRange != "2222" || len(props.SourceAddressPrefixes) != 2 || *props.Description != "jump hosts" {
+		t.Errorf("edited rule = %+v", props)
+	}
+	if *row.rule.Properties.DestinationPortRange != "22" {
+		t.Error("editing changed the rule in the view")
+	}
+
+	model.prompt.input.SetValue(strings.Replace(want, "priority=300", "priority=200", 1))
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(model.flash, "already used by inbound rule allow-web") {
+		t.Errorf("flash = %q, want a priority conflict", model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("e"))
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || !strings.HasPrefix(model.prompt.question, "Update allow inbound rule allow-ssh (priority 300)") {
+		t.Errorf("saving the rule unchanged should not conflict with itself, got %+v (flash %q)", model.prompt, model.flash)
+	}
+}
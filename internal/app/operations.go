@@ -0,0 +1,136 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+// operation is a long-running Azure operation tracked in the background.
+type operation struct {
+	id          int
+	description string
+	status      string
+	started     time.Time
+	finished    time.Time
+	err         error
+}
+
+// startOperation registers a background operation and returns the command
+// that starts it.
+func (m *Model) startOperation(description string, start func(id int) tea.Cmd) tea.Cmd {
+	m.nextOperationID++
+	m.operations = append(m.operations, operation{
+		id:          m.nextOperationID,
+		description: description,
+		status:      "Starting",
+		started:     time.Now(),
+	})
+	m.setFlash(fmt.Sprintf("Started: %s (see :ops)", description))
+	return start(m.nextOperationID)
+}
+
+// runningOperations counts operations that have not finished yet.
+func (m Model) runningOperations() int {
+	running := 0
+	for _, op := range m.operations {
+		if op.finished.IsZero() {
+			running++
+		}
+	}
+	return running
+}
+
+// applyOperationUpdate records progress of a background operation and keeps
+// polling it until it is done.
+func (m *Model) applyOperationUpdate(msg azure.OperationUpdateMsg) tea.Cmd {
+	for i := range m.operations {
+		op := &m.operations[i]
+		if op.id != msg.ID {
+			continue
+		}
+		op.status = msg.Status
+		op.err = msg.Err
+		if msg.Done {
+			op.finished = time.Now()
+		}
+	}
+
+	if m.currentView == "operations" {
+		m.updateTableWithOperations()
+	}
+	if !msg.Done {
+		return msg.Next
+	}
+
+	// Show the outcome in the view the operation was started from.
+	switch {
+	case m.currentView == "aks" && m.aksID != "":
+		return azure.FetchAKSCluster(m.aksID)
+	case m.currentView == "vmss" && m.vmssID != "":
+		return azure.FetchVMSS(m.vmssID)
+	case m.currentView == "nsg" && m.nsgID != "":
+		return azure.FetchNSG(m.nsgID)
+	case m.currentView == "disks":
+		return m.reloadDisks()
+	}
+	return nil
+}
+
+func (m *Model) openOperations() {
+	m.viewStack = append(m.viewStack, m.currentView)
+	m.currentView = "operations"
+	m.properties = nil
+	m.updateLayout(m.width, m.height)
+}
+
+func (m *Model) updateTableWithOperations() {
+	m.table.SetRows([]table.Row{})
+
+	idWidth := int(float64(m.width) * 0.05)          // 5% of width
+	descriptionWidth := int(float64(m.width) * 0.45) // 45% of width
+	statusWidth := int(float64(m.width) * 0.3)       // 30% of width
+	startedWidth := int(float64(m.width) * 0.1)      // 10% of width
+	durationWidth := int(float64(m.width) * 0.1)     // 10% of width
+
+	columns := []table.Column{
+		{Title: "ID", Width: idWidth},
+		{Title: "Operation", Width: descriptionWidth},
+		{Title: "Status", Width: statusWidth},
+		{Title: "Started", Width: startedWidth},
+		{Title: "Duration", Width: durationWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	// Newest first
+	for i := len(m.operations) - 1; i >= 0; i-- {
+		op := m.operations[i]
+		status := op.status
+		if op.err != nil {
+			status = fmt.Sprintf("%s: %s", status, strings.SplitN(op.err.Error(), "\n", 2)[0])
+		}
+		end := op.finished
+		if end.IsZero() {
+			end = time.Now()
+		}
+		rows = append(rows, table.Row{
+			strconv.Itoa(op.id),
+			op.description,
+			status,
+			op.started.Format("15:04:05"),
+			end.Sub(op.started).Round(time.Second).String(),
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"-", "No operations started in this session", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
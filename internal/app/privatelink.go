@@ -0,0 +1,346 @@
+package app
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// openPrivateLink lists the private endpoints and private DNS zones of a
+// subscription or resource group, starting on the zones when zones is set.
+// The row named selectName, if any, is selected once loaded.
+func (m *Model) openPrivateLink(subscriptionID, resourceGroup, selectName string, zones bool) tea.Cmd {
+	if subscriptionID == "" {
+		m.setFlash(":privatelink needs a subscription: select one first")
+		return nil
+	}
+	m.openDetailView("privatelink")
+	m.plSub = subscriptionID
+	m.plRG = resourceGroup
+	m.plSelect = selectName
+	m.plZones = zones
+	m.dnsIssues = nil
+	return azure.FetchPrivateLink(subscriptionID, resourceGroup)
+}
+
+func (m *Model) applyPrivateLink(msg azure.PrivateLinkMsg) {
+	if m.currentView != "privatelink" || msg.SubscriptionID != m.plSub || msg.ResourceGroup != m.plRG {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.privateLink = msg
+	m.dnsIssues = map[string][]azure.DNSIssue{}
+	for _, issue := range azure.CheckPrivateDNS(msg.Endpoints, msg.Zones) {
+		key := strings.ToLower(issue.Endpoint)
+		m.dnsIssues[key] = append(m.dnsIssues[key], issue)
+	}
+	m.table.SetCursor(0)
+	m.updateLayout(m.width, m.height)
+}
+
+func (m *Model) updateTableWithPrivateLink() {
+	scope := "subscription " + m.subscriptionName(m.plSub)
+	if m.plRG != "" {
+		scope = "resource group " + m.plRG
+	}
+	issues := 0
+	for _, list := range m.dnsIssues {
+		issues += len(list)
+	}
+	showing := "private endpoints (tab: DNS zones)"
+	if m.plZones {
+		showing = "private DNS zones (tab: endpoints, enter: records)"
+	}
+	m.properties = []ui.Property{
+		{Key: "Scope", Value: scope},
+		{Key: "Private endpoints", Value: strconv.Itoa(len(m.privateLink.Endpoints))},
+		{Key: "Private DNS zones", Value: strconv.Itoa(len(m.privateLink.Zones))},
+		{Key: "DNS issues", Value: strconv.Itoa(issues)},
+		{Key: "Showing", Value: showing},
+	}
+	if m.plZones {
+		m.updateTableWithPrivateZones()
+		return
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.18)   // 18% of width
+	targetWidth := int(float64(m.width) * 0.22) // 22% of width
+	stateWidth := int(float64(m.width) * 0.1)   // 10% of width
+	ipWidth := int(float64(m.width) * 0.12)     // 12% of width
+	dnsWidth := int(float64(m.width) * 0.38)    // 38% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Target", Width: targetWidth},
+		{Title: "Connection", Width: stateWidth},
+		{Title: "IP Address", Width: ipWidth},
+		{Title: "DNS", Width: dnsWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, entry := range m.privateLink.Endpoints {
+		endpoint := entry.Endpoint
+		target, state := "-", "-"
+		if props := endpoint.Properties; props != nil {
+			for _, connection := range append(append([]*armnetwork.PrivateLinkServiceConnection{}, props.PrivateLinkServiceConnections...), props.ManualPrivateLinkServiceConnections...) {
+				if connection == nil || connection.Properties == nil {
+					continue
+				}
+				if connection.Properties.PrivateLinkServiceID != nil {
+					target = resourceName(*connection.Properties.PrivateLinkServiceID)
+					if groups := joinOrDash(connection.Properties.GroupIDs); groups != "-" {
+						target += " (" + groups + ")"
+					}
+				}
+				if connection.Properties.PrivateLinkServiceConnectionState != nil {
+					state = orDash(connection.Properties.PrivateLinkServiceConnectionState.Status)
+				}
+				break
+			}
+		}
+		var addresses []string
+		for _, name := range entry.Names {
+			if name.IP != "" && !slices.Contains(addresses, name.IP) {
+				addresses = append(addresses, name.IP)
+			}
+		}
+		address := "-"
+		if len(addresses) > 0 {
+			address = strings.Join(addresses, ", ")
+		}
+		dns := "ok"
+		if len(entry.Names) == 0 {
+			dns = "-"
+		}
+		if endpoint.ID != nil {
+			if list := m.dnsIssues[strings.ToLower(*endpoint.ID)]; len(list) > 0 {
+				problems := make([]string, 0, len(list))
+				for _, issue := range list {
+					if issue.FQDN == "" {
+						problems = append(problems, issue.Problem)
+						continue
+					}
+					problems = append(problems, issue.FQDN+": "+issue.Problem)
+				}
+				dns = strings.Join(problems, "; ")
+			}
+		}
+		rows = append(rows, table.Row{orDash(endpoint.Name), target, state, address, dns})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No private endpoints", "-", "-", "-", "-"})
+	}
+	m.setPrivateLinkRows(rows, cursor)
+}
+
+func (m *Model) updateTableWithPrivateZones() {
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.32)   // 32% of width
+	recordsWidth := int(float64(m.width) * 0.1) // 10% of width
+	linksWidth := int(float64(m.width) * 0.4)   // 40% of width
+	groupWidth := int(float64(m.width) * 0.18)  // 18% of width
+
+	columns := []table.Column{
+		{Title: "Zone", Width: nameWidth},
+		{Title: "Record Sets", Width: recordsWidth},
+		{Title: "Linked VNets", Width: linksWidth},
+		{Title: "Resource Group", Width: groupWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, zone := range m.privateLink.Zones {
+		group := "-"
+		if zone.Zone.ID != nil {
+			if id, err := arm.ParseResourceID(*zone.Zone.ID); err == nil {
+				group = id.ResourceGroupName
+			}
+		}
+		rows = append(rows, table.Row{orDash(zone.Zone.Name), strconv.Itoa(len(zone.Records)), zoneLinks(zone.Links), group})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No private DNS zones", "-", "-", "-"})
+	}
+	m.setPrivateLinkRows(rows, cursor)
+}
+
+// setPrivateLinkRows shows rows, keeping the cursor, or on the row named
+// plSelect once after loading.
+func (m *Model) setPrivateLinkRows(rows []table.Row, cursor int) {
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+	if m.plSelect != "" {
+		for i, row := range rows {
+			if strings.EqualFold(row[0], m.plSelect) {
+				m.table.SetCursor(i)
+			}
+		}
+		m.plSelect = ""
+	}
+}
+
+// zoneLinks names the virtual networks linked to a zone, marking those
+// that register their VMs' names in it.
+func zoneLinks(links []*armprivatedns.VirtualNetworkLink) string {
+	var names []string
+	for _, link := range links {
+		if link == nil || link.Properties == nil || link.Properties.VirtualNetwork == nil || link.Properties.VirtualNetwork.ID == nil {
+			continue
+		}
+		name := resourceName(*link.Properties.VirtualNetwork.ID)
+		if link.Properties.RegistrationEnabled != nil && *link.Properties.RegistrationEnabled {
+			name += " (auto-registration)"
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ", ")
+}
+
+// handlePrivateLinkKey switches between endpoints and zones on tab and
+// opens the record sets of a zone on enter.
+func (m *Model) handlePrivateLinkKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "tab":
+		m.plZones = !m.plZones
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return nil, true
+	case "enter":
+		if !m.plZones {
+			return nil, false
+		}
+		cursor := m.table.Cursor()
+		if cursor < 0 || cursor >= len(m.privateLink.Zones) {
+			return nil, true
+		}
+		m.dnsZone = m.privateLink.Zones[cursor]
+		m.plSelect = orDash(m.dnsZone.Zone.Name) // Back on this zone after esc
+		m.viewStack = append(m.viewStack, m.currentView)
+		m.currentView = "dnszone"
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return nil, true
+	}
+	return nil, false
+}
+
+func (m *Model) updateTableWithDNSZone() {
+	zone := m.dnsZone
+	m.properties = []ui.Property{
+		{Key: "Zone", Value: orDash(zone.Zone.Name)},
+		{Key: "Record sets", Value: strconv.Itoa(len(zone.Records))},
+		{Key: "Linked VNets", Value: zoneLinks(zone.Links)},
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.3)   // 30% of width
+	typeWidth := int(float64(m.width) * 0.08)  // 8% of width
+	ttlWidth := int(float64(m.width) * 0.08)   // 8% of width
+	valueWidth := int(float64(m.width) * 0.54) // 54% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Type", Width: typeWidth},
+		{Title: "TTL", Width: ttlWidth},
+		{Title: "Value", Width: valueWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, set := range zone.Records {
+		if set == nil {
+			continue
+		}
+		recordType := orDash(set.Type)
+		if i := strings.LastIndex(recordType, "/"); i >= 0 {
+			recordType = recordType[i+1:]
+		}
+		ttl, value := "-", "-"
+		if props := set.Properties; props != nil {
+			ttl = intOrDash(props.TTL)
+			value = recordValue(props)
+			if props.IsAutoRegistered != nil && *props.IsAutoRegistered {
+				value += " (auto-registered)"
+			}
+		}
+		rows = append(rows, table.Row{orDash(set.Name), recordType, ttl, value})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No record sets", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+}
+
+// recordValue formats the data of a record set of any type.
+func recordValue(props *armprivatedns.RecordSetProperties) string {
+	var values []string
+	for _, a := range props.ARecords {
+		if a != nil && a.IPv4Address != nil {
+			values = append(values, *a.IPv4Address)
+		}
+	}
+	for _, aaaa := range props.AaaaRecords {
+		if aaaa != nil && aaaa.IPv6Address != nil {
+			values = append(values, *aaaa.IPv6Address)
+		}
+	}
+	if props.CnameRecord != nil && props.CnameRecord.Cname != nil {
+		values = append(values, *props.CnameRecord.Cname)
+	}
+	for _, mx := range props.MxRecords {
+		if mx != nil {
+			values = append(values, fmt.Sprintf("%s %s", intOrDash(mx.Preference), orDash(mx.Exchange)))
+		}
+	}
+	for _, ptr := range props.PtrRecords {
+		if ptr != nil && ptr.Ptrdname != nil {
+			values = append(values, *ptr.Ptrdname)
+		}
+	}
+	for _, srv := range props.SrvRecords {
+		if srv != nil {
+			values = append(values, fmt.Sprintf("%s %s %s %s", intOrDash(srv.Priority), intOrDash(srv.Weight), intOrDash(srv.Port), orDash(srv.Target)))
+		}
+	}
+	for _, txt := range props.TxtRecords {
+		if txt != nil {
+			values = append(values, joinOrDash(txt.Value))
+		}
+	}
+	if props.SoaRecord != nil {
+		values = append(values, orDash(props.SoaRecord.Host)+" "+orDash(props.SoaRecord.Email))
+	}
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ", ")
+}
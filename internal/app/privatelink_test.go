@@ -0,0 +1,125 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func newTestPrivateEndpoint(name, target, group, fqdn, address string) azure.PrivateEndpoint {
+	return azure.PrivateEndpoint{
+		Endpoint: &armnetwork.PrivateEndpoint{
+			ID:   to.Ptr(testNetworkRG + "Microsoft.Network/privateEndpoints/" + name),
+			Name: to.Ptr(name),
+			Properties: &armnetwork.PrivateEndpointProperties{
+				PrivateLinkServiceConnections: []*armnetwork.PrivateLinkServiceConnection{{
+					Properties: &armnetwork.PrivateLinkServiceConnectionProperties{
+						PrivateLinkServiceID:              to.Ptr(target),
+						GroupIDs:                          []*string{to.Ptr(group)},
+						PrivateLinkServiceConnectionState: &armnetwork.PrivateLinkServiceConnectionState{Status: to.Ptr("Approved")},
+					},
+				}},
+			},
+		},
+		VNet:  testVNetID,
+		Names: []azure.PrivateName{{FQDN: fqdn, IP: address}},
+	}
+}
+
+func TestPrivateLinkView(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testNetworkRG + "Microsoft.Network/privateEndpoints/pe-vault"), Name: to.Ptr("pe-vault"), Type: to.Ptr("Microsoft.Network/privateEndpoints")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "privatelink" || model.plSub != "sub-1" || model.plRG != "rg-a" || model.plZones {
+		t.Fatalf("enter on private endpoint row opened %q for %s/%s", model.currentView, model.plSub, model.plRG)
+	}
+
+	unreadable := newTestPrivateEndpoint("pe-sql", testNetworkRG+"Microsoft.Sql/servers/sql-app", "sqlServer", "", "")
+	unreadable.Names, unreadable.Err = nil, errors.New("AuthorizationFailed")
+	updated, _ := model.Update(azure.PrivateLinkMsg{
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-a",
+		Endpoints: []azure.PrivateEndpoint{
+			newTestPrivateEndpoint("pe-storage", testStorageID, "blob", "mystorage.blob.core.windows.net", "10.0.5.4"),
+			newTestPrivateEndpoint("pe-vault", testNetworkRG+"Microsoft.KeyVault/vaults/kv-app", "vault", "kv-app.vault.azure.net", "10.0.5.5"),
+			unreadable,
+		},
+		Zones: []azure.PrivateZone{
+			{
+				Zone: &armprivatedns.PrivateZone{ID: to.Ptr(testNetworkRG + "Microsoft.Network/privateDnsZones/privatelink.blob.core.windows.net"), Name: to.Ptr("privatelink.blob.core.windows.net")},
+				Records: []*armprivatedns.RecordSet{
+					{
+						Name:       to.Ptr("mystorage"),
+						Type:       to.Ptr("Microsoft.Network/privateDnsZones/A"),
+						Properties: &armprivatedns.RecordSetProperties{TTL: to.Ptr[int64](10), ARecords: []*armprivatedns.ARecord{{IPv4Address: to.Ptr("10.0.5.4")}}},
+					},
+					{
+						Name:       to.Ptr("@"),
+						Type:       to.Ptr("Microsoft.Network/privateDnsZones/SOA"),
+						Properties: &armprivatedns.RecordSetProperties{TTL: to.Ptr[int64](3600), SoaRecord: &armprivatedns.SoaRecord{Host: to.Ptr("azureprivatedns.net"), Email: to.Ptr("azureprivatedns-host.microsoft.com")}},
+					},
+				},
+				Links: []*armprivatedns.VirtualNetworkLink{{
+					Properties: &armprivatedns.VirtualNetworkLinkProperties{VirtualNetwork: &armprivatedns.SubResource{ID: to.Ptr(testVNetID)}, RegistrationEnabled: to.Ptr(false)},
+				}},
+			},
+			{
+				Zone: &armprivatedns.PrivateZone{ID: to.Ptr(testNetworkRG + "Microsoft.Network/privateDnsZones/privatelink.vaultcore.azure.net"), Name: to.Ptr("privatelink.vaultcore.azure.net")},
+				Links: []*armprivatedns.VirtualNetworkLink{{
+					Properties: &armprivatedns.VirtualNetworkLinkProperties{VirtualNetwork: &armprivatedns.SubResource{ID: to.Ptr(testVNetID)}, RegistrationEnabled: to.Ptr(true)},
+				}},
+			},
+		},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "DNS issues"); got != "2" {
+		t.Errorf("DNS issues = %q", got)
+	}
+	rows := model.table.Rows()
+	if len(rows) != 3 || model.table.Cursor() != 1 {
+		t.Fatalf("rows = %v, cursor %d; want pe-vault selected", rows, model.table.Cursor())
+	}
+	if rows[0][1] != "st1 (blob)" || rows[0][2] != "Approved" || rows[0][3] != "10.0.5.4" || rows[0][4] != "ok" {
+		t.Errorf("pe-storage row = %v", rows[0])
+	}
+	if rows[1][4] != "kv-app.vault.azure.net: no private DNS zone for it in scope" {
+		t.Errorf("pe-vault DNS = %q", rows[1][4])
+	}
+	if rows[2][4] != "network interface could not be read: AuthorizationFailed" {
+		t.Errorf("pe-sql DNS = %q", rows[2][4])
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyTab})
+	rows = model.table.Rows()
+	if len(rows) != 2 || rows[0][1] != "2" || rows[0][2] != "vnet-hub" || rows[1][2] != "vnet-hub (auto-registration)" {
+		t.Fatalf("zone rows = %v", rows)
+	}
+
+	model, _ = pressKeys(model, runes("j"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.currentView != "dnszone" || propertyValue(t, model, "Zone") != "privatelink.vaultcore.azure.net" {
+		t.Fatalf("enter on the second zone opened %q", model.currentView)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc}, runes("k"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.currentView != "dnszone" || propertyValue(t, model, "Zone") != "privatelink.blob.core.windows.net" {
+		t.Fatalf("back and up opened %q", model.currentView)
+	}
+	rows = model.table.Rows()
+	if len(rows) != 2 || rows[0][0] != "mystorage" || rows[0][1] != "A" || rows[0][2] != "10" || rows[0][3] != "10.0.5.4" || rows[1][1] != "SOA" {
+		t.Errorf("record rows = %v", rows)
+	}
+}
+
+func TestPrivateLinkCommand(t *testing.T) {
+	model, cmd := typeCommand(t, newResourcesModel(), "privatelink")
+	if cmd == nil || model.currentView != "privatelink" || model.plSub != "sub-1" || model.plRG != "rg-a" {
+		t.Fatalf(":privatelink opened %q for %s/%s", model.currentView, model.plSub, model.plRG)
+	}
+}
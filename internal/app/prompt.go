@@ -0,0 +1,122 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prompt asks for a value, a yes/no confirmation or a choice between actions.
+type prompt struct {
+	question string
+	input    textinput.Model
+	confirm  bool
+	choices  []choice
+	submit   func(m *Model, value string) tea.Cmd
+}
+
+// choice is one answer to a prompt opened with askChoice.
+type choice struct {
+	key   string
+	label string
+	run   func(m *Model) tea.Cmd
+}
+
+// askValue prompts for a line of text and passes it to submit.
+func (m *Model) askValue(question, initial string, submit func(m *Model, value string) tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = ""
+	input.SetValue(initial)
+	input.Focus()
+
+	m.prompt = &prompt{question: question, input: input, submit: submit}
+	m.updateLayout(m.width, m.height)
+}
+
+// askConfirm asks a yes/no question and calls submit only on yes.
+func (m *Model) askConfirm(question string, submit func(m *Model) tea.Cmd) {
+	m.prompt = &prompt{
+		question: question + " (y/n)",
+		confirm:  true,
+		submit:   func(m *Model, _ string) tea.Cmd { return submit(m) },
+	}
+	m.updateLayout(m.width, m.height)
+}
+
+// askChoice offers a set of single-key answers; esc picks none of them.
+func (m *Model) askChoice(question string, choices []choice) {
+	m.prompt = &prompt{question: question, choices: choices}
+	m.updateLayout(m.width, m.height)
+}
+
+// updatePrompt handles keys while a prompt is open.
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := m.prompt
+
+	if len(p.choices) > 0 {
+		if msg.Type == tea.KeyEsc {
+			m.prompt = nil
+			m.updateLayout(m.width, m.height)
+			return m, nil
+		}
+		for _, c := range p.choices {
+			if msg.String() == c.key {
+				m.prompt = nil
+				m.updateLayout(m.width, m.height)
+				return m, c.run(&m)
+			}
+		}
+		return m, nil
+	}
+
+	if p.confirm {
+		switch msg.String() {
+		case "y", "Y":
+			m.prompt = nil
+			m.updateLayout(m.width, m.height)
+			return m, p.submit(&m, "")
+		case "n", "N", "esc":
+			m.prompt = nil
+			m.updateLayout(m.width, m.height)
+		}
+		return m, nil
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		m.prompt = nil
+		m.updateLayout(m.width, m.height)
+		return m, nil
+	case tea.KeyEnter:
+		m.prompt = nil
+		m.updateLayout(m.width, m.height)
+		return m, p.submit(&m, p.input.Value())
+	}
+
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	return m, cmd
+}
+
+// setFlash shows a one-off message above the footer until the next key press.
+func (m *Model) setFlash(message string) {
+	m.flash = message
+	m.updateLayout(m.width, m.height)
+}
+
+// View renders the open prompt.
+func (p *prompt) View() string {
+	if p.confirm {
+		return p.question
+	}
+	if len(p.choices) > 0 {
+		answers := make([]string, 0, len(p.choices)+1)
+		for _, c := range p.choices {
+			answers = append(answers, c.key+": "+c.label)
+		}
+		answers = append(answers, "esc: cancel")
+		return p.question + " (" + strings.Join(answers, ", ") + ")"
+	}
+	return p.question + " " + p.input.View()
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// openPublicIPs lists the public IPs of a subscription or resource group.
+// The row named selectName, if any, is selected once loaded.
+func (m *Model) openPublicIPs(subscriptionID, resourceGroup, selectName string) tea.Cmd {
+	if subscriptionID == "" {
+		m.setFlash(":pips needs a subscription: select one first")
+		return nil
+	}
+	m.openDetailView("pips")
+	m.pipsSub = subscriptionID
+	m.pipsRG = resourceGroup
+	m.pipsSelect = selectName
+	m.pipsReport = false
+	m.exposure = nil
+	return azure.FetchPublicIPs(subscriptionID, resourceGroup)
+}
+
+// publicIPAttachment names what a public IP is bound to, or "unattached".
+func publicIPAttachment(pip *armnetwork.PublicIPAddress) string {
+	props := pip.Properties
+	if props == nil {
+		return "unattached"
+	}
+	if props.IPConfiguration != nil && props.IPConfiguration.ID != nil {
+		if _, label, ok := attachedResource(*props.IPConfiguration.ID); ok {
+			return label
+		}
+	}
+	if props.NatGateway != nil && props.NatGateway.ID != nil {
+		if _, label, ok := attachedResource(*props.NatGateway.ID); ok {
+			return label
+		}
+	}
+	return "unattached"
+}
+
+func (m *Model) updateTableWithPublicIPs() {
+	scope := "subscription " + m.subscriptionName(m.pipsSub)
+	if m.pipsRG != "" {
+		scope = "resource group " + m.pipsRG
+	}
+	unattached := 0
+	for _, pip := range m.pips.PublicIPs {
+		if pip != nil && publicIPAttachment(pip) == "unattached" {
+			unattached++
+		}
+	}
+	ports := make([]string, 0, len(azure.ManagementPorts))
+	for _, port := range azure.ManagementPorts {
+		ports = append(ports, strconv.Itoa(port))
+	}
+	m.properties = []ui.Property{
+		{Key: "Scope", Value: scope},
+		{Key: "Public IPs", Value: fmt.Sprintf("%d (%d unattached)", len(m.pips.PublicIPs), unattached)},
+	}
+	if m.pipsReport && m.exposure != nil {
+		exposed, unchecked := 0, 0
+		for _, exposure := range m.exposure.Exposures {
+			if exposure.Err != nil {
+				unchecked++
+			} else {
+				exposed++
+			}
+		}
+		m.properties = append(m.properties,
+			ui.Property{Key: "Showing", Value: "exposure report: management ports (" + strings.Join(ports, ", ") + ") open to 0.0.0.0/0"},
+			ui.Property{Key: "Exposed", Value: strconv.Itoa(exposed)},
+		)
+		if unchecked > 0 {
+			m.properties = append(m.properties, ui.Property{Key: "Not checked", Value: styles.ErrorStyle.Render(strconv.Itoa(unchecked) + " (their interface or NSGs could not be read)")})
+		}
+		m.updateTableWithExposure()
+		return
+	}
+	m.properties = append(m.properties, ui.Property{Key: "Showing", Value: "inventory (x: exposure report)"})
+
+	cursor := m.table.Cursor()
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.2)        // 20% of width
+	addressWidth := int(float64(m.width) * 0.13)    // 13% of width
+	skuWidth := int(float64(m.width) * 0.09)        // 9% of width
+	allocationWidth := int(float64(m.width) * 0.09) // 9% of width
+	dnsWidth := int(float64(m.width) * 0.24)        // 24% of width
+	attachedWidth := int(float64(m.width) * 0.25)   // 25% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "IP Address", Width: addressWidth},
+		{Title: "SKU", Width: skuWidth},
+		{Title: "Allocation", Width: allocationWidth},
+		{Title: "DNS Name", Width: dnsWidth},
+		{Title: "Attached To", Width: attachedWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, pip := range m.pips.PublicIPs {
+		if pip == nil {
+			continue
+		}
+		sku, address, allocation, dns := "-", "-", "-", "-"
+		if pip.SKU != nil {
+			sku = orDash(pip.SKU.Name)
+		}
+		if props := pip.Properties; props != nil {
+			address = orDash(props.IPAddress)
+			allocation = orDash(props.PublicIPAllocationMethod)
+			if props.DNSSettings != nil {
+				dns = orDash(props.DNSSettings.Fqdn)
+			}
+		}
+		rows = append(rows, table.Row{orDash(pip.Name), address, sku, allocation, dns, publicIPAttachment(pip)})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No public IPs", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	if cursor < 0 || cursor >= len(rows) {
+		cursor = 0
+	}
+	m.table.SetCursor(cursor)
+	if m.pipsSelect != "" {
+		for i, row := range rows {
+			if strings.EqualFold(row[0], m.pipsSelect) {
+				m.table.SetCursor(i)
+			}
+		}
+	}
+}
+
+func (m *Model) updateTableWithExposure() {
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.18)    // 18% of width
+	addressWidth := int(float64(m.width) * 0.12) // 12% of width
+	nicWidth := int(float64(m.width) * 0.17)     // 17% of width
+	vmWidth := int(float64(m.width) * 0.13)      // 13% of width
+	portsWidth := int(float64(m.width) * 0.13)   // 13% of width
+	allowedWidth := int(float64(m.width) * 0.27) // 27% of width
+
+	columns := []table.Column{
+		{Title: "Public IP", Width: nameWidth},
+		{Title: "IP Address", Width: addressWidth},
+		{Title: "NIC", Width: nicWidth},
+		{Title: "VM", Width: vmWidth},
+		{Title: "Open Ports", Width: portsWidth},
+		{Title: "Allowed By", Width: allowedWidth},
+	}
+	m.table.SetColumns(columns)
+
+	addresses := map[string]string{}
+	for _, pip := range m.pips.PublicIPs {
+		if pip != nil && pip.ID != nil && pip.Properties != nil {
+			addresses[strings.ToLower(*pip.ID)] = orDash(pip.Properties.IPAddress)
+		}
+	}
+	var rows []table.Row
+	for _, exposure := range m.exposure.Exposures {
+		vm := "-"
+		if exposure.VM != "" {
+			vm = resourceName(exposure.VM)
+		}
+		ports := make([]string, 0, len(exposure.Ports))
+		for _, port := range exposure.Ports {
+			ports = append(ports, strconv.Itoa(port))
+		}
+		address, ok := addresses[strings.ToLower(exposure.PublicIP)]
+		if !ok {
+			address = "-"
+		}
+		open, allowedBy := strings.Join(ports, ", "), strings.Join(exposure.AllowedBy, ", ")
+		if exposure.Err != nil {
+			open, allowedBy = "unknown", "failed: "+exposure.Err.Error()
+		}
+		rows = append(rows, table.Row{
+			resourceName(exposure.PublicIP),
+			address,
+			resourceName(exposure.NIC),
+			vm,
+			open,
+			allowedBy,
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No exposed public IPs", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// handlePublicIPsKey toggles the exposure report, computing it the first
+// time.
+func (m *Model) handlePublicIPsKey(key string) (tea.Cmd, bool) {
+	if key != "x" {
+		return nil, false
+	}
+	m.pipsReport = !m.pipsReport
+	m.table.SetCursor(0)
+	if m.pipsReport && m.exposure == nil {
+		m.loading = true
+		return azure.FetchExposure(m.pipsSub, m.pipsRG, m.pips.PublicIPs), true
+	}
+	m.updateLayout(m.width, m.height)
+	return nil, true
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testPublicIPs = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/"
+
+func newTestPublicIP(name, address string, sku armnetwork.PublicIPAddressSKUName, configID string) *armnetwork.PublicIPAddress {
+	pip := &armnetwork.PublicIPAddress{
+		ID:   to.Ptr(testPublicIPs + "publicIPAddresses/" + name),
+		Name: to.Ptr(name),
+		SKU:  &armnetwork.PublicIPAddressSKU{Name: to.Ptr(sku)},
+		Properties: &armnetwork.PublicIPAddressPropertiesFormat{
+			IPAddress:                to.Ptr(address),
+			PublicIPAllocationMethod: to.Ptr(armnetwork.IPAllocationMethodStatic),
+		},
+	}
+	if configID != "" {
+		pip.Properties.IPConfiguration = &armnetwork.IPConfiguration{ID: to.Ptr(configID)}
+	}
+	return pip
+}
+
+func TestPublicIPInventoryAndExposure(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testPublicIPs + "publicIPAddresses/pip-lb"), Name: to.Ptr("pip-lb"), Type: to.Ptr("Microsoft.Network/publicIPAddresses")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "pips" || model.pipsSub != "sub-1" || model.pipsRG != "rg-a" {
+		t.Fatalf("enter on public IP row opened %q for %s/%s", model.currentView, model.pipsSub, model.pipsRG)
+	}
+
+	vmPIP := newTestPublicIP("pip-vm1", "20.1.2.3", armnetwork.PublicIPAddressSKUNameStandard, testPublicIPs+"networkInterfaces/vm1-nic/ipConfigurations/ipconfig1")
+	vmPIP.Properties.DNSSettings = &armnetwork.PublicIPAddressDNSSettings{Fqdn: to.Ptr("vm1.westeurope.cloudapp.azure.com")}
+	updated, _ := model.Update(azure.PublicIPsMsg{
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-a",
+		PublicIPs: []*armnetwork.PublicIPAddress{
+			vmPIP,
+			newTestPublicIP("pip-lb", "20.1.2.4", armnetwork.PublicIPAddressSKUNameStandard, testPublicIPs+"loadBalancers/lb-web/frontendIPConfigurations/fe"),
+			newTestPublicIP("pip-old", "", armnetwork.PublicIPAddressSKUNameBasic, ""),
+		},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Public IPs"); got != "3 (1 unattached)" {
+		t.Errorf("Public IPs = %q", got)
+	}
+	rows := model.table.Rows()
+	if len(rows) != 3 || model.table.Cursor() != 1 {
+		t.Fatalf("rows = %v, cursor %d; want pip-lb selected", rows, model.table.Cursor())
+	}
+	for i, want := range []string{"vm1-nic (network interface)", "lb-web (load balancer)", "unattached"} {
+		if rows[i][5] != want {
+			t.Errorf("row %d attached to %q, want %q", i, rows[i][5], want)
+		}
+	}
+	if rows[0][4] != "vm1.westeurope.cloudapp.azure.com" || rows[2][1] != "-" || rows[2][2] != "Basic" {
+		t.Errorf("rows = %v", rows)
+	}
+
+	model, cmd = pressKeys(model, runes("x"))
+	if cmd == nil || !model.loading {
+		t.Fatal("x did not compute the exposure report")
+	}
+	updated, _ = model.Update(azure.ExposureMsg{
+		SubscriptionID: "sub-1",
+		ResourceGroup:  "rg-a",
+		Exposures: []azure.Exposure{{
+			PublicIP:  *vmPIP.ID,
+			NIC:       testPublicIPs + "networkInterfaces/vm1-nic",
+			VM:        testVMID,
+			Ports:     []int{22, 3389},
+			AllowedBy: []string{"nsg-vm/allow-mgmt"},
+		}, {
+			PublicIP: testPublicIPs + "publicIPAddresses/pip-lb",
+			NIC:      testPublicIPs + "networkInterfaces/lb-nic",
+			Err:      errors.New("AuthorizationFailed"),
+		}},
+	})
+	model = updated.(Model)
+	rows = model.table.Rows()
+	if len(rows) != 2 || rows[0][0] != "pip-vm1" || rows[0][1] != "20.1.2.3" || rows[0][3] != "vm1" || rows[0][4] != "22, 3389" || rows[0][5] != "nsg-vm/allow-mgmt" {
+		t.Errorf("report rows = %v", rows)
+	}
+	if rows[1][4] != "unknown" || !strings.Contains(rows[1][5], "AuthorizationFailed") {
+		t.Errorf("unreadable public IP row = %v", rows[1])
+	}
+	if propertyValue(t, model, "Exposed") != "1" || !strings.Contains(propertyValue(t, model, "Not checked"), "1") {
+		t.Errorf("exposed = %q, not checked = %q", propertyValue(t, model, "Exposed"), propertyValue(t, model, "Not checked"))
+	}
+
+	model, cmd = pressKeys(model, runes("x"))
+	if cmd != nil || len(model.table.Rows()) != 3 {
+		t.Error("x did not go back to the inventory")
+	}
+	if _, cmd = pressKeys(model, runes("x")); cmd != nil {
+		t.Error("the report was computed again instead of reused")
+	}
+}
+
+func TestPublicIPsCommand(t *testing.T) {
+	model, cmd := typeCommand(t, newResourcesModel(), "pips")
+	if cmd == nil || model.currentView != "pips" || model.pipsSub != "sub-1" || model.pipsRG != "rg-a" {
+		t.Fatalf(":pips opened %q for %s/%s", model.currentView, model.pipsSub, model.pipsRG)
+	}
+}
+
+func TestPublicIPRepliesAfterEsc(t *testing.T) {
+	model, _ := typeCommand(t, newResourcesModel(), "pips")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+
+	for _, msg := range []tea.Msg{
+		azure.PublicIPsMsg{SubscriptionID: "sub-1", ResourceGroup: "rg-a"},
+		azure.ExposureMsg{SubscriptionID: "sub-1", ResourceGroup: "rg-a"},
+	} {
+		updated, _ := model.Update(msg)
+		model = updated.(Model)
+		if model.currentView != "resources" || model.loading {
+			t.Errorf("late %T left view %q loading=%v", msg, model.currentView, model.loading)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// maxConcurrentRuns bounds how many VMs run a script at once; the other
+// targets wait for one of them to finish.
+const maxConcurrentRuns = 4
+
+// runTarget is a VM a script is sent to with :run, and its result once the
+// script has finished.
+type runTarget struct {
+	id      string
+	name    string
+	started bool
+	done    bool
+	result  azure.RunCommandResultMsg
+}
+
+// toggleMarkedVM marks or unmarks the VM under the cursor for :run. It
+// reports whether the row was a VM.
+func (m *Model) toggleMarkedVM() bool {
+	resource, ok := m.selectedVM()
+	if !ok {
+		return false
+	}
+	id := strings.ToLower(*resource.ID)
+	if _, marked := m.markedVMs[id]; marked {
+		delete(m.markedVMs, id)
+	} else {
+		m.markedVMs[id] = runTarget{id: *resource.ID, name: *resource.Name}
+	}
+
+	cursor := m.table.Cursor()
+	m.updateTableWithResources()
+	m.table.SetCursor(cursor)
+	return true
+}
+
+// resourceRowName is the name shown for a resource, prefixed when it is a VM
+// marked for :run.
+func (m Model) resourceRowName(name string, id *string) string {
+	if id != nil {
+		if _, marked := m.markedVMs[strings.ToLower(*id)]; marked {
+			return markedPrefix + name
+		}
+	}
+	return name
+}
+
+// runTargets returns the VMs :run applies to in the current view: the marked
+// VMs, or else the VM under the cursor or on screen.
+func (m Model) runTargets() []runTarget {
+	switch m.currentView {
+	case "resources":
+		if len(m.markedVMs) > 0 {
+			targets := make([]runTarget, 0, len(m.markedVMs))
+			for _, target := range m.markedVMs {
+				targets = append(targets, target)
+			}
+			sort.Slice(targets, func(i, j int) bool { return targets[i].name < targets[j].name })
+			return targets
+		}
+		if resource, ok := m.selectedVM(); ok {
+			return []runTarget{{id: *resource.ID, name: *resource.Name}}
+		}
+	case "vm":
+		if m.vm.VM.Name != nil {
+			return []runTarget{{id: m.vmID, name: *m.vm.VM.Name}}
+		}
+	}
+	return nil
+}
+
+// startRun implements ":run [file]": the script is read from file, or typed
+// into an editor when no file is given.
+func (m *Model) startRun(args []string) tea.Cmd {
+	targets := m.runTargets()
+	if len(targets) == 0 {
+		m.setFlash(":run needs a VM: select one, or mark several with space in the resources view")
+		return nil
+	}
+
+	if len(args) > 0 {
+		path := strings.Join(args, " ")
+		script, err := os.ReadFile(path)
+		if err != nil {
+			m.setFlash(err.Error())
+			return nil
+		}
+		m.openDetailView("runresults")
+		return m.runScript(targets, string(script))
+	}
+
+	m.openDetailView("runscript")
+	m.loading = false
+	m.runTargetList = targets
+	m.runEditor = textarea.New()
+	m.runEditor.CharLimit = 0
+	m.runEditor.MaxHeight = 0
+	m.runEditor.Placeholder = "Shell script on Linux, PowerShell on Windows"
+	m.runEditor.Focus()
+	m.properties = []ui.Property{{Key: "Run on", Value: targetNames(targets)}}
+	m.updateLayout(m.width, m.height)
+	return textarea.Blink
+}
+
+// updateRunEditor handles keys while a script is being typed.
+func (m Model) updateRunEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closeDetailView()
+		return m, nil
+	case "ctrl+s":
+		script := m.runEditor.Value()
+		if strings.TrimSpace(script) == "" {
+			m.setFlash("the script is empty")
+			return m, nil
+		}
+		// The results replace the editor, so esc returns to where :run was
+		// started.
+		m.currentView = "runresults"
+		return m, m.runScript(m.runTargetList, script)
+	}
+
+	var cmd tea.Cmd
+	m.runEditor, cmd = m.runEditor.Update(msg)
+	return m, cmd
+}
+
+// runScript sends script to the targets, at most maxConcurrentRuns at a
+// time, and shows their output as it arrives. The marks the targets came
+// from are cleared.
+func (m *Model) runScript(targets []runTarget, script string) tea.Cmd {
+	m.runID++
+	m.runScriptText = script
+	m.runTargetList = append([]runTarget(nil), targets...)
+	clear(m.markedVMs)
+	m.loading = false
+	m.runOutput = newTextPane()
+
+	cmds := make([]tea.Cmd, 0, maxConcurrentRuns)
+	for range maxConcurrentRuns {
+		if cmd := m.startNextRun(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
+	}
+	m.renderRunResults()
+	m.updateLayout(m.width, m.height)
+	return tea.Batch(cmds...)
+}
+
+// startNextRun sends the script to the first target still waiting, if any.
+func (m *Model) startNextRun() tea.Cmd {
+	for i := range m.runTargetList {
+		if !m.runTargetList[i].started {
+			m.runTargetList[i].started = true
+			return azure.RunCommandOnVM(m.runID, m.runTargetList[i].id, m.runScriptText)
+		}
+	}
+	return nil
+}
+
+// applyRunResult records the output of one VM of the current run and
+// starts the next waiting target in its place.
+func (m *Model) applyRunResult(msg azure.RunCommandResultMsg) tea.Cmd {
+	if msg.RunID != m.runID {
+		return nil
+	}
+	for i := range m.runTargetList {
+		if m.runTargetList[i].id == msg.VMID {
+			m.runTargetList[i].done = true
+			m.runTargetList[i].result = msg
+		}
+	}
+	cmd := m.startNextRun()
+	m.renderRunResults()
+	m.updateLayout(m.width, m.height)
+	return cmd
+}
+
+// renderRunResults writes every target's status and output into the results
+// pane, in the order the targets were given.
+func (m *Model) renderRunResults() {
+	finished := 0
+	var sb strings.Builder
+	for _, target := range m.runTargetList {
+		switch {
+		case !target.started:
+			fmt.Fprintf(&sb, "=== %s: queued\n\n", target.name)
+			continue
+		case !target.done:
+			fmt.Fprintf(&sb, "=== %s: running\n\n", target.name)
+			continue
+		case target.result.Err != nil:
+			fmt.Fprintf(&sb, "=== %s: failed\n%v\n\n", target.name, target.result.Err)
+		default:
+			fmt.Fprintf(&sb, "=== %s: finished\n", target.name)
+			if out := strings.TrimRight(target.result.Stdout, "\n"); out != "" {
+				fmt.Fprintf(&sb, "--- stdout\n%s\n", out)
+			}
+			if out := strings.TrimRight(target.result.Stderr, "\n"); out != "" {
+				fmt.Fprintf(&sb, "--- stderr\n%s\n", out)
+			}
+			sb.WriteString("\n")
+		}
+		finished++
+	}
+
+	m.runOutput.setText(sb.String())
+	m.properties = []ui.Property{
+		{Key: "Run on", Value: targetNames(m.runTargetList)},
+		{Key: "Finished", Value: fmt.Sprintf("%d of %d", finished, len(m.runTargetList))},
+	}
+}
+
+func targetNames(targets []runTarget) string {
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testVM2ID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/virtualMachines/vm2"
+
+func newFleetModel() Model {
+	return newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+		armresources.GenericResourceExpanded{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Storage/storageAccounts/st1"), Name: to.Ptr("st1"), Type: to.Ptr("Microsoft.Storage/storageAccounts")},
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVM2ID), Name: to.Ptr("vm2"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+}
+
+func TestMarkVMs(t *testing.T) {
+	model := newFleetModel()
+
+	model, _ = pressKeys(model, runes(" "))
+	model.table.SetCursor(2)
+	model, _ = pressKeys(model, runes(" "))
+
+	rows := model.table.Rows()
+	if rows[0][0] != markedPrefix+"vm1" || rows[1][0] != "st1" || rows[2][0] != markedPrefix+"vm2" {
+		t.Errorf("rows after marking = %v", rows)
+	}
+	if model.table.Cursor() != 2 {
+		t.Errorf("cursor moved to %d while marking", model.table.Cursor())
+	}
+
+	model.table.SetCursor(1)
+	model, _ = pressKeys(model, runes(" "))
+	if len(model.markedVMs) != 2 {
+		t.Errorf("space on a storage account changed the marks: %v", model.markedVMs)
+	}
+}
+
+func TestRunScriptOnMarkedVMs(t *testing.T) {
+	model := newFleetModel()
+	model, _ = pressKeys(model, runes(" "))
+	model.table.SetCursor(2)
+	model, _ = pressKeys(model, runes(" "))
+
+	model, _ = typeCommand(t, model, "run")
+	if model.currentView != "runscript" {
+		t.Fatalf(":run opened %q, want the script editor", model.currentView)
+	}
+	if got := propertyValue(t, model, "Run on"); got != "vm1, vm2" {
+		t.Errorf("Run on = %q, want both marked VMs", got)
+	}
+
+	model, _ = pressKeys(model, runes("q"))
+	if model.currentView != "runscript" || model.runEditor.Value() != "q" {
+		t.Fatalf("q in the editor was not typed into the script")
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyBackspace}, runes("uptime"))
+
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyCtrlS})
+	if cmd == nil || model.currentView != "runresults" {
+		t.Fatalf("ctrl+s switched to %q", model.currentView)
+	}
+	runID := model.runID
+
+	updated, _ := model.Update(azure.RunCommandResultMsg{RunID: runID, VMID: testVM2ID, Stdout: " 10:00:01 up 3 days\n", Stderr: "warning\n"})
+	model = updated.(Model)
+	updated, _ = model.Update(azure.RunCommandResultMsg{RunID: runID - 1, VMID: testVMID, Stdout: "stale"})
+	model = updated.(Model)
+
+	output := strings.Join(model.runOutput.allLines(), "\n")
+	for _, want := range []string{"=== vm1: running", "=== vm2: finished", "--- stdout\n 10:00:01 up 3 days", "--- stderr\nwarning"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output does not contain %q:\n%s", want, output)
+		}
+	}
+	if strings.Contains(output, "stale") {
+		t.Error("output of an earlier run was shown")
+	}
+
+	updated, _ = model.Update(azure.RunCommandResultMsg{RunID: runID, VMID: testVMID, Err: errors.New("VMAgentStatusCommunicationError")})
+	model = updated.(Model)
+	if got := propertyValue(t, model, "Finished"); got != "2 of 2" {
+		t.Errorf("Finished = %q, want 2 of 2", got)
+	}
+	if output := strings.Join(model.runOutput.allLines(), "\n"); !strings.Contains(output, "=== vm1: failed\nVMAgentStatusCommunicationError") {
+		t.Errorf("output does not show the failure:\n%s", output)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resources" {
+		t.Errorf("esc from the results went to %q, want resources", model.currentView)
+	}
+	if rows := model.table.Rows(); len(model.markedVMs) != 0 || strings.HasPrefix(rows[0][0], markedPrefix) {
+		t.Errorf("VMs still marked after the run: %v", model.markedVMs)
+	}
+}
+
+func TestRunScriptBoundsConcurrency(t *testing.T) {
+	var resources []armresources.GenericResourceExpanded
+	for i := range maxConcurrentRuns + 2 {
+		name := fmt.Sprintf("vm%d", i)
+		resources = append(resources, armresources.GenericResourceExpanded{
+			ID:   to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/virtualMachines/" + name),
+			Name: to.Ptr(name),
+			Type: to.Ptr("Microsoft.Compute/virtualMachines"),
+		})
+	}
+	model := newResourcesModel(resources...)
+	for i := range resources {
+		model.table.SetCursor(i)
+		model, _ = pressKeys(model, runes(" "))
+	}
+
+	model, cmd := typeCommand(t, model, "run "+writeScript(t))
+	if cmd == nil || len(model.runTargetList) != len(resources) {
+		t.Fatalf(":run started on %d VMs", len(model.runTargetList))
+	}
+	started := func() int {
+		n := 0
+		for _, target := range model.runTargetList {
+			if target.started {
+				n++
+			}
+		}
+		return n
+	}
+	if started() != maxConcurrentRuns {
+		t.Errorf("%d VMs started at once, want %d", started(), maxConcurrentRuns)
+	}
+	if output := strings.Join(model.runOutput.allLines(), "\n"); strings.Count(output, ": queued") != 2 {
+		t.Errorf("output does not show the waiting VMs as queued:\n%s", output)
+	}
+
+	updated, cmd := model.Update(azure.RunCommandResultMsg{RunID: model.runID, VMID: *resources[0].ID, Stdout: "ok"})
+	model = updated.(Model)
+	if cmd == nil || started() != maxConcurrentRuns+1 {
+		t.Errorf("a finished VM did not start the next one (%d started)", started())
+	}
+}
+
+func TestChangingResourceGroupClearsMarks(t *testing.T) {
+	model := newFleetModel()
+	model, _ = pressKeys(model, runes(" "))
+	if len(model.markedVMs) != 1 {
+		t.Fatal("space did not mark the VM")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resourcegroups" {
+		t.Fatalf("esc from resources went to %q", model.currentView)
+	}
+	updated, _ := model.Update(azure.ResourceGroupsMsg{SubscriptionID: "sub-1", Groups: []armresources.ResourceGroup{{Name: to.Ptr("rg-b"), Location: to.Ptr("westeurope")}}})
+	model, _ = pressKeys(updated.(Model), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.currentView != "resources" || model.selectedRG != "rg-b" || len(model.markedVMs) != 0 {
+		t.Errorf("entering a resource group kept the marks: %v", model.markedVMs)
+	}
+}
+
+// writeScript writes a script for :run to a temporary file.
+func writeScript(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "diag.sh")
+	if err := os.WriteFile(path, []byte("df -h\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunScriptFromFile(t *testing.T) {
+	path := writeScript(t)
+
+	model, cmd := typeCommand(t, newFleetModel(), "run "+path)
+	if cmd == nil || model.currentView != "runresults" || len(model.runTargetList) != 1 || model.runTargetList[0].name != "vm1" {
+		t.Fatalf(":run <file> opened %q for %v", model.currentView, model.runTargetList)
+	}
+
+	model, cmd = typeCommand(t, newFleetModel(), "run "+path+".missing")
+	if cmd != nil || model.currentView != "resources" || !strings.Contains(model.flash, "no such file") {
+		t.Errorf(":run with a missing file: view %q, flash %q", model.currentView, model.flash)
+	}
+}
+
+func TestRunWithoutVM(t *testing.T) {
+	model, cmd := typeCommand(t, newMultiSubModel(), "run")
+	if cmd != nil || !strings.Contains(model.flash, ":run needs a VM") {
+		t.Errorf(":run outside a VM: flash %q", model.flash)
+	}
+}
@@ -0,0 +1,215 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// maxUserDelegationSAS is the longest a user delegation key, and so a SAS
+// signed with it, stays valid.
+const maxUserDelegationSAS = 7 * 24 * time.Hour
+
+// sasPermissions lists the permission letters each kind of SAS accepts,
+// and the ones offered by default.
+var sasPermissions = map[string]struct{ allowed, initial string }{
+	"account":   {allowed: "rwdlacup", initial: "rl"},
+	"container": {allowed: "racwdl", initial: "rl"},
+	"blob":      {allowed: "racwd", initial: "r"},
+}
+
+// handleSASKey starts the SAS form on s: for the account, the container
+// being browsed or selected, or the selected blob.
+func (m *Model) handleSASKey(key string) (tea.Cmd, bool) {
+	if key != "s" {
+		return nil, false
+	}
+
+	path := m.storagePath
+	entry, selected := m.selectedStorageEntry()
+	container, blob := "", ""
+	switch {
+	case path.IsRoot() && selected && entry.Kind == azure.StorageContainer:
+		container = entry.Name
+	case path.Service == azure.BlobService:
+		container = path.Container
+		if selected && entry.Kind == azure.StorageBlob {
+			blob = path.Prefix + entry.Name
+		}
+	}
+
+	choices := []choice{{key: "a", label: "account " + m.storage.Name, run: func(m *Model) tea.Cmd {
+		return m.askSASSigning(azure.SASRequest{})
+	}}}
+	if container != "" {
+		choices = append(choices, choice{key: "c", label: "container " + container, run: func(m *Model) tea.Cmd {
+			return m.askSASSigning(azure.SASRequest{Container: container})
+		}})
+	}
+	if blob != "" {
+		choices = append(choices, choice{key: "b", label: "blob " + blob, run: func(m *Model) tea.Cmd {
+			return m.askSASSigning(azure.SASRequest{Container: container, Blob: blob})
+		}})
+	}
+	if len(choices) == 1 {
+		return choices[0].run(m), true
+	}
+	m.askChoice("Generate a SAS for:", choices)
+	return nil, true
+}
+
+// sharedKeyWarning explains why the account key cannot sign a SAS, or
+// returns "" when it can.
+func (m Model) sharedKeyWarning() string {
+	switch {
+	case m.storage.SharedKeyDisabled:
+		return fmt.Sprintf("shared key access is disabled on %s, so key-signed SAS tokens would be rejected", m.storage.Name)
+	case m.storage.Key == "" && m.storage.KeyErr != nil:
+		return "the account key is not available: " + m.storage.KeyErr.Error()
+	case m.storage.Key == "":
+		return "the account key is not available"
+	}
+	return ""
+}
+
+func (m *Model) askSASSigning(req azure.SASRequest) tea.Cmd {
+	warning := m.sharedKeyWarning()
+	if req.Container == "" {
+		if warning != "" {
+			m.setFlash("Cannot sign an account SAS: " + warning)
+			return nil
+		}
+		return m.askSASPermissions(req, "account")
+	}
+
+	kind := "container"
+	if req.Blob != "" {
+		kind = "blob"
+	}
+	choices := []choice{{key: "u", label: "user delegation key (Entra ID)", run: func(m *Model) tea.Cmd {
+		req.UserDelegation = true
+		return m.askSASPermissions(req, kind)
+	}}}
+	question := "Sign the SAS with:"
+	if warning != "" {
+		question = fmt.Sprintf("Sign the SAS with (%s):", warning)
+	} else {
+		choices = append(choices, choice{key: "k", label: "account key", run: func(m *Model) tea.Cmd {
+			return m.askSASPermissions(req, kind)
+		}})
+	}
+	m.askChoice(question, choices)
+	return nil
+}
+
+func (m *Model) askSASPermissions(req azure.SASRequest, kind string) tea.Cmd {
+	perms := sasPermissions[kind]
+	m.askValue(fmt.Sprintf("Permissions for the %s SAS, any of %q:", req.Scope(), perms.allowed), perms.initial, func(m *Model, value string) tea.Cmd {
+		value = strings.TrimSpace(value)
+		if value == "" || strings.Trim(value, perms.allowed) != "" {
+			m.setFlash(fmt.Sprintf("invalid permissions %q: use letters from %q", value, perms.allowed))
+			return nil
+		}
+		req.Permissions = value
+		return m.askSASExpiry(req)
+	})
+	return nil
+}
+
+func (m *Model) askSASExpiry(req azure.SASRequest) tea.Cmd {
+	m.askValue("Valid for (e.g. 30m, 8h, 7d):", "8h", func(m *Model, value string) tea.Cmd {
+		validity, err := parseValidity(value)
+		if err != nil {
+			m.setFlash(err.Error())
+			return nil
+		}
+		if req.UserDelegation && validity > maxUserDelegationSAS {
+			m.setFlash("a user delegation SAS is valid for at most 7 days")
+			return nil
+		}
+		req.Expiry = time.Now().Add(validity)
+		m.openDetailView("sas")
+		return azure.GenerateSAS(m.storage, req)
+	})
+	return nil
+}
+
+// parseValidity reads a duration such as 90m or 8h, also accepting whole
+// days such as 7d.
+func parseValidity(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	var validity time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		validity = time.Duration(n) * 24 * time.Hour
+	} else {
+		validity, err = time.ParseDuration(value)
+	}
+	if err != nil || validity <= 0 {
+		return 0, fmt.Errorf("invalid validity %q: use a duration such as 8h or 7d", value)
+	}
+	return validity, nil
+}
+
+// sasSecret is what the SAS view copies by default: the URL of a container
+// or blob SAS, the connection string of an account SAS.
+func (m Model) sasSecret() (string, string) {
+	if m.sas.URL != "" {
+		return "SAS URL", m.sas.URL
+	}
+	return "connection string", m.sas.ConnectionString
+}
+
+func (m *Model) applySAS(msg azure.SASMsg) tea.Cmd {
+	if m.currentView != "sas" || msg.AccountID != m.storage.ID {
+		return nil
+	}
+	m.loading = false
+	m.err = nil
+	m.sas = msg
+
+	req := msg.Request
+	signedWith := "account key"
+	if req.UserDelegation {
+		signedWith = "user delegation key (Entra ID)"
+	}
+	m.properties = []ui.Property{
+		{Key: "Scope", Value: req.Scope()},
+		{Key: "Signed with", Value: signedWith},
+		{Key: "Permissions", Value: req.Permissions},
+		{Key: "Expires", Value: req.Expiry.Local().Format("2006-01-02 15:04:05")},
+	}
+	if msg.URL != "" {
+		m.properties = append(m.properties, ui.Property{Key: "URL", Value: msg.URL})
+	}
+	if msg.ConnectionString != "" {
+		m.properties = append(m.properties, ui.Property{Key: "Connection string", Value: msg.ConnectionString})
+	}
+	m.properties = append(m.properties, ui.Property{Key: "Token", Value: msg.Token})
+
+	name, secret := m.sasSecret()
+	m.setFlash(fmt.Sprintf("Copied the %s to the clipboard", name))
+	return copyToClipboard(secret)
+}
+
+// handleSASViewKey copies the generated SAS again: c the URL or connection
+// string, t the bare token.
+func (m *Model) handleSASViewKey(key string) (tea.Cmd, bool) {
+	switch key {
+	case "c":
+		name, secret := m.sasSecret()
+		m.setFlash(fmt.Sprintf("Copied the %s to the clipboard", name))
+		return copyToClipboard(secret), true
+	case "t":
+		m.setFlash("Copied the SAS token to the clipboard")
+		return copyToClipboard(m.sas.Token), true
+	}
+	return nil, false
+}
@@ -0,0 +1,104 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func TestBlobSASWithUserDelegation(t *testing.T) {
+	model := newContainerModel(t)
+	model.table.SetCursor(1)
+	model, _ = pressKeys(model, runes("s"))
+	if model.prompt == nil || len(model.prompt.choices) != 3 {
+		t.Fatalf("s on a blob did not offer account, container and blob, got %+v", model.prompt)
+	}
+
+	model, _ = pressKeys(model, runes("b"))
+	if model.prompt == nil || len(model.prompt.choices) != 1 || !strings.Contains(model.prompt.question, "not available") {
+		t.Fatalf("without the account key only user delegation should be offered, got %+v", model.prompt)
+	}
+	model, _ = pressKeys(model, runes("u"))
+	if model.prompt == nil || model.prompt.input.Value() != "r" {
+		t.Fatalf("permissions prompt = %+v, want r by default", model.prompt)
+	}
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	model.prompt.input.SetValue("10d")
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd != nil || !strings.Contains(model.flash, "at most 7 days") {
+		t.Fatalf("a 10 day user delegation SAS was not refused, flash %q", model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("s"), runes("b"), runes("u"), tea.KeyMsg{Type: tea.KeyEnter})
+	model, cmd = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "sas" || !model.loading {
+		t.Fatalf("generating the SAS opened %q (loading %v)", model.currentView, model.loading)
+	}
+
+	req := azure.SASRequest{Container: "logs", Blob: "2024/app.json", Permissions: "r", Expiry: time.Now().Add(8 * time.Hour), UserDelegation: true}
+	updated, cmd := model.Update(azure.SASMsg{
+		AccountID: testStorageID,
+		Request:   req,
+		Token:     "sv=2021-08-06&sig=abc",
+		URL:       "https://st1.blob.core.windows.net/logs/2024/app.json?sv=2021-08-06&sig=abc",
+	})
+	model = updated.(Model)
+	if cmd == nil || model.loading || !strings.Contains(model.flash, "Copied the SAS URL") {
+		t.Fatalf("the SAS URL was not copied, flash %q", model.flash)
+	}
+	props := map[string]string{}
+	for _, p := range model.properties {
+		props[p.Key] = p.Value
+	}
+	if props["Scope"] != "blob logs/2024/app.json" || !strings.HasPrefix(props["Signed with"], "user delegation") || props["Token"] == "" {
+		t.Errorf("properties = %v", props)
+	}
+
+	model, cmd = pressKeys(model, runes("t"))
+	if cmd == nil || !strings.Contains(model.flash, "SAS token") {
+		t.Errorf("t did not copy the token, flash %q", model.flash)
+	}
+}
+
+func TestAccountSASNeedsTheKey(t *testing.T) {
+	model := newStorageModel(t)
+	model.table.SetCursor(1)
+	model, _ = pressKeys(model, runes("s"))
+	if model.prompt != nil || !strings.Contains(model.flash, "Cannot sign an account SAS") {
+		t.Fatalf("account SAS without a key: prompt %+v, flash %q", model.prompt, model.flash)
+	}
+
+	model.storage.Key = "a2V5"
+	model.storage.KeyErr = nil
+	model, _ = pressKeys(model, runes("s"))
+	if model.prompt == nil || model.prompt.input.Value() != "rl" || !strings.Contains(model.prompt.question, "account SAS") {
+		t.Fatalf("s on a queue with the key should ask for account permissions, got %+v", model.prompt)
+	}
+	model.prompt.input.SetValue("rx")
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if !strings.Contains(model.flash, "invalid permissions") {
+		t.Errorf("flash = %q, want invalid permissions", model.flash)
+	}
+
+	model.storage.SharedKeyDisabled = true
+	model, _ = pressKeys(model, runes("s"))
+	if model.prompt != nil || !strings.Contains(model.flash, "shared key access is disabled") {
+		t.Errorf("flash = %q, want a shared key warning", model.flash)
+	}
+}
+
+func TestParseValidity(t *testing.T) {
+	for value, want := range map[string]time.Duration{"30m": 30 * time.Minute, "8h": 8 * time.Hour, "7d": 7 * 24 * time.Hour} {
+		if got, err := parseValidity(value); err != nil || got != want {
+			t.Errorf("parseValidity(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"", "0h", "-1d", "soon"} {
+		if _, err := parseValidity(value); err == nil {
+			t.Errorf("parseValidity(%q) did not fail", value)
+		}
+	}
+}
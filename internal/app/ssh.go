@@ -0,0 +1,91 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
+)
+
+// connectSSH resolves the address of a VM; the session is started once the
+// azure.SSHTargetMsg arrives.
+func (m *Model) connectSSH(resourceID, name string) tea.Cmd {
+	m.setFlash(fmt.Sprintf("Resolving address of %s...", name))
+	return azure.ResolveSSHTarget(resourceID)
+}
+
+// sshCommand builds the command that connects to target: plain ssh to its
+// public IP, or an Azure Bastion tunnel when it has none and a Bastion host
+// is configured for its subscription.
+func sshCommand(target azure.SSHTargetMsg, settings config.SSH) (*exec.Cmd, error) {
+	user := settings.User
+	if user == "" {
+		user = target.AdminUsername
+	}
+	// ssh and az are started without a shell, so expand ~ here.
+	if rest, ok := strings.CutPrefix(settings.KeyFile, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			settings.KeyFile = filepath.Join(home, rest)
+		}
+	}
+
+	if ip := publicIP(target.Addresses); ip != "" {
+		args := []string{}
+		if settings.KeyFile != "" {
+			args = append(args, "-i", settings.KeyFile)
+		}
+		host := ip
+		if user != "" {
+			host = user + "@" + ip
+		}
+		return exec.Command("ssh", append(args, host)...), nil
+	}
+
+	if settings.Bastion == "" {
+		if target.NetworkErr != nil {
+			return nil, fmt.Errorf("%s has no public IP that could be read and no Bastion host is configured for subscription %s: %w", target.Name, target.SubscriptionID, target.NetworkErr)
+		}
+		return nil, fmt.Errorf("%s has no public IP and no Bastion host is configured for subscription %s", target.Name, target.SubscriptionID)
+	}
+	bastion, err := arm.ParseResourceID(settings.Bastion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Bastion host %q: %w", settings.Bastion, err)
+	}
+
+	args := []string{
+		"network", "bastion", "ssh",
+		"--name", bastion.Name,
+		"--resource-group", bastion.ResourceGroupName,
+		"--subscription", bastion.SubscriptionID,
+		"--target-resource-id", target.VMID,
+	}
+	if settings.KeyFile != "" {
+		if user == "" {
+			return nil, fmt.Errorf("no user to sign in to %s with its key; set user in the ssh settings for subscription %s", target.Name, target.SubscriptionID)
+		}
+		args = append(args, "--auth-type", "ssh-key", "--username", user, "--ssh-key", settings.KeyFile)
+	} else {
+		args = append(args, "--auth-type", "AAD")
+	}
+	return exec.Command("az", args...), nil
+}
+
+// publicIP returns the first allocated public IP, preferring the primary IP
+// configuration.
+func publicIP(addresses []azure.VMAddress) string {
+	sorted := append([]azure.VMAddress(nil), addresses...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Primary && !sorted[j].Primary })
+	for _, address := range sorted {
+		if address.PublicIP != "" {
+			return address.PublicIP
+		}
+	}
+	return ""
+}
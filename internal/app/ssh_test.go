@@ -0,0 +1,131 @@
+package app
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
+)
+
+const testBastionID = "/subscriptions/hub/resourceGroups/rg-hub/providers/Microsoft.Network/bastionHosts/bas-hub"
+
+func TestSSHCommand(t *testing.T) {
+	t.Setenv("HOME", "/home/ops")
+
+	withPublicIP := azure.SSHTargetMsg{
+		VMID:           testVMID,
+		Name:           "vm1",
+		SubscriptionID: "sub-1",
+		AdminUsername:  "azureuser",
+		Addresses: []azure.VMAddress{
+			{NIC: "nic2", PublicIP: "20.0.0.2"},
+			{NIC: "nic1", Primary: true, PublicIP: "20.0.0.1"},
+		},
+	}
+	privateOnly := azure.SSHTargetMsg{
+		VMID:           testVMID,
+		Name:           "vm1",
+		SubscriptionID: "sub-1",
+		AdminUsername:  "azureuser",
+		Addresses:      []azure.VMAddress{{NIC: "nic1", Primary: true, PrivateIP: "10.0.0.4", PublicIPID: "/subscriptions/sub-1/resourceGroups/rg/providers/Microsoft.Network/publicIPAddresses/pip1"}},
+	}
+
+	tests := []struct {
+		name     string
+		target   azure.SSHTargetMsg
+		settings config.SSH
+		want     []string
+	}{
+		{
+			name:   "public IP with admin user",
+			target: withPublicIP,
+			want:   []string{"ssh", "azureuser@20.0.0.1"},
+		},
+		{
+			name:     "public IP with configured user and key",
+			target:   withPublicIP,
+			settings: config.SSH{User: "ops", KeyFile: "~/.ssh/ops", Bastion: testBastionID},
+			want:     []string{"ssh", "-i", "/home/ops/.ssh/ops", "ops@20.0.0.1"},
+		},
+		{
+			name:     "bastion with key",
+			target:   privateOnly,
+			settings: config.SSH{KeyFile: "/keys/id", Bastion: testBastionID},
+			want: []string{"az", "network", "bastion", "ssh", "--name", "bas-hub", "--resource-group", "rg-hub",
+				"--subscription", "hub", "--target-resource-id", testVMID,
+				"--auth-type", "ssh-key", "--username", "azureuser", "--ssh-key", "/keys/id"},
+		},
+		{
+			name:     "bastion with Entra ID",
+			target:   privateOnly,
+			settings: config.SSH{Bastion: testBastionID},
+			want: []string{"az", "network", "bastion", "ssh", "--name", "bas-hub", "--resource-group", "rg-hub",
+				"--subscription", "hub", "--target-resource-id", testVMID, "--auth-type", "AAD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := sshCommand(tt.target, tt.settings)
+			if err != nil {
+				t.Fatalf("sshCommand() error = %v", err)
+			}
+			if !slices.Equal(cmd.Args, tt.want) {
+				t.Errorf("args = %q, want %q", cmd.Args, tt.want)
+			}
+		})
+	}
+
+	if _, err := sshCommand(privateOnly, config.SSH{}); err == nil || !strings.Contains(err.Error(), "no public IP") {
+		t.Errorf("sshCommand() without public IP or Bastion error = %v", err)
+	}
+
+	// A public IP that failed to load still leaves the private addresses,
+	// and its error is shown when there is no other way in.
+	partial := privateOnly
+	partial.NetworkErr = errors.New("AuthorizationFailed")
+	if _, err := sshCommand(partial, config.SSH{}); err == nil || !strings.Contains(err.Error(), "AuthorizationFailed") {
+		t.Errorf("sshCommand() with an unreadable public IP error = %v", err)
+	}
+	if cmd, err := sshCommand(partial, config.SSH{Bastion: testBastionID}); err != nil || cmd.Args[0] != "az" {
+		t.Errorf("sshCommand() with an unreadable public IP did not use Bastion: %v", err)
+	}
+
+	noUser := privateOnly
+	noUser.AdminUsername = ""
+	if _, err := sshCommand(noUser, config.SSH{KeyFile: "/keys/id", Bastion: testBastionID}); err == nil || !strings.Contains(err.Error(), "no user") {
+		t.Errorf("sshCommand() with a key but no user error = %v", err)
+	}
+}
+
+func TestSSHFromResourcesView(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+	model.cfg = config.Config{SSH: map[string]config.SSH{"*": {User: "ops"}}}
+
+	model, cmd := pressKeys(model, runes("s"))
+	if cmd == nil || !strings.Contains(model.flash, "vm1") {
+		t.Fatalf("s on a VM row did not resolve its address, flash = %q", model.flash)
+	}
+
+	updated, cmd := model.Update(azure.SSHTargetMsg{VMID: testVMID, Name: "vm1", SubscriptionID: "sub-1"})
+	model = updated.(Model)
+	if cmd != nil || !strings.Contains(model.flash, "no public IP") {
+		t.Errorf("VM without a reachable address: cmd = %v, flash = %q", cmd != nil, model.flash)
+	}
+
+	updated, cmd = model.Update(azure.SSHTargetMsg{
+		VMID: testVMID, Name: "vm1", SubscriptionID: "sub-1",
+		Addresses: []azure.VMAddress{{PublicIP: "20.0.0.1"}},
+	})
+	model = updated.(Model)
+	if cmd == nil || model.flash != "" {
+		t.Errorf("VM with a public IP did not start ssh, flash = %q", model.flash)
+	}
+}
@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// storageServiceNames labels the services of a storage account.
+var storageServiceNames = map[string]string{
+	azure.BlobService:  "Blob service",
+	azure.FileService:  "File service",
+	azure.QueueService: "Queue service",
+	azure.TableService: "Table service",
+}
+
+func (m *Model) openStorage(resourceID string) tea.Cmd {
+	m.openDetailView("storage")
+	m.storage = azure.StorageAccount{ID: resourceID, Name: resourceName(resourceID)}
+	m.storagePath = azure.StoragePath{}
+	m.storageEntries = nil
+	m.storageErrors = nil
+	m.storageSelect = ""
+	return azure.FetchStorageAccount(resourceID)
+}
+
+// browseStorage moves the explorer to path; the entry named selectName, if
+// any, is selected once it is listed.
+func (m *Model) browseStorage(path azure.StoragePath, selectName string) tea.Cmd {
+	m.storagePath = path
+	m.storageSelect = selectName
+	m.loading = true
+	m.err = nil
+	return azure.ListStorage(m.storage, path)
+}
+
+// parentPrefix returns the directory above prefix, "" at the top.
+func parentPrefix(prefix string) string {
+	trimmed := strings.TrimSuffix(prefix, "/")
+	i := strings.LastIndex(trimmed, "/")
+	if i < 0 {
+		return ""
+	}
+	return trimmed[:i+1]
+}
+
+func (m *Model) updateTableWithStorage() {
+	path := m.storagePath
+
+	auth := "account key"
+	if m.storage.Key == "" {
+		auth = "Microsoft Entra ID"
+		if m.storage.KeyErr != nil {
+			auth += " (no account key: " + m.storage.KeyErr.Error() + ")"
+		}
+	}
+	count := fmt.Sprintf("%d", len(m.storageEntries))
+	switch path.Service {
+	case azure.QueueService:
+		count += " (peeked, messages stay in the queue)"
+	case azure.TableService:
+		count += " (first 200 matching)"
+	}
+	m.properties = []ui.Property{
+		{Key: "Account", Value: m.storage.Name},
+		{Key: "Auth", Value: auth},
+		{Key: "Location", Value: path.String()},
+	}
+	if path.Filter != "" {
+		m.properties = append(m.properties, ui.Property{Key: "Filter", Value: path.Filter})
+	}
+	m.properties = append(m.properties, ui.Property{Key: "Entries", Value: count})
+
+	services := make([]string, 0, len(m.storageErrors))
+	for service := range m.storageErrors {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		m.properties = append(m.properties, ui.Property{
+			Key:   storageServiceNames[service],
+			Value: styles.ErrorStyle.Render(m.storageErrors[service].Error()),
+		})
+	}
+
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.35)     // 35% of width
+	kindWidth := int(float64(m.width) * 0.1)      // 10% of width
+	sizeWidth := int(float64(m.width) * 0.1)      // 10% of width
+	modifiedWidth := int(float64(m.width) * 0.15) // 15% of width
+	detailWidth := int(float64(m.width) * 0.3)    // 30% of width
+
+	columns := []table.Column{
+		{Title: "Name", Width: nameWidth},
+		{Title: "Kind", Width: kindWidth},
+		{Title: "Size", Width: sizeWidth},
+		{Title: "Last Modified", Width: modifiedWidth},
+		{Title: "Details", Width: detailWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, entry := range m.storageEntries {
+		modified := "-"
+		if !entry.LastModified.IsZero() {
+			modified = entry.LastModified.Local().Format("2006-01-02 15:04")
+		}
+		detail := entry.Detail
+		if detail == "" {
+			detail = "-"
+		}
+		rows = append(rows, table.Row{entry.Name, entry.Kind, formatBytes(entry.Size), modified, detail})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"Empty", "-", "-", "-", "-"})
+	}
+
+	cursor := m.table.Cursor()
+	m.table.SetRows(rows)
+	m.table.SetCursor(cursor)
+	for i, entry := range m.storageEntries {
+		if m.storageSelect != "" && entry.Name == m.storageSelect {
+			m.table.SetCursor(i)
+		}
+	}
+}
+
+// selectedStorageEntry returns the entry under the cursor.
+func (m Model) selectedStorageEntry() (azure.StorageEntry, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.storageEntries) {
+		return azure.StorageEntry{}, false
+	}
+	return m.storageEntries[cursor], true
+}
+
+// handleStorageKey moves through the storage explorer. It reports whether
+// the key was handled; esc at the account root is left to close the view.
+func (m *Model) handleStorageKey(key string) (tea.Cmd, bool) {
+	path := m.storagePath
+
+	switch key {
+	case "enter":
+		entry, ok := m.selectedStorageEntry()
+		if !ok {
+			return nil, true
+		}
+		switch entry.Kind {
+		case azure.StorageContainer:
+			return m.browseStorage(azure.StoragePath{Service: azure.BlobService, Container: entry.Name}, ""), true
+		case azure.StorageShare:
+			return m.browseStorage(azure.StoragePath{Service: azure.FileService, Container: entry.Name}, ""), true
+		case azure.StorageQueue:
+			return m.browseStorage(azure.StoragePath{Service: azure.QueueService, Container: entry.Name}, ""), true
+		case azure.StorageTable:
+			return m.browseStorage(azure.StoragePath{Service: azure.TableService, Container: entry.Name}, ""), true
+		case azure.StorageDirectory:
+			path.Prefix += entry.Name
+			return m.browseStorage(path, ""), true
+		}
+		return nil, true
+
+	case "esc", "backspace":
+		if path.IsRoot() {
+			return nil, false
+		}
+		if path.Prefix != "" {
+			parent := parentPrefix(path.Prefix)
+			child := strings.TrimPrefix(path.Prefix, parent)
+			path.Prefix = parent
+			return m.browseStorage(path, child), true
+		}
+		return m.browseStorage(azure.StoragePath{}, path.Container), true
+
+	case "r":
+		return m.browseStorage(path, ""), true
+
+	case "f":
+		if path.Service != azure.TableService {
+			return nil, false
+		}
+		m.askValue(fmt.Sprintf("OData filter for %s (empty for all):", path.Container), path.Filter, func(m *Model, value string) tea.Cmd {
+			filtered := m.storagePath
+			filtered.Filter = strings.TrimSpace(value)
+			return m.browseStorage(filtered, "")
+		})
+		return nil, true
+	}
+	return nil, false
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testStorageID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Storage/storageAccounts/st1"
+
+func newStorageModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testStorageID), Name: to.Ptr("st1"), Type: to.Ptr("Microsoft.Storage/storageAccounts")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "storage" || model.storage.ID != testStorageID {
+		t.Fatalf("enter on storage account row opened %q (%s)", model.currentView, model.storage.ID)
+	}
+
+	updated, cmd := model.Update(azure.StorageAccountMsg{Account: azure.StorageAccount{ID: testStorageID, Name: "st1", KeyErr: errors.New("AuthorizationFailed")}})
+	model = updated.(Model)
+	if cmd == nil || !model.loading {
+		t.Fatal("resolving the account did not list its root")
+	}
+
+	updated, _ = model.Update(azure.StorageListMsg{
+		AccountID: testStorageID,
+		Entries: []azure.StorageEntry{
+			{Name: "logs", Kind: azure.StorageContainer, Size: -1, Detail: "private"},
+			{Name: "orders", Kind: azure.StorageQueue, Size: -1},
+			{Name: "events", Kind: azure.StorageTable, Size: -1},
+		},
+		Errors: map[string]error{azure.FileService: errors.New("FeatureNotSupported")},
+	})
+	return updated.(Model)
+}
+
+func storagePathMsg(path azure.StoragePath, entries ...azure.StorageEntry) azure.StorageListMsg {
+	return azure.StorageListMsg{AccountID: testStorageID, Path: path, Entries: entries}
+}
+
+func TestStorageExplorerRoot(t *testing.T) {
+	model := newStorageModel(t)
+
+	rows := model.table.Rows()
+	if len(rows) != 3 || rows[0][1] != "Container" || rows[2][1] != "Table" {
+		t.Fatalf("root rows = %v", rows)
+	}
+	view := model.View()
+	for _, want := range []string{"Microsoft Entra ID (no account key: AuthorizationFailed)", "File service", "FeatureNotSupported"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view does not contain %q", want)
+		}
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resources" {
+		t.Errorf("esc at the account root returned to %q, want resources", model.currentView)
+	}
+}
+
+func TestStorageExplorerBlobDirectories(t *testing.T) {
+	model := newStorageModel(t)
+
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	container := azure.StoragePath{Service: azure.BlobService, Container: "logs"}
+	if cmd == nil || model.storagePath != container {
+		t.Fatalf("enter on container moved to %+v", model.storagePath)
+	}
+
+	updated, _ := model.Update(storagePathMsg(container,
+		azure.StorageEntry{Name: "2024/", Kind: azure.StorageDirectory, Size: -1},
+		azure.StorageEntry{Name: "b.txt", Kind: azure.StorageBlob, Size: 2048},
+	))
+	model = updated.(Model)
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[1][2] != "2.0 KiB" {
+		t.Fatalf("container rows = %v", rows)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	dir := azure.StoragePath{Service: azure.BlobService, Container: "logs", Prefix: "2024/"}
+	if model.storagePath != dir {
+		t.Fatalf("enter on directory moved to %+v", model.storagePath)
+	}
+
+	// A listing of a place the user already left is dropped.
+	updated, _ = model.Update(storagePathMsg(container))
+	model = updated.(Model)
+	if !model.loading {
+		t.Error("stale listing replaced the directory being loaded")
+	}
+
+	updated, _ = model.Update(storagePathMsg(dir, azure.StorageEntry{Name: "a.txt", Kind: azure.StorageBlob, Size: 1}))
+	model = updated.(Model)
+	if !strings.Contains(model.View(), "blob://logs/2024/") {
+		t.Error("view does not show the current location")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyBackspace})
+	if model.storagePath != container {
+		t.Fatalf("backspace moved to %+v, want the container root", model.storagePath)
+	}
+	updated, _ = model.Update(storagePathMsg(container,
+		azure.StorageEntry{Name: "0000/", Kind: azure.StorageDirectory, Size: -1},
+		azure.StorageEntry{Name: "2024/", Kind: azure.StorageDirectory, Size: -1},
+	))
+	model = updated.(Model)
+	if got := model.table.SelectedRow()[0]; got != "2024/" {
+		t.Errorf("going up selected %q, want the directory we came from", got)
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if !model.storagePath.IsRoot() || model.currentView != "storage" {
+		t.Errorf("esc in a container moved to %+v in %q, want the account root", model.storagePath, model.currentView)
+	}
+}
+
+func TestStorageExplorerTableFilter(t *testing.T) {
+	model := newStorageModel(t)
+	model.table.SetCursor(2)
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	table := azure.StoragePath{Service: azure.TableService, Container: "events"}
+	updated, _ := model.Update(storagePathMsg(table, azure.StorageEntry{Name: "eu / 1", Kind: azure.StorageEntity, Size: -1, Detail: "Color=blue"}))
+	model = updated.(Model)
+
+	model, _ = pressKeys(model, runes("f"))
+	if model.prompt == nil {
+		t.Fatal("f in a table did not prompt for a filter")
+	}
+	model, cmd := pressKeys(model, runes("PartitionKey eq 'eu'"), tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.storagePath.Filter != "PartitionKey eq 'eu'" {
+		t.Errorf("filter moved to %+v", model.storagePath)
+	}
+}
+
+func TestStorageRepliesAfterEsc(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testStorageID), Name: to.Ptr("st1"), Type: to.Ptr("Microsoft.Storage/storageAccounts")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEsc})
+
+	for _, msg := range []tea.Msg{
+		azure.StorageAccountMsg{Account: azure.StorageAccount{ID: testStorageID, Name: "st1"}},
+		storagePathMsg(azure.StoragePath{}),
+	} {
+		updated, cmd := model.Update(msg)
+		model = updated.(Model)
+		if cmd != nil || model.currentView != "resources" || model.loading {
+			t.Errorf("late %T left view %q loading=%v", msg, model.currentView, model.loading)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{-1: "-", 0: "0 B", 1023: "1023 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"}
+	for input, want := range tests {
+		if got := formatBytes(input); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+// switchTenant scopes the session to another tenant and reloads the
+// subscriptions visible there. Everything cached for the previous tenant is
+// dropped since it belongs to a different directory.
+func (m *Model) switchTenant(tenantID string) tea.Cmd {
+	azure.SetTenant(tenantID)
+
+	m.tenantID = tenantID
+	m.header = ""
+	m.subscriptions = nil
+	m.resourceGroups = make(map[string][]armresources.ResourceGroup)
+	m.resources = make(map[string][]armresources.GenericResourceExpanded)
+	m.markedSubs = make(map[string]bool)
+	m.subResources = make(map[string][]armresources.GenericResourceExpanded)
+	m.subErrors = nil
+	m.multiSub = false
+	m.selectedSub = ""
+	m.selectedRG = ""
+	clear(m.markedVMs)
+
+	m.currentView = "subscriptions"
+	m.loading = true
+	return azure.FetchSubscriptions
+}
+
+func (m *Model) updateTableWithTenants() {
+	m.table.SetRows([]table.Row{})
+
+	idWidth := int(float64(m.width) * 0.6)     // 60% of width
+	statusWidth := int(float64(m.width) * 0.4) // 40% of width
+
+	columns := []table.Column{
+		{Title: "Tenant ID", Width: idWidth},
+		{Title: "Status", Width: statusWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	cursor := 0
+	for i, tenant := range m.tenants {
+		status := ""
+		if *tenant.TenantID == m.tenantID {
+			status = "Current"
+			cursor = i
+		}
+		rows = append(rows, table.Row{*tenant.TenantID, status})
+	}
+	m.table.SetRows(rows)
+	if len(rows) > 0 {
+		m.table.SetCursor(cursor)
+	}
+}
@@ -0,0 +1,68 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+func TestSwitchTenant(t *testing.T) {
+	defer azure.SetTenant("")
+
+	model := newMultiSubModel()
+	model.markedSubs["sub-1"] = true
+
+	updated, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	model = updated.(Model)
+	if model.currentView != "tenants" {
+		t.Fatalf("currentView = %q, want tenants", model.currentView)
+	}
+
+	updated, _ = model.Update(azure.TenantsMsg{Tenants: []armsubscription.TenantIDDescription{
+		{TenantID: to.Ptr("tenant-a")},
+		{TenantID: to.Ptr("tenant-b")},
+	}})
+	model = updated.(Model)
+	model.table.SetCursor(1)
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+
+	if cmd == nil {
+		t.Fatal("switching tenant did not reload subscriptions")
+	}
+	if azure.Tenant() != "tenant-b" || model.tenantID != "tenant-b" {
+		t.Errorf("tenant = %q (model %q), want tenant-b", azure.Tenant(), model.tenantID)
+	}
+	if model.currentView != "subscriptions" || len(model.subscriptions) != 0 || len(model.markedSubs) != 0 {
+		t.Error("switching tenant kept state from the previous tenant")
+	}
+}
+
+func TestSubscriptionTenantColumn(t *testing.T) {
+	model := newMultiSubModel()
+	updated, _ := model.Update(azure.SubscriptionsMsg{
+		Subs: []armsubscription.Subscription{
+			newTestSubscription("prod", "sub-1"),
+			newTestSubscription("guest", "sub-2"),
+		},
+		TenantID: "tenant-a",
+		Tenants:  map[string]string{"sub-1": "tenant-a", "sub-2": "tenant-b"},
+	})
+	model = updated.(Model)
+
+	rows := model.table.Rows()
+	if len(rows) != 2 || rows[0][2] != "tenant-a" || rows[1][2] != "tenant-b" {
+		t.Errorf("tenant column = %v, want each subscription's own tenant", rows)
+	}
+
+	// Without the tenants the column says so rather than guessing.
+	updated, _ = model.Update(azure.SubscriptionsMsg{Subs: []armsubscription.Subscription{newTestSubscription("prod", "sub-1")}, TenantID: "tenant-a"})
+	model = updated.(Model)
+	if got := model.table.Rows()[0][2]; got != "-" {
+		t.Errorf("tenant column = %q without tenants, want -", got)
+	}
+}
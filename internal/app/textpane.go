@@ -0,0 +1,211 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/styles"
+)
+
+// textPane is a scrollable block of text with incremental search. When
+// follow is set, new content keeps the pane scrolled to the bottom.
+type textPane struct {
+	viewport viewport.Model
+	lines    []string
+	partial  string // trailing text not yet terminated by a newline
+
+	searching bool
+	input     string
+	query     string
+	matches   []int // line numbers containing query
+	match     int
+
+	follow bool
+}
+
+func newTextPane() textPane {
+	return textPane{viewport: viewport.New(0, 0)}
+}
+
+// setSize fits the pane to the space left for content.
+func (p *textPane) setSize(width, height int) {
+	p.viewport.Width = width
+	p.viewport.Height = height
+	p.render()
+}
+
+// setText replaces the pane's content.
+func (p *textPane) setText(text string) {
+	p.lines = nil
+	p.partial = ""
+	p.appendText(text)
+}
+
+// appendText adds text to the end of the pane, completing the last line if
+// it was unterminated.
+func (p *textPane) appendText(text string) {
+	text = p.partial + strings.ReplaceAll(text, "\r", "")
+	parts := strings.Split(text, "\n")
+	p.lines = append(p.lines, parts[:len(parts)-1]...)
+	p.partial = parts[len(parts)-1]
+	p.findMatches()
+	p.render()
+	if p.follow {
+		p.viewport.GotoBottom()
+	}
+}
+
+func (p *textPane) allLines() []string {
+	if p.partial == "" {
+		return p.lines
+	}
+	return append(p.lines[:len(p.lines):len(p.lines)], p.partial)
+}
+
+func (p *textPane) findMatches() {
+	p.matches = nil
+	if p.query == "" {
+		return
+	}
+	query := strings.ToLower(p.query)
+	for i, line := range p.allLines() {
+		if strings.Contains(strings.ToLower(line), query) {
+			p.matches = append(p.matches, i)
+		}
+	}
+	if p.match >= len(p.matches) {
+		p.match = 0
+	}
+}
+
+// render sets the viewport content, highlighting search matches.
+func (p *textPane) render() {
+	lines := p.allLines()
+	if p.query == "" {
+		p.viewport.SetContent(strings.Join(lines, "\n"))
+		return
+	}
+
+	query := strings.ToLower(p.query)
+	rendered := make([]string, len(lines))
+	for i, line := range lines {
+		rendered[i] = highlight(line, query)
+	}
+	p.viewport.SetContent(strings.Join(rendered, "\n"))
+}
+
+// highlight marks every case-insensitive occurrence of query in line.
+func highlight(line, query string) string {
+	lower := strings.ToLower(line)
+	if len(lower) != len(line) {
+		// Case folding changed byte offsets; leave the line as is.
+		return line
+	}
+	var sb strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			sb.WriteString(line)
+			return sb.String()
+		}
+		sb.WriteString(line[:i])
+		sb.WriteString(styles.MatchStyle.Render(line[i : i+len(query)]))
+		line, lower = line[i+len(query):], lower[i+len(query):]
+	}
+}
+
+// jump scrolls to the next (or previous) search match.
+func (p *textPane) jump(forward bool) {
+	if len(p.matches) == 0 {
+		return
+	}
+	if forward {
+		p.match = (p.match + 1) % len(p.matches)
+	} else {
+		p.match = (p.match - 1 + len(p.matches)) % len(p.matches)
+	}
+	p.follow = false
+	p.viewport.SetYOffset(p.matches[p.match])
+}
+
+// update handles a key. It reports whether the key was used by the pane.
+func (p *textPane) update(msg tea.KeyMsg) (tea.Cmd, bool) {
+	if p.searching {
+		switch msg.Type {
+		case tea.KeyEsc:
+			p.searching = false
+		case tea.KeyEnter:
+			p.searching = false
+			p.query = p.input
+			p.match = -1
+			p.findMatches()
+			p.render()
+			p.jump(true)
+		case tea.KeyBackspace:
+			if len(p.input) > 0 {
+				p.input = p.input[:len(p.input)-1]
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			p.input += string(msg.Runes)
+		}
+		return nil, true
+	}
+
+	switch msg.String() {
+	case "/":
+		p.searching = true
+		p.input = ""
+		return nil, true
+	case "n":
+		p.jump(true)
+		return nil, true
+	case "N":
+		p.jump(false)
+		return nil, true
+	case "g", "home":
+		p.follow = false
+		p.viewport.GotoTop()
+		return nil, true
+	case "G", "end":
+		p.viewport.GotoBottom()
+		return nil, true
+	case "esc", "q", ":", "ctrl+c":
+		return nil, false
+	}
+
+	before := p.viewport.YOffset
+	var cmd tea.Cmd
+	p.viewport, cmd = p.viewport.Update(msg)
+	if p.viewport.YOffset < before {
+		// Scrolling up stops following, like less +F.
+		p.follow = false
+	}
+	return cmd, true
+}
+
+// status describes the pane's position, search and follow state for the
+// line above it.
+func (p *textPane) status() string {
+	if p.searching {
+		return fmt.Sprintf("/%s█", p.input)
+	}
+	parts := []string{fmt.Sprintf("%d lines, %3.0f%%", len(p.allLines()), p.viewport.ScrollPercent()*100)}
+	if p.query != "" {
+		if len(p.matches) == 0 {
+			parts = append(parts, fmt.Sprintf("no matches for %q", p.query))
+		} else {
+			parts = append(parts, fmt.Sprintf("match %d/%d for %q", p.match+1, len(p.matches), p.query))
+		}
+	}
+	if p.follow {
+		parts = append(parts, "following")
+	}
+	return strings.Join(parts, " • ")
+}
+
+// view renders the status line and the visible text.
+func (p *textPane) view() string {
+	return styles.SearchStyle.Render(p.status()) + "\n" + p.viewport.View()
+}
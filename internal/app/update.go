@@ -16,6 +16,7 @@ var resourceTypes = []string{
 	"Compute",
 	"Network",
 	"Storage",
+	"Security",
 	"All",
 }
 
@@ -28,6 +29,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
+		if m.flash != "" {
+			m.setFlash("")
+		}
+
 		// Handle search mode
 		if m.searchMode {
 			switch msg.Type {
@@ -57,18 +62,190 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.commandMode {
+			return m.updateCommandMode(msg)
+		}
+		if m.prompt != nil {
+			return m.updatePrompt(msg)
+		}
+		if m.currentView == "runscript" {
+			return m.updateRunEditor(msg)
+		}
+		if m.currentView == "runresults" {
+			if cmd, ok := m.runOutput.update(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "bootlog" && !m.loading && m.err == nil {
+			if cmd, ok := m.handleBootLogKey(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "transfers" {
+			if cmd, ok := m.handleTransferKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "blobpreview" && !m.loading && m.err == nil {
+			if cmd, ok := m.preview.update(msg); ok {
+				return m, cmd
+			}
+		}
+		if (m.currentView == "lb" || m.currentView == "appgw") && !m.loading {
+			if cmd, ok := m.handleBalancerKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if (m.currentView == "vnet" || m.currentView == "lb" || m.currentView == "appgw") && !m.loading && m.err == nil {
+			if cmd, ok := m.topology.update(msg); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "sas" && !m.loading && m.err == nil {
+			if cmd, ok := m.handleSASViewKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "storage" && !m.loading {
+			if cmd, ok := m.handleBlobKey(msg.String()); ok {
+				return m, cmd
+			}
+			if cmd, ok := m.handleSASKey(msg.String()); ok {
+				return m, cmd
+			}
+			if cmd, ok := m.handleStorageKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "disks" && !m.loading {
+			if cmd, ok := m.handleDisksKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "pips" && !m.loading {
+			if cmd, ok := m.handlePublicIPsKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "privatelink" && !m.loading {
+			if cmd, ok := m.handlePrivateLinkKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if (m.currentView == "keyvault" || m.currentView == "vaultversions") && !m.loading {
+			if cmd, ok := m.handleKeyVaultKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "expiring" && !m.loading {
+			if cmd, ok := m.handleExpiringKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "effective" && !m.loading {
+			if cmd, ok := m.handleEffectiveKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "nsg" && !m.loading {
+			if cmd, ok := m.handleNSGKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "vmss" && !m.loading {
+			if cmd, ok := m.handleVMSSKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+		if m.currentView == "aks" && !m.loading {
+			if cmd, ok := m.handleAKSKey(msg.String()); ok {
+				return m, cmd
+			}
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
+		case ":":
+			m.commandMode = true
+			m.commandInput = ""
+			m.updateLayout(m.width, m.height)
+			return m, nil
 		case "/":
 			if m.currentView == "resources" && m.selectedResourceType != "" {
 				m.searchMode = true
 				m.searchQuery = ""
 				return m, nil
 			}
+		case "t":
+			if m.currentView == "subscriptions" {
+				m.currentView = "tenants"
+				m.loading = true
+				return m, azure.FetchTenants
+			}
+		case " ":
+			if m.currentView == "subscriptions" {
+				m.toggleMarkedSubscription()
+				return m, nil
+			}
+			if m.currentView == "resources" && m.toggleMarkedVM() {
+				return m, nil
+			}
+		case "a":
+			if len(m.markedSubs) > 0 && (m.currentView == "subscriptions" || (m.currentView == "resourcegroups" && m.multiSub)) {
+				m.multiSub = true
+				m.selectedRG = ""
+				clear(m.markedVMs)
+				m.currentView = "resources"
+				m.selectedResourceType = "All"
+				m.loading = true
+				return m, m.reloadResources()
+			}
+		case "K":
+			if m.currentView == "resources" && m.kubeconfigForResource() {
+				return m, nil
+			}
+		case "s":
+			if m.currentView == "resources" {
+				if cmd, ok := m.sshToResource(); ok {
+					return m, cmd
+				}
+			}
+			if m.currentView == "vm" && !m.loading && m.vm.VM.Name != nil {
+				return m, m.connectSSH(m.vmID, *m.vm.VM.Name)
+			}
+		case "e":
+			if m.currentView == "resources" {
+				if cmd, ok := m.effectiveForResource(); ok {
+					return m, cmd
+				}
+			}
+			if m.currentView == "vm" && !m.loading && m.vm.VM.Name != nil {
+				return m, m.effectiveForVM()
+			}
+		case "l":
+			if m.currentView == "resources" {
+				if resource, ok := m.selectedVM(); ok {
+					return m, m.openBootLog(*resource.ID, *resource.Name)
+				}
+			}
+			if m.currentView == "vm" && !m.loading && m.vm.VM.Name != nil {
+				return m, m.openBootLog(m.vmID, *m.vm.VM.Name)
+			}
 		case "enter":
 			switch m.currentView {
+			case "tenants":
+				selected := m.table.SelectedRow()
+				if len(selected) >= 1 {
+					return m, m.switchTenant(selected[0])
+				}
 			case "subscriptions":
+				if len(m.markedSubs) > 0 {
+					m.multiSub = true
+					m.currentView = "resourcegroups"
+					m.loading = true
+					return m, azure.FetchResourceGroupsMulti(m.markedSubscriptionIDs())
+				}
 				selected := m.table.SelectedRow()
 				if len(selected) >= 2 {
 					m.selectedSub = selected[1]
@@ -79,11 +256,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "resourcegroups":
 				selected := m.table.SelectedRow()
 				if len(selected) >= 1 {
+					if m.multiSub {
+						cursor := m.table.Cursor()
+						if cursor < 0 || cursor >= len(m.rowSubscriptions) {
+							return m, nil
+						}
+						m.selectedSub = m.rowSubscriptions[cursor]
+					}
 					m.selectedRG = selected[0]
+					clear(m.markedVMs)
 					m.currentView = "resources"
 					m.selectedResourceType = "All"
 					m.loading = true
-					return m, azure.FetchResources(m.selectedSub, m.selectedRG)
+					return m, m.reloadResources()
+				}
+			case "resources":
+				if cmd := m.openResource(); cmd != nil {
+					return m, cmd
 				}
 			}
 		case "right", "left":
@@ -114,7 +303,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 				if oldType != m.selectedResourceType {
 					m.loading = true
-					return m, azure.FetchResources(m.selectedSub, m.selectedRG)
+					return m, m.reloadResources()
 				}
 			}
 		case "1", "2", "3", "4", "5":
@@ -125,7 +314,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedResourceType = resourceTypes[idx]
 					if oldType != m.selectedResourceType {
 						m.loading = true
-						return m, azure.FetchResources(m.selectedSub, m.selectedRG)
+						return m, m.reloadResources()
 					}
 				}
 			}
@@ -137,17 +326,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.selectedResourceType = resourceTypes[idx]
 					if oldType != m.selectedResourceType {
 						m.loading = true
-						return m, azure.FetchResources(m.selectedSub, m.selectedRG)
+						return m, m.reloadResources()
 					}
 				}
 			}
 		case "esc":
+			m.err = nil
+			if m.previousView() != "" {
+				m.closeDetailView()
+				return m, nil
+			}
 			switch m.currentView {
+			case "tenants":
+				m.currentView = "subscriptions"
+				m.updateTableWithSubscriptions()
 			case "resourcegroups":
 				m.currentView = "subscriptions"
+				m.multiSub = false
+				m.subErrors = nil
 				m.updateTableWithSubscriptions()
 			case "resources":
 				m.currentView = "resourcegroups"
+				if m.multiSub && m.selectedRG == "" {
+					m.loading = true
+					return m, azure.FetchResourceGroupsMulti(m.markedSubscriptionIDs())
+				}
 				m.updateTableWithResourceGroups()
 			}
 		}
@@ -162,20 +365,277 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case azure.ErrorMsg:
+		m.loading = false
+		m.err = msg.Error
+		return m, nil
+
+	case azure.AuthPromptMsg:
+		m.authPrompt = msg.Message
+		return m, nil
+
+	case azure.IdentityMsg:
+		m.authPrompt = ""
+		m.header = fmt.Sprintf("%s • tenant %s • auth: %s", msg.Principal, msg.TenantID, msg.Method)
+		return m, nil
+
 	case azure.SubscriptionsMsg:
 		m.loading = false
+		m.err = nil
+		m.authPrompt = ""
 		m.subscriptions = msg.Subs
+		m.tenantID = msg.TenantID
+		m.subscriptionTenants = msg.Tenants
 		m.updateTableWithSubscriptions()
+		if m.header == "" {
+			// Resolve the identity only once signed in, so interactive
+			// methods never prompt twice.
+			return m, azure.FetchIdentity
+		}
 		return m, nil
 
 	case azure.ResourceGroupsMsg:
 		m.loading = false
+		m.err = nil
 		m.resourceGroups[msg.SubscriptionID] = msg.Groups
 		m.updateTableWithResourceGroups()
 		return m, nil
 
+	case azure.WhoamiMsg:
+		m.loading = false
+		m.err = nil
+		m.whoami = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.OperationUpdateMsg:
+		return m, m.applyOperationUpdate(msg)
+
+	case azure.KubeconfigMsg:
+		m.err = nil
+		m.flash = ""
+		m.offerLaunch(msg)
+		return m, nil
+
+	case azure.BootLogURIMsg:
+		if m.currentView != "bootlog" || msg.VMID != m.bootLogVM {
+			return m, nil
+		}
+		m.bootLogURI = msg.URI
+		return m, m.readBootLog()
+
+	case bootLogReadMsg:
+		return m, m.applyBootLog(msg)
+
+	case bootLogTickMsg:
+		if msg.gen != m.bootLogGen || m.currentView != "bootlog" {
+			return m, nil
+		}
+		return m, m.readBootLog()
+
+	case azure.RunCommandResultMsg:
+		return m, m.applyRunResult(msg)
+
+	case azure.SSHTargetMsg:
+		m.flash = ""
+		cmd, err := sshCommand(msg, m.cfg.SSHFor(msg.SubscriptionID))
+		if err != nil {
+			m.setFlash(err.Error())
+			return m, nil
+		}
+		return m, launch("ssh", cmd)
+
+	case execFinishedMsg:
+		if msg.err != nil {
+			m.setFlash(fmt.Sprintf("%s: %v", msg.name, msg.err))
+		}
+		return m, nil
+
+	case azure.AKSClusterMsg:
+		if m.currentView != "aks" || msg.ID != m.aksID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.aksCluster = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.TransferStartedMsg:
+		return m, m.applyTransferStarted(msg)
+
+	case azure.TransferUpdateMsg:
+		return m, m.applyTransferUpdate(msg)
+
+	case azure.SASMsg:
+		return m, m.applySAS(msg)
+
+	case azure.BlobPreviewMsg:
+		m.applyBlobPreview(msg)
+		return m, nil
+
+	case azure.StorageAccountMsg:
+		if m.currentView != "storage" || msg.Account.ID != m.storage.ID {
+			return m, nil
+		}
+		m.storage = msg.Account
+		return m, azure.ListStorage(m.storage, m.storagePath)
+
+	case azure.StorageListMsg:
+		if m.currentView != "storage" || msg.AccountID != m.storage.ID || msg.Path != m.storagePath {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.storageEntries = msg.Entries
+		m.storageErrors = msg.Errors
+		m.table.SetCursor(0)
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.DisksMsg:
+		if m.currentView != "disks" || msg.SubscriptionID != m.disksSub || msg.ResourceGroup != m.disksRG {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.disks = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.PublicIPsMsg:
+		if m.currentView != "pips" || msg.SubscriptionID != m.pipsSub || msg.ResourceGroup != m.pipsRG {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.pips = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.ExposureMsg:
+		if m.currentView != "pips" || msg.SubscriptionID != m.pipsSub || msg.ResourceGroup != m.pipsRG {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.exposure = &msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.PrivateLinkMsg:
+		m.applyPrivateLink(msg)
+		return m, nil
+
+	case azure.KeyVaultMsg:
+		m.applyKeyVault(msg)
+		return m, nil
+
+	case azure.VaultVersionsMsg:
+		m.applyVaultVersions(msg)
+		return m, nil
+
+	case azure.SecretValueMsg:
+		return m, m.applySecretValue(msg)
+
+	case secretHideMsg:
+		m.hideSecret(msg)
+		return m, nil
+
+	case azure.ExpiringMsg:
+		m.applyExpiring(msg)
+		return m, nil
+
+	case azure.EffectiveNetworkMsg:
+		m.applyEffective(msg)
+		return m, nil
+
+	case azure.VNetMsg:
+		m.applyVNet(msg)
+		return m, nil
+
+	case azure.LoadBalancerMsg:
+		m.applyLoadBalancer(msg)
+		return m, nil
+
+	case azure.AppGatewayMsg:
+		if m.currentView != "appgw" || msg.ID != m.appgwID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.appgw = msg
+		m.applyAppGateway()
+		return m, azure.FetchAppGatewayHealth(msg.ID)
+
+	case azure.AppGatewayHealthMsg:
+		if m.currentView != "appgw" || msg.ID != m.appgwID {
+			return m, nil
+		}
+		m.appgwHealth = &msg
+		m.applyAppGateway()
+		return m, nil
+
+	case azure.NSGMsg:
+		if m.currentView != "nsg" || msg.ID != m.nsgID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.nsg = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.VMSSMsg:
+		if m.currentView != "vmss" || msg.ID != m.vmssID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.vmss = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.VMMsg:
+		if m.currentView != "vm" || msg.ID != m.vmID {
+			return m, nil
+		}
+		m.loading = false
+		m.err = nil
+		m.vm = msg
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.TenantsMsg:
+		m.loading = false
+		m.err = nil
+		m.tenants = msg.Tenants
+		m.updateTableWithTenants()
+		return m, nil
+
+	case azure.MultiResourceGroupsMsg:
+		m.loading = false
+		m.err = nil
+		for subID, groups := range msg.Groups {
+			m.resourceGroups[subID] = groups
+		}
+		m.subErrors = msg.Errors
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
+	case azure.MultiResourcesMsg:
+		m.loading = false
+		m.err = nil
+		for subID, resources := range msg.Resources {
+			m.subResources[subID] = resources
+		}
+		m.subErrors = msg.Errors
+		m.updateLayout(m.width, m.height)
+		return m, nil
+
 	case azure.ResourcesMsg:
 		m.loading = false
+		m.err = nil
 		m.resources[m.selectedRG] = msg.Resources
 
 		// Find first tab that has resources
@@ -208,21 +668,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-var tabs = []string{"Clusters", "Compute", "Network", "Storage", "All"}
+var tabs = []string{"Clusters", "Compute", "Network", "Storage", "Security", "All"}
 
 func (m *Model) updateTableWithSubscriptions() {
 	// First clear the rows
 	m.table.SetRows([]table.Row{})
 
 	// Calculate responsive column widths
-	nameWidth := int(float64(m.width) * 0.4)  // 40% of width
-	idWidth := int(float64(m.width) * 0.4)    // 40% of width
-	stateWidth := int(float64(m.width) * 0.2) // 20% of width
+	nameWidth := int(float64(m.width) * 0.3)   // 30% of width
+	idWidth := int(float64(m.width) * 0.3)     // 30% of width
+	tenantWidth := int(float64(m.width) * 0.3) // 30% of width
+	stateWidth := int(float64(m.width) * 0.1)  // 10% of width
 
 	// Update columns
 	columns := []table.Column{
 		{Title: "Name", Width: nameWidth},
 		{Title: "ID", Width: idWidth},
+		{Title: "Tenant", Width: tenantWidth},
 		{Title: "State", Width: stateWidth},
 	}
 	m.table.SetColumns(columns)
@@ -230,9 +692,15 @@ func (m *Model) updateTableWithSubscriptions() {
 	// Set rows
 	var rows []table.Row
 	for _, sub := range m.subscriptions {
+		name := *sub.DisplayName
+		if m.markedSubs[*sub.SubscriptionID] {
+			name = markedPrefix + name
+		}
+		tenant := m.subscriptionTenants[*sub.SubscriptionID]
 		rows = append(rows, table.Row{
-			*sub.DisplayName,
+			name,
 			*sub.SubscriptionID,
+			orDash(&tenant),
 			string(*sub.State),
 		})
 	}
@@ -246,6 +714,11 @@ func (m *Model) updateTableWithResourceGroups() {
 	// First clear the rows
 	m.table.SetRows([]table.Row{})
 
+	if m.multiSub {
+		m.updateTableWithAggregatedResourceGroups()
+		return
+	}
+
 	// Calculate responsive column widths
 	nameWidth := int(float64(m.width) * 0.5)     // 50% of width
 	locationWidth := int(float64(m.width) * 0.3) // 30% of width
@@ -280,6 +753,11 @@ func (m *Model) updateTableWithResources() {
 	// First clear the rows
 	m.table.SetRows([]table.Row{})
 
+	if m.aggregatedResources() {
+		m.updateTableWithAggregatedResources()
+		return
+	}
+
 	// Calculate responsive column widths
 	nameWidth := int(float64(m.width) * 0.4)   // 40% of width
 	typeWidth := int(float64(m.width) * 0.4)   // 40% of width
@@ -295,6 +773,7 @@ func (m *Model) updateTableWithResources() {
 
 	// Set rows
 	var rows []table.Row
+	m.rowResources = nil
 	if resources, ok := m.resources[m.selectedRG]; ok {
 		for _, resource := range resources {
 			matchesTab := m.selectedResourceType == "All" || matchResourceType(*resource.Type, m.selectedResourceType)
@@ -306,10 +785,11 @@ func (m *Model) updateTableWithResources() {
 					resourceType = formatResourceType(resourceType)
 				}
 				rows = append(rows, table.Row{
-					*resource.Name,
+					m.resourceRowName(*resource.Name, resource.ID),
 					resourceType,
 					getResourceStatus(resource),
 				})
+				m.rowResources = append(m.rowResources, resource)
 			}
 		}
 	}
@@ -377,18 +857,24 @@ func matchResourceType(resourceType, tab string) bool {
 			strings.Contains(resourceType, "microsoft.container/containergroups")
 	case "Compute":
 		return strings.Contains(resourceType, "microsoft.compute/virtualmachines") ||
-			strings.Contains(resourceType, "microsoft.compute/vmscalesets") ||
-			strings.Contains(resourceType, "microsoft.compute/disks")
+			strings.Contains(resourceType, "microsoft.compute/virtualmachinescalesets") ||
+			strings.Contains(resourceType, "microsoft.compute/disks") ||
+			strings.Contains(resourceType, "microsoft.compute/snapshots")
 	case "Network":
 		return strings.Contains(resourceType, "microsoft.network/virtualnetworks") ||
 			strings.Contains(resourceType, "microsoft.network/networksecuritygroups") ||
+			strings.Contains(resourceType, "microsoft.network/networkinterfaces") ||
 			strings.Contains(resourceType, "microsoft.network/publicipaddresses") ||
 			strings.Contains(resourceType, "microsoft.network/loadbalancers") ||
-			strings.Contains(resourceType, "microsoft.network/applicationgateways")
+			strings.Contains(resourceType, "microsoft.network/applicationgateways") ||
+			strings.Contains(resourceType, "microsoft.network/privateendpoints") ||
+			strings.Contains(resourceType, "microsoft.network/privatednszones")
 	case "Storage":
 		return strings.Contains(resourceType, "microsoft.storage/storageaccounts") ||
 			strings.Contains(resourceType, "microsoft.storage/fileservices") ||
 			strings.Contains(resourceType, "microsoft.storage/blobservices")
+	case "Security":
+		return strings.Contains(resourceType, "microsoft.keyvault/vaults")
 	case "All":
 		return true // Show all resource types
 	default:
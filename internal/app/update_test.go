@@ -1,7 +1,11 @@
 package app
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/config"
 )
 
 func TestFormatResourceType(t *testing.T) {
@@ -61,6 +65,12 @@ func TestMatchResourceType(t *testing.T) {
 			tab:          "Compute",
 			expected:     true,
 		},
+		{
+			name:         "VM scale set in Compute tab",
+			resourceType: "Microsoft.Compute/virtualMachineScaleSets",
+			tab:          "Compute",
+			expected:     true,
+		},
 		{
 			name:         "Storage account in Storage tab",
 			resourceType: "Microsoft.Storage/storageAccounts",
@@ -73,6 +83,24 @@ func TestMatchResourceType(t *testing.T) {
 			tab:          "Network",
 			expected:     true,
 		},
+		{
+			name:         "Network interface in Network tab",
+			resourceType: "Microsoft.Network/networkInterfaces",
+			tab:          "Network",
+			expected:     true,
+		},
+		{
+			name:         "Private DNS zone in Network tab",
+			resourceType: "Microsoft.Network/privateDnsZones",
+			tab:          "Network",
+			expected:     true,
+		},
+		{
+			name:         "Key vault in Security tab",
+			resourceType: "Microsoft.KeyVault/vaults",
+			tab:          "Security",
+			expected:     true,
+		},
 		{
 			name:         "VM not in Network tab",
 			resourceType: "Microsoft.Compute/virtualMachines",
@@ -112,3 +140,29 @@ func TestGetResourceStatus(t *testing.T) {
 		}
 	})
 }
+
+func TestIdentityAndErrorMessages(t *testing.T) {
+	model := New(config.Config{})
+
+	updated, _ := model.Update(azure.AuthPromptMsg{Message: "enter code ABC"})
+	model = updated.(Model)
+	if model.authPrompt != "enter code ABC" {
+		t.Errorf("authPrompt = %q, want device code instructions", model.authPrompt)
+	}
+
+	updated, _ = model.Update(azure.IdentityMsg{Method: "devicecode", Principal: "jane@contoso.com", TenantID: "tenant-1"})
+	model = updated.(Model)
+	want := "jane@contoso.com • tenant tenant-1 • auth: devicecode"
+	if model.header != want {
+		t.Errorf("header = %q, want %q", model.header, want)
+	}
+	if model.authPrompt != "" {
+		t.Error("authPrompt not cleared after sign-in")
+	}
+
+	updated, _ = model.Update(azure.ErrorMsg{Error: errors.New("forbidden")})
+	model = updated.(Model)
+	if model.loading || model.err == nil {
+		t.Errorf("ErrorMsg left loading = %v, err = %v", model.loading, model.err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
 )
 
 func (m Model) View() string {
@@ -18,6 +19,9 @@ func (m Model) View() string {
 	// Show context information in resources view
 	if m.currentView == "resources" {
 		contextInfo := fmt.Sprintf("Subscription: %s | Resource Group: %s", m.selectedSub, m.selectedRG)
+		if m.aggregatedResources() {
+			contextInfo = fmt.Sprintf("Subscriptions: %d selected | Resource Group: all", len(m.markedSubs))
+		}
 		sb.WriteString(styles.HeaderStyle.Render(contextInfo))
 		sb.WriteString("\n\n")
 
@@ -50,32 +54,132 @@ func (m Model) View() string {
 		}
 	}
 
+	if m.commandMode {
+		sb.WriteString(styles.SearchStyle.Render(fmt.Sprintf(":%s█", m.commandInput)))
+		sb.WriteString("\n\n")
+	}
+	if m.prompt != nil {
+		sb.WriteString(styles.SearchStyle.Render(m.prompt.View()))
+		sb.WriteString("\n\n")
+	}
+
 	// Content
 	if m.loading {
 		sb.WriteString(m.spinner.View())
 		sb.WriteString(" Loading...")
+		if m.authPrompt != "" {
+			sb.WriteString("\n\n")
+			sb.WriteString(styles.SearchStyle.Render(m.authPrompt))
+		}
 	} else if m.err != nil {
 		sb.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
 	} else {
-		sb.WriteString(m.table.View())
+		if m.multiSub {
+			for _, warning := range m.subscriptionWarnings() {
+				sb.WriteString(styles.WarningStyle.Render("⚠ " + warning))
+				sb.WriteString("\n")
+			}
+		}
+		if len(m.properties) > 0 {
+			sb.WriteString(ui.RenderProperties(m.properties))
+			sb.WriteString("\n\n")
+		}
+		switch m.currentView {
+		case "bootlog":
+			sb.WriteString(m.bootLog.view())
+		case "runscript":
+			sb.WriteString(m.runEditor.View())
+		case "runresults":
+			sb.WriteString(m.runOutput.view())
+		case "blobpreview":
+			sb.WriteString(m.preview.view())
+		case "vnet", "lb", "appgw":
+			sb.WriteString(m.topology.view())
+		case "sas":
+			// Everything is in the properties above.
+		default:
+			sb.WriteString(m.table.View())
+		}
 	}
 
 	// Footer
 	sb.WriteString("\n")
-	footerText := "q: quit"
+	if m.flash != "" {
+		sb.WriteString(styles.WarningStyle.Render(m.flash))
+		sb.WriteString("\n")
+	}
+	footerText := "q: quit • :: command"
 	switch m.currentView {
 	case "subscriptions":
-		footerText += " • enter: select subscription"
+		footerText += " • enter: select subscription • space: mark • t: switch tenant"
+		if len(m.markedSubs) > 0 {
+			footerText += fmt.Sprintf(" • enter/a: groups/resources of %d marked", len(m.markedSubs))
+		}
+	case "tenants":
+		footerText += " • enter: switch to tenant • esc: back to subscriptions"
+	case "aks":
+		footerText += " • K: kubeconfig • s: start/stop • u: upgrade • c: scale pool • a: autoscaler • i: node image • esc: back"
+	case "vm":
+		footerText += " • s: ssh • l: serial log • e: effective rules and routes • esc: back"
+	case "vmss":
+		footerText += " • c: capacity • r: restart • e: reimage • u: upgrade to latest model • esc: back"
+	case "storage":
+		footerText += " • enter: open • esc/backspace: up • r: reload • d: download • u: upload • p: preview blob • s: SAS • f: filter table entities"
+	case "sas":
+		footerText += " • c: copy URL or connection string • t: copy token • esc: back"
+	case "transfers":
+		footerText += " • esc: cancel transfer, or back once it is over"
+	case "blobpreview":
+		footerText += " • /: search • n/N: next/previous match • g/G: top/bottom • esc: back"
+	case "pips":
+		footerText += " • x: exposure report/inventory • esc: back"
+	case "privatelink":
+		footerText += " • tab: endpoints/DNS zones • enter: zone records • esc: back"
+	case "dnszone":
+		footerText += " • esc: back"
+	case "keyvault":
+		footerText += " • tab: secrets/keys/certificates • enter: versions • v: reveal secret • c: copy secret or ID • r: reload • esc: back"
+	case "vaultversions":
+		footerText += " • v: reveal secret version • c: copy secret or ID • esc: back"
+	case "expiring":
+		footerText += " • enter: open key vault • r: scan again • esc: back"
+	case "effective":
+		footerText += " • tab: security rules/routes • esc: back"
+	case "lb":
+		footerText += " • r: reload health • /: search • n/N: next/previous match • g/G: top/bottom • esc: back"
+	case "appgw":
+		footerText += " • r: probe backends again • /: search • n/N: next/previous match • g/G: top/bottom • esc: back"
+	case "vnet":
+		footerText += " • /: search • n/N: next/previous match • g/G: top/bottom • esc: back"
+	case "nsg":
+		footerText += " • t: toggle default rules • a: add rule • e: edit rule • d: delete rule • esc: back"
+	case "disks":
+		footerText += " • u: unattached only • p: snapshot disk • r: resize disk • n: disk from snapshot • esc: back"
+	case "runscript":
+		footerText = "ctrl+s: run on the listed VMs • esc: cancel"
+	case "runresults":
+		footerText += " • /: search • n/N: next/previous match • esc: back"
+	case "bootlog":
+		footerText += " • /: search • n/N: next/previous match • f: follow • g/G: top/bottom • esc: back"
+	case "whoami", "operations":
+		footerText += " • esc: back"
 	case "resourcegroups":
 		footerText += " • enter: view resources • esc: back to subscriptions"
+		if m.multiSub {
+			footerText += " • a: all resources"
+		}
 	case "resources":
 		if m.searchMode {
 			footerText += " • enter: finish search • esc: cancel search"
 		} else {
-			footerText += " • ←/→ or 1-5: switch resource type • /: search • esc: back to resource groups"
+			footerText += " • enter: details • K: AKS kubeconfig • s/l: VM ssh/serial log • e: effective rules of VM/NIC • space: mark VM for :run • ←/→ or 1-6: switch resource type • /: search • esc: back to resource groups"
 		}
 	}
 
+	if running := m.runningOperations(); running > 0 {
+		footerText += fmt.Sprintf(" • %d operation(s) running (:ops)", running)
+	}
+
 	sb.WriteString(styles.FooterStyle.Render(footerText))
 
 	return sb.String()
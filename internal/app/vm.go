@@ -0,0 +1,235 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+func (m *Model) openVM(resourceID string) tea.Cmd {
+	m.openDetailView("vm")
+	m.vmID = resourceID
+	return azure.FetchVM(resourceID)
+}
+
+// vmPowerState returns the power state reported in the VM's instance view,
+// e.g. "VM running".
+func vmPowerState(vm armcompute.VirtualMachine) string {
+	if vm.Properties == nil || vm.Properties.InstanceView == nil {
+		return "-"
+	}
+	return powerState(vm.Properties.InstanceView.Statuses)
+}
+
+// powerState picks the PowerState/* entry from instance view statuses.
+func powerState(statuses []*armcompute.InstanceViewStatus) string {
+	for _, status := range statuses {
+		if status != nil && status.Code != nil && strings.HasPrefix(*status.Code, "PowerState/") {
+			if status.DisplayStatus != nil {
+				return *status.DisplayStatus
+			}
+			return strings.TrimPrefix(*status.Code, "PowerState/")
+		}
+	}
+	return "-"
+}
+
+// vmImage describes the image a VM was created from: a marketplace URN or
+// the name of a custom or gallery image.
+func vmImage(image *armcompute.ImageReference) string {
+	if image == nil {
+		return "-"
+	}
+	if image.Publisher != nil && image.Offer != nil && image.SKU != nil {
+		version := orDash(image.ExactVersion)
+		if version == "-" {
+			version = orDash(image.Version)
+		}
+		return strings.Join([]string{*image.Publisher, *image.Offer, *image.SKU, version}, ":")
+	}
+	for _, id := range []*string{image.ID, image.SharedGalleryImageID, image.CommunityGalleryImageID} {
+		if id != nil {
+			return resourceName(*id)
+		}
+	}
+	return "-"
+}
+
+// resourceName returns the last segment of a resource ID, or the ID itself
+// when it cannot be parsed.
+func resourceName(resourceID string) string {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return resourceID
+	}
+	return id.Name
+}
+
+// vmBootDiagnostics reports whether boot diagnostics are enabled and where
+// they are stored.
+func vmBootDiagnostics(props *armcompute.VirtualMachineProperties) string {
+	if props.DiagnosticsProfile == nil || props.DiagnosticsProfile.BootDiagnostics == nil {
+		return "disabled"
+	}
+	diagnostics := props.DiagnosticsProfile.BootDiagnostics
+	if diagnostics.Enabled == nil || !*diagnostics.Enabled {
+		return "disabled"
+	}
+	if diagnostics.StorageURI == nil || *diagnostics.StorageURI == "" {
+		return "enabled (managed storage)"
+	}
+	return "enabled (" + *diagnostics.StorageURI + ")"
+}
+
+// vmIdentity lists the managed identities assigned to a VM.
+func vmIdentity(identity *armcompute.VirtualMachineIdentity) string {
+	if identity == nil || identity.Type == nil || *identity.Type == armcompute.ResourceIdentityTypeNone {
+		return "none"
+	}
+	var parts []string
+	if identity.PrincipalID != nil {
+		parts = append(parts, "system-assigned "+*identity.PrincipalID)
+	}
+	var users []string
+	for id := range identity.UserAssignedIdentities {
+		users = append(users, resourceName(id))
+	}
+	sort.Strings(users)
+	if len(users) > 0 {
+		parts = append(parts, "user-assigned "+strings.Join(users, ", "))
+	}
+	if len(parts) == 0 {
+		return string(*identity.Type)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// formatVMAddresses lists private and public IPs, primary configuration first.
+func formatVMAddresses(addresses []azure.VMAddress) (private, public string) {
+	sorted := append([]azure.VMAddress(nil), addresses...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Primary && !sorted[j].Primary })
+
+	var privateIPs, publicIPs []string
+	for _, address := range sorted {
+		if address.PrivateIP != "" {
+			privateIPs = append(privateIPs, fmt.Sprintf("%s (%s, %s)", address.PrivateIP, address.NIC, orDash(&address.Subnet)))
+		}
+		switch {
+		case address.PublicIP != "":
+			publicIPs = append(publicIPs, address.PublicIP)
+		case address.PublicIPID != "":
+			publicIPs = append(publicIPs, resourceName(address.PublicIPID)+" (unallocated)")
+		}
+	}
+	private, public = "-", "none"
+	if len(privateIPs) > 0 {
+		private = strings.Join(privateIPs, ", ")
+	}
+	if len(publicIPs) > 0 {
+		public = strings.Join(publicIPs, ", ")
+	}
+	return private, public
+}
+
+func (m *Model) updateTableWithVM() {
+	vm := m.vm.VM
+
+	m.properties = []ui.Property{{Key: "Virtual machine", Value: orDash(vm.Name)}}
+	if props := vm.Properties; props != nil {
+		size, image, osType := "-", "-", "-"
+		if props.HardwareProfile != nil {
+			size = orDash(props.HardwareProfile.VMSize)
+		}
+		if props.StorageProfile != nil {
+			image = vmImage(props.StorageProfile.ImageReference)
+			if props.StorageProfile.OSDisk != nil {
+				osType = orDash(props.StorageProfile.OSDisk.OSType)
+			}
+		}
+		zone := "none"
+		if len(vm.Zones) > 0 {
+			zone = joinOrDash(vm.Zones)
+		}
+		private, public := formatVMAddresses(m.vm.Addresses)
+		if m.vm.NetworkErr != nil {
+			private += fmt.Sprintf(" (some interfaces failed to load: %v)", m.vm.NetworkErr)
+		}
+
+		m.properties = append(m.properties,
+			ui.Property{Key: "Size", Value: size},
+			ui.Property{Key: "Image", Value: image},
+			ui.Property{Key: "OS", Value: osType},
+			ui.Property{Key: "Power state", Value: vmPowerState(vm)},
+			ui.Property{Key: "Availability zone", Value: zone},
+			ui.Property{Key: "Private IPs", Value: private},
+			ui.Property{Key: "Public IPs", Value: public},
+			ui.Property{Key: "Boot diagnostics", Value: vmBootDiagnostics(props)},
+			ui.Property{Key: "Managed identity", Value: vmIdentity(vm.Identity)},
+		)
+	}
+
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.4)     // 40% of width
+	roleWidth := int(float64(m.width) * 0.15)    // 15% of width
+	sizeWidth := int(float64(m.width) * 0.1)     // 10% of width
+	skuWidth := int(float64(m.width) * 0.2)      // 20% of width
+	cachingWidth := int(float64(m.width) * 0.15) // 15% of width
+
+	columns := []table.Column{
+		{Title: "Disk", Width: nameWidth},
+		{Title: "Role", Width: roleWidth},
+		{Title: "Size (GB)", Width: sizeWidth},
+		{Title: "SKU", Width: skuWidth},
+		{Title: "Caching", Width: cachingWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	if vm.Properties != nil && vm.Properties.StorageProfile != nil {
+		storage := vm.Properties.StorageProfile
+		if disk := storage.OSDisk; disk != nil {
+			rows = append(rows, table.Row{
+				orDash(disk.Name),
+				"OS",
+				intOrDash(disk.DiskSizeGB),
+				managedDiskSKU(disk.ManagedDisk),
+				orDash(disk.Caching),
+			})
+		}
+		for _, disk := range storage.DataDisks {
+			if disk == nil {
+				continue
+			}
+			rows = append(rows, table.Row{
+				orDash(disk.Name),
+				"Data, LUN " + intOrDash(disk.Lun),
+				intOrDash(disk.DiskSizeGB),
+				managedDiskSKU(disk.ManagedDisk),
+				orDash(disk.Caching),
+			})
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No disks", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// managedDiskSKU returns the storage type of a managed disk, or "unmanaged"
+// for VHD-based disks.
+func managedDiskSKU(disk *armcompute.ManagedDiskParameters) string {
+	if disk == nil {
+		return "unmanaged"
+	}
+	return orDash(disk.StorageAccountType)
+}
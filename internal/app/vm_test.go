@@ -0,0 +1,152 @@
+package app
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testVMID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/virtualMachines/vm1"
+
+func newTestVM() armcompute.VirtualMachine {
+	return armcompute.VirtualMachine{
+		ID:    to.Ptr(testVMID),
+		Name:  to.Ptr("vm1"),
+		Zones: []*string{to.Ptr("2")},
+		Identity: &armcompute.VirtualMachineIdentity{
+			Type:        to.Ptr(armcompute.ResourceIdentityTypeSystemAssignedUserAssigned),
+			PrincipalID: to.Ptr("0000-1111"),
+			UserAssignedIdentities: map[string]*armcompute.UserAssignedIdentitiesValue{
+				"/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.ManagedIdentity/userAssignedIdentities/id-app": {},
+			},
+		},
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypesStandardD2SV3)},
+			StorageProfile: &armcompute.StorageProfile{
+				ImageReference: &armcompute.ImageReference{
+					Publisher:    to.Ptr("Canonical"),
+					Offer:        to.Ptr("0001-com-ubuntu-server-jammy"),
+					SKU:          to.Ptr("22_04-lts-gen2"),
+					Version:      to.Ptr("latest"),
+					ExactVersion: to.Ptr("22.04.202404090"),
+				},
+				OSDisk: &armcompute.OSDisk{
+					Name:        to.Ptr("vm1-os"),
+					OSType:      to.Ptr(armcompute.OperatingSystemTypesLinux),
+					DiskSizeGB:  to.Ptr[int32](30),
+					Caching:     to.Ptr(armcompute.CachingTypesReadWrite),
+					ManagedDisk: &armcompute.ManagedDiskParameters{StorageAccountType: to.Ptr(armcompute.StorageAccountTypesPremiumLRS)},
+				},
+				DataDisks: []*armcompute.DataDisk{
+					{Name: to.Ptr("vm1-data"), Lun: to.Ptr[int32](0), DiskSizeGB: to.Ptr[int32](128)},
+				},
+			},
+			DiagnosticsProfile: &armcompute.DiagnosticsProfile{
+				BootDiagnostics: &armcompute.BootDiagnostics{Enabled: to.Ptr(true)},
+			},
+			InstanceView: &armcompute.VirtualMachineInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{
+					{Code: to.Ptr("ProvisioningState/succeeded"), DisplayStatus: to.Ptr("Provisioning succeeded")},
+					{Code: to.Ptr("PowerState/running"), DisplayStatus: to.Ptr("VM running")},
+				},
+			},
+		},
+	}
+}
+
+func propertyValue(t *testing.T, model Model, key string) string {
+	t.Helper()
+	for _, property := range model.properties {
+		if property.Key == key {
+			return property.Value
+		}
+	}
+	t.Fatalf("no property %q in %v", key, model.properties)
+	return ""
+}
+
+func TestOpenVM(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+
+	updated, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model = updated.(Model)
+	if cmd == nil || model.currentView != "vm" || model.vmID != testVMID {
+		t.Fatalf("enter on VM row opened %q (%s)", model.currentView, model.vmID)
+	}
+
+	// A reply for another VM, e.g. one opened and left just before, is
+	// dropped.
+	updated, _ = model.Update(azure.VMMsg{ID: testVMID + "-old", VM: armcompute.VirtualMachine{Name: to.Ptr("vm-old")}})
+	model = updated.(Model)
+	if !model.loading || model.vm.VM.Name != nil {
+		t.Fatal("reply for another VM was applied")
+	}
+
+	updated, _ = model.Update(azure.VMMsg{
+		ID: testVMID,
+		VM: newTestVM(),
+		Addresses: []azure.VMAddress{
+			{NIC: "vm1-nic2", PrivateIP: "10.0.1.5", Subnet: "vnet/backend"},
+			{NIC: "vm1-nic", Primary: true, PrivateIP: "10.0.0.4", PublicIP: "20.1.2.3", Subnet: "vnet/default"},
+		},
+	})
+	model = updated.(Model)
+
+	checks := map[string]string{
+		"Size":              "Standard_D2s_v3",
+		"Image":             "Canonical:0001-com-ubuntu-server-jammy:22_04-lts-gen2:22.04.202404090",
+		"OS":                "Linux",
+		"Power state":       "VM running",
+		"Availability zone": "2",
+		"Private IPs":       "10.0.0.4 (vm1-nic, vnet/default), 10.0.1.5 (vm1-nic2, vnet/backend)",
+		"Public IPs":        "20.1.2.3",
+		"Boot diagnostics":  "enabled (managed storage)",
+		"Managed identity":  "system-assigned 0000-1111; user-assigned id-app",
+	}
+	for key, want := range checks {
+		if got := propertyValue(t, model, key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	rows := model.table.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("got %d disk rows, want 2", len(rows))
+	}
+	if rows[0][1] != "OS" || rows[0][3] != "Premium_LRS" {
+		t.Errorf("OS disk row = %v", rows[0])
+	}
+	if rows[1][1] != "Data, LUN 0" || rows[1][3] != "unmanaged" {
+		t.Errorf("data disk row = %v", rows[1])
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEsc})
+	if model.currentView != "resources" {
+		t.Errorf("esc from VM view went to %q", model.currentView)
+	}
+}
+
+func TestVMNetworkErrorStillShowsVM(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMID), Name: to.Ptr("vm1"), Type: to.Ptr("Microsoft.Compute/virtualMachines")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+
+	updated, _ := model.Update(azure.VMMsg{ID: testVMID, VM: newTestVM(), NetworkErr: errors.New("AuthorizationFailed")})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Private IPs"); !strings.Contains(got, "AuthorizationFailed") {
+		t.Errorf("Private IPs = %q, want the network error", got)
+	}
+	if got := propertyValue(t, model, "Power state"); got != "VM running" {
+		t.Errorf("Power state = %q, want VM running", got)
+	}
+}
@@ -0,0 +1,189 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+func (m *Model) openVMSS(resourceID string) tea.Cmd {
+	m.openDetailView("vmss")
+	m.vmssID = resourceID
+	return azure.FetchVMSS(resourceID)
+}
+
+func (m *Model) updateTableWithVMSS() {
+	scaleSet := m.vmss.ScaleSet
+
+	onLatest := 0
+	for _, instance := range m.vmss.Instances {
+		if instance != nil && instance.Properties != nil && instance.Properties.LatestModelApplied != nil && *instance.Properties.LatestModelApplied {
+			onLatest++
+		}
+	}
+
+	sku, capacity := "-", "-"
+	if scaleSet.SKU != nil {
+		sku = orDash(scaleSet.SKU.Name)
+		capacity = intOrDash(scaleSet.SKU.Capacity)
+	}
+	zones := "none"
+	if len(scaleSet.Zones) > 0 {
+		zones = joinOrDash(scaleSet.Zones)
+	}
+	m.properties = []ui.Property{
+		{Key: "Scale set", Value: orDash(scaleSet.Name)},
+		{Key: "SKU", Value: sku},
+		{Key: "Capacity", Value: capacity},
+		{Key: "Instances", Value: fmt.Sprintf("%d (%d on latest model)", len(m.vmss.Instances), onLatest)},
+		{Key: "Availability zones", Value: zones},
+	}
+	if props := scaleSet.Properties; props != nil {
+		upgradeMode := "-"
+		if props.UpgradePolicy != nil {
+			upgradeMode = orDash(props.UpgradePolicy.Mode)
+		}
+		m.properties = append(m.properties,
+			ui.Property{Key: "Orchestration mode", Value: orDash(props.OrchestrationMode)},
+			ui.Property{Key: "Upgrade policy", Value: upgradeMode},
+			ui.Property{Key: "Provisioning state", Value: orDash(props.ProvisioningState)},
+		)
+	}
+
+	m.table.SetRows([]table.Row{})
+
+	nameWidth := int(float64(m.width) * 0.3)    // 30% of width
+	idWidth := int(float64(m.width) * 0.08)     // 8% of width
+	powerWidth := int(float64(m.width) * 0.17)  // 17% of width
+	latestWidth := int(float64(m.width) * 0.12) // 12% of width
+	stateWidth := int(float64(m.width) * 0.15)  // 15% of width
+	zoneWidth := int(float64(m.width) * 0.08)   // 8% of width
+	sizeWidth := int(float64(m.width) * 0.1)    // 10% of width
+
+	columns := []table.Column{
+		{Title: "Instance", Width: nameWidth},
+		{Title: "ID", Width: idWidth},
+		{Title: "Power State", Width: powerWidth},
+		{Title: "Latest Model", Width: latestWidth},
+		{Title: "Provisioning", Width: stateWidth},
+		{Title: "Zone", Width: zoneWidth},
+		{Title: "Size", Width: sizeWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, instance := range m.vmss.Instances {
+		if instance == nil {
+			continue
+		}
+		power, latest, provisioning := "-", "-", "-"
+		if props := instance.Properties; props != nil {
+			if props.InstanceView != nil {
+				power = powerState(props.InstanceView.Statuses)
+			}
+			if props.LatestModelApplied != nil {
+				latest = yesNo(props.LatestModelApplied)
+			}
+			provisioning = orDash(props.ProvisioningState)
+		}
+		size := "-"
+		if instance.SKU != nil {
+			size = orDash(instance.SKU.Name)
+		}
+		rows = append(rows, table.Row{
+			orDash(instance.Name),
+			orDash(instance.InstanceID),
+			power,
+			latest,
+			provisioning,
+			joinOrDash(instance.Zones),
+			size,
+		})
+	}
+	if len(rows) == 0 {
+		rows = append(rows, table.Row{"No instances", "-", "-", "-", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
+
+// selectedInstance returns the scale set instance under the cursor.
+func (m Model) selectedInstance() (*armcompute.VirtualMachineScaleSetVM, bool) {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.vmss.Instances) || m.vmss.Instances[cursor] == nil {
+		return nil, false
+	}
+	return m.vmss.Instances[cursor], true
+}
+
+// handleVMSSKey runs scale set and instance actions from the VMSS view. It
+// reports whether the key was an action.
+func (m *Model) handleVMSSKey(key string) (tea.Cmd, bool) {
+	scaleSet := m.vmss.ScaleSet
+	if scaleSet.Name == nil {
+		return nil, false
+	}
+	scaleSetID := m.vmssID
+	scaleSetName := *scaleSet.Name
+
+	if key == "c" {
+		initial := ""
+		if scaleSet.SKU != nil {
+			initial = intOrDash(scaleSet.SKU.Capacity)
+		}
+		m.askValue(fmt.Sprintf("Scale %s to instance count:", scaleSetName), initial, func(m *Model, value string) tea.Cmd {
+			capacity, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil || capacity < 0 {
+				m.setFlash(fmt.Sprintf("invalid instance count %q", value))
+				return nil
+			}
+			m.askConfirm(fmt.Sprintf("Scale %s to %d instances?", scaleSetName, capacity), func(m *Model) tea.Cmd {
+				return m.startOperation(fmt.Sprintf("Scale %s to %d instances", scaleSetName, capacity), func(id int) tea.Cmd {
+					return azure.ScaleVMSS(id, scaleSetID, capacity)
+				})
+			})
+			return nil
+		})
+		return nil, true
+	}
+
+	instance, ok := m.selectedInstance()
+	if !ok || instance.InstanceID == nil {
+		return nil, false
+	}
+	instanceID := *instance.InstanceID
+	target := fmt.Sprintf("%s instance %s", scaleSetName, instanceID)
+
+	var (
+		question    string
+		description string
+		start       func(id int) tea.Cmd
+	)
+	switch key {
+	case "r":
+		question = fmt.Sprintf("Restart %s?", target)
+		description = "Restart " + target
+		start = func(id int) tea.Cmd { return azure.RestartVMSSInstance(id, scaleSetID, instanceID) }
+	case "e":
+		question = fmt.Sprintf("Reimage %s? Its OS disk is replaced with a fresh one.", target)
+		description = "Reimage " + target
+		start = func(id int) tea.Cmd { return azure.ReimageVMSSInstance(id, scaleSetID, instanceID) }
+	case "u":
+		question = fmt.Sprintf("Upgrade %s to the latest model?", target)
+		description = "Upgrade " + target + " to the latest model"
+		start = func(id int) tea.Cmd { return azure.UpgradeVMSSInstance(id, scaleSetID, instanceID) }
+	default:
+		return nil, false
+	}
+	m.askConfirm(question, func(m *Model) tea.Cmd {
+		return m.startOperation(description, start)
+	})
+	return nil, true
+}
@@ -0,0 +1,132 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testVMSSID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Compute/virtualMachineScaleSets/vmss1"
+
+func newTestVMSSInstance(id string, latest bool, power string) *armcompute.VirtualMachineScaleSetVM {
+	return &armcompute.VirtualMachineScaleSetVM{
+		Name:       to.Ptr("vmss1_" + id),
+		InstanceID: to.Ptr(id),
+		Zones:      []*string{to.Ptr("1")},
+		SKU:        &armcompute.SKU{Name: to.Ptr("Standard_B2s")},
+		Properties: &armcompute.VirtualMachineScaleSetVMProperties{
+			LatestModelApplied: to.Ptr(latest),
+			ProvisioningState:  to.Ptr("Succeeded"),
+			InstanceView: &armcompute.VirtualMachineScaleSetVMInstanceView{
+				Statuses: []*armcompute.InstanceViewStatus{{Code: to.Ptr("PowerState/" + power), DisplayStatus: to.Ptr("VM " + power)}},
+			},
+		},
+	}
+}
+
+func newVMSSModel(t *testing.T) Model {
+	t.Helper()
+
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVMSSID), Name: to.Ptr("vmss1"), Type: to.Ptr("Microsoft.Compute/virtualMachineScaleSets")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "vmss" || model.vmssID != testVMSSID {
+		t.Fatalf("enter on scale set row opened %q (%s)", model.currentView, model.vmssID)
+	}
+
+	// A reply for a scale set that was opened and left before is dropped.
+	updated, _ := model.Update(azure.VMSSMsg{ID: testVMSSID + "-old"})
+	model = updated.(Model)
+	if !model.loading {
+		t.Fatal("reply for another scale set was applied")
+	}
+
+	updated, _ = model.Update(azure.VMSSMsg{
+		ID: testVMSSID,
+		ScaleSet: armcompute.VirtualMachineScaleSet{
+			ID:   to.Ptr(testVMSSID),
+			Name: to.Ptr("vmss1"),
+			SKU:  &armcompute.SKU{Name: to.Ptr("Standard_B2s"), Capacity: to.Ptr[int64](2)},
+			Properties: &armcompute.VirtualMachineScaleSetProperties{
+				UpgradePolicy: &armcompute.UpgradePolicy{Mode: to.Ptr(armcompute.UpgradeModeManual)},
+			},
+		},
+		Instances: []*armcompute.VirtualMachineScaleSetVM{
+			newTestVMSSInstance("0", true, "running"),
+			newTestVMSSInstance("3", false, "deallocated"),
+		},
+	})
+	return updated.(Model)
+}
+
+func TestOpenVMSS(t *testing.T) {
+	model := newVMSSModel(t)
+
+	if got := propertyValue(t, model, "Instances"); got != "2 (1 on latest model)" {
+		t.Errorf("Instances = %q", got)
+	}
+	if got := propertyValue(t, model, "Upgrade policy"); got != "Manual" {
+		t.Errorf("Upgrade policy = %q, want Manual", got)
+	}
+
+	rows := model.table.Rows()
+	if len(rows) != 2 {
+		t.Fatalf("got %d instance rows, want 2", len(rows))
+	}
+	if rows[1][1] != "3" || rows[1][2] != "VM deallocated" || rows[1][3] != "no" {
+		t.Errorf("second instance row = %v", rows[1])
+	}
+}
+
+func TestVMSSInstanceActions(t *testing.T) {
+	model := newVMSSModel(t)
+	model.table.SetCursor(1)
+
+	for key, want := range map[string]string{
+		"r": "Restart vmss1 instance 3?",
+		"e": "Reimage vmss1 instance 3?",
+		"u": "Upgrade vmss1 instance 3 to the latest model?",
+	} {
+		next, _ := pressKeys(model, runes(key))
+		if next.prompt == nil || !next.prompt.confirm || !strings.HasPrefix(next.prompt.question, want) {
+			t.Errorf("%s prompted %+v, want %q", key, next.prompt, want)
+		}
+	}
+
+	model, _ = pressKeys(model, runes("u"))
+	model, cmd := pressKeys(model, runes("y"))
+	if cmd == nil || len(model.operations) != 1 {
+		t.Fatal("confirming the upgrade did not start an operation")
+	}
+
+	updated, cmd := model.Update(azure.OperationUpdateMsg{ID: 1, Status: "Succeeded", Done: true})
+	model = updated.(Model)
+	if cmd == nil {
+		t.Error("finished operation did not refresh the scale set")
+	}
+}
+
+func TestScaleVMSS(t *testing.T) {
+	model := newVMSSModel(t)
+
+	model, _ = pressKeys(model, runes("c"))
+	if model.prompt == nil || model.prompt.input.Value() != "2" {
+		t.Fatalf("c did not prompt with the current capacity")
+	}
+
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyBackspace}, runes("x"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt != nil || !strings.Contains(model.flash, "invalid instance count") {
+		t.Errorf("invalid count: prompt = %+v, flash = %q", model.prompt, model.flash)
+	}
+
+	model, _ = pressKeys(model, runes("c"), tea.KeyMsg{Type: tea.KeyBackspace}, runes("5"), tea.KeyMsg{Type: tea.KeyEnter})
+	if model.prompt == nil || model.prompt.question != "Scale vmss1 to 5 instances? (y/n)" {
+		t.Fatalf("expected confirmation for 5 instances, got %+v", model.prompt)
+	}
+}
@@ -0,0 +1,246 @@
+package app
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// connectedKinds names the resources that commonly hold IP configurations,
+// in a subnet or bound to a public IP.
+var connectedKinds = map[string]string{
+	"microsoft.network/networkinterfaces":       "network interface",
+	"microsoft.network/loadbalancers":           "load balancer",
+	"microsoft.network/applicationgateways":     "application gateway",
+	"microsoft.network/virtualnetworkgateways":  "virtual network gateway",
+	"microsoft.network/bastionhosts":            "Bastion host",
+	"microsoft.network/azurefirewalls":          "firewall",
+	"microsoft.network/privateendpoints":        "private endpoint",
+	"microsoft.network/privatelinkservices":     "private link service",
+	"microsoft.network/natgateways":             "NAT gateway",
+	"microsoft.compute/virtualmachinescalesets": "scale set",
+}
+
+// attachedResource returns the top-level resource that a child resource such
+// as an IP configuration belongs to, as a lowercase key and a label naming
+// it and its kind.
+func attachedResource(resourceID string) (string, string, bool) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return "", "", false
+	}
+	for id.Parent != nil && len(id.ResourceType.Types) > 1 {
+		id = id.Parent
+	}
+	kind, ok := connectedKinds[strings.ToLower(id.ResourceType.String())]
+	if !ok {
+		kind = id.ResourceType.String()
+	}
+	return strings.ToLower(id.String()), fmt.Sprintf("%s (%s)", id.Name, kind), true
+}
+
+func (m *Model) openVNet(resourceID string) tea.Cmd {
+	m.openDetailView("vnet")
+	m.vnetID = resourceID
+	m.topology = newTextPane()
+	m.updateLayout(m.width, m.height)
+	return azure.FetchVNet(resourceID)
+}
+
+func (m *Model) applyVNet(msg azure.VNetMsg) {
+	if m.currentView != "vnet" || msg.ID != m.vnetID {
+		return
+	}
+	m.loading = false
+	m.err = nil
+	m.vnet = msg
+
+	network := msg.Network
+	addressSpace, subnets, peerings, dns := "-", 0, 0, "Azure-provided"
+	if props := network.Properties; props != nil {
+		if props.AddressSpace != nil {
+			addressSpace = joinOrDash(props.AddressSpace.AddressPrefixes)
+		}
+		subnets = len(props.Subnets)
+		peerings = len(props.VirtualNetworkPeerings)
+		if props.DhcpOptions != nil && len(props.DhcpOptions.DNSServers) > 0 {
+			dns = joinOrDash(props.DhcpOptions.DNSServers)
+		}
+	}
+	m.properties = []ui.Property{
+		{Key: "Virtual network", Value: orDash(network.Name)},
+		{Key: "Location", Value: orDash(network.Location)},
+		{Key: "Address space", Value: addressSpace},
+		{Key: "Subnets", Value: strconv.Itoa(subnets)},
+		{Key: "Peerings", Value: strconv.Itoa(peerings)},
+		{Key: "DNS servers", Value: dns},
+	}
+
+	m.topology.setText(ui.RenderTree(vnetTree(msg)))
+	m.updateLayout(m.width, m.height)
+}
+
+// vnetTree lays out a virtual network as its address space, its subnets
+// with what they hold and its peerings.
+func vnetTree(msg azure.VNetMsg) ui.TreeNode {
+	network := msg.Network
+	root := ui.TreeNode{Label: fmt.Sprintf("%s (%s)", orDash(network.Name), orDash(network.Location))}
+	props := network.Properties
+	if props == nil {
+		return root
+	}
+
+	space := ui.TreeNode{Label: "Address space"}
+	if props.AddressSpace != nil {
+		for _, prefix := range props.AddressSpace.AddressPrefixes {
+			if prefix != nil {
+				space.Children = append(space.Children, ui.TreeNode{Label: *prefix})
+			}
+		}
+	}
+	root.Children = append(root.Children, space)
+
+	free := map[string]string{}
+	for _, usage := range msg.Usages {
+		if usage != nil && usage.ID != nil && usage.Limit != nil && usage.CurrentValue != nil {
+			free[strings.ToLower(*usage.ID)] = fmt.Sprintf("%d free of %d", int64(*usage.Limit-*usage.CurrentValue), int64(*usage.Limit))
+		}
+	}
+	subnets := ui.TreeNode{Label: fmt.Sprintf("Subnets (%d)", len(props.Subnets))}
+	for _, subnet := range props.Subnets {
+		if subnet != nil {
+			subnets.Children = append(subnets.Children, subnetTree(subnet, free))
+		}
+	}
+	root.Children = append(root.Children, subnets)
+
+	peerings := ui.TreeNode{Label: fmt.Sprintf("Peerings (%d)", len(props.VirtualNetworkPeerings))}
+	for _, peering := range props.VirtualNetworkPeerings {
+		if peering != nil {
+			peerings.Children = append(peerings.Children, ui.TreeNode{Label: peeringLabel(peering)})
+		}
+	}
+	root.Children = append(root.Children, peerings)
+	return root
+}
+
+func subnetTree(subnet *armnetwork.Subnet, free map[string]string) ui.TreeNode {
+	node := ui.TreeNode{Label: orDash(subnet.Name)}
+	props := subnet.Properties
+	if props == nil {
+		return node
+	}
+
+	prefix := orDash(props.AddressPrefix)
+	if len(props.AddressPrefixes) > 0 {
+		prefix = joinOrDash(props.AddressPrefixes)
+	}
+	node.Label += "  " + prefix
+	if subnet.ID != nil {
+		if usage, ok := free[strings.ToLower(*subnet.ID)]; ok {
+			node.Label += "  (" + usage + ")"
+		}
+	}
+
+	nsg, routeTable := "none", "none"
+	if props.NetworkSecurityGroup != nil && props.NetworkSecurityGroup.ID != nil {
+		nsg = resourceName(*props.NetworkSecurityGroup.ID)
+	}
+	if props.RouteTable != nil && props.RouteTable.ID != nil {
+		routeTable = resourceName(*props.RouteTable.ID)
+	}
+	node.Children = append(node.Children,
+		ui.TreeNode{Label: "NSG: " + nsg},
+		ui.TreeNode{Label: "Route table: " + routeTable},
+	)
+	var delegations []string
+	for _, delegation := range props.Delegations {
+		if delegation != nil && delegation.Properties != nil && delegation.Properties.ServiceName != nil {
+			delegations = append(delegations, *delegation.Properties.ServiceName)
+		}
+	}
+	if len(delegations) > 0 {
+		node.Children = append(node.Children, ui.TreeNode{Label: "Delegated to: " + strings.Join(delegations, ", ")})
+	}
+
+	if connected := connectedResources(props); len(connected) > 0 {
+		node.Children = append(node.Children, ui.TreeNode{Label: fmt.Sprintf("Connected (%d)", len(connected)), Children: connected})
+	}
+	return node
+}
+
+// connectedResources lists the resources with IP configurations or private
+// endpoints in a subnet, once each, in the order they first appear.
+func connectedResources(props *armnetwork.SubnetPropertiesFormat) []ui.TreeNode {
+	var order []string
+	labels := map[string]string{}
+	counts := map[string]int{}
+	add := func(resourceID string) {
+		key, label, ok := attachedResource(resourceID)
+		if !ok {
+			return
+		}
+		if _, seen := labels[key]; !seen {
+			labels[key] = label
+			order = append(order, key)
+		}
+		counts[key]++
+	}
+	for _, config := range props.IPConfigurations {
+		if config != nil && config.ID != nil {
+			add(*config.ID)
+		}
+	}
+	for _, endpoint := range props.PrivateEndpoints {
+		if endpoint != nil && endpoint.ID != nil {
+			add(*endpoint.ID)
+		}
+	}
+
+	nodes := make([]ui.TreeNode, 0, len(order))
+	for _, key := range order {
+		label := labels[key]
+		if counts[key] > 1 {
+			label = strings.TrimSuffix(label, ")") + fmt.Sprintf(", %d IP configurations)", counts[key])
+		}
+		nodes = append(nodes, ui.TreeNode{Label: label})
+	}
+	return nodes
+}
+
+func peeringLabel(peering *armnetwork.VirtualNetworkPeering) string {
+	label := orDash(peering.Name)
+	props := peering.Properties
+	if props == nil {
+		return label
+	}
+	if props.RemoteVirtualNetwork != nil && props.RemoteVirtualNetwork.ID != nil {
+		remote := *props.RemoteVirtualNetwork.ID
+		if id, err := arm.ParseResourceID(remote); err == nil {
+			remote = id.Name + " in " + id.ResourceGroupName
+		}
+		label += " → " + remote
+	}
+	label += fmt.Sprintf(": %s, %s", orDash(props.PeeringState), orDash(props.PeeringSyncLevel))
+
+	var flags []string
+	if props.AllowForwardedTraffic != nil && *props.AllowForwardedTraffic {
+		flags = append(flags, "forwarded traffic")
+	}
+	if props.AllowGatewayTransit != nil && *props.AllowGatewayTransit {
+		flags = append(flags, "gateway transit")
+	}
+	if props.UseRemoteGateways != nil && *props.UseRemoteGateways {
+		flags = append(flags, "remote gateways")
+	}
+	if len(flags) > 0 {
+		label += " (" + strings.Join(flags, ", ") + ")"
+	}
+	return label
+}
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+)
+
+const testVNetID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/virtualNetworks/vnet-hub"
+
+func TestOpenVNetTopology(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVNetID), Name: to.Ptr("vnet-hub"), Type: to.Ptr("Microsoft.Network/virtualNetworks")},
+	)
+	model, cmd := pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil || model.currentView != "vnet" || model.vnetID != testVNetID {
+		t.Fatalf("enter on virtual network row opened %q (%s)", model.currentView, model.vnetID)
+	}
+
+	rg := "/subscriptions/sub-1/resourceGroups/rg-a/providers/"
+	updated, _ := model.Update(azure.VNetMsg{
+		ID: testVNetID,
+		Network: armnetwork.VirtualNetwork{
+			Name:     to.Ptr("vnet-hub"),
+			Location: to.Ptr("westeurope"),
+			Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+				AddressSpace: &armnetwork.AddressSpace{AddressPrefixes: []*string{to.Ptr("10.0.0.0/16"), to.Ptr("10.1.0.0/16")}},
+				Subnets: []*armnetwork.Subnet{
+					{
+						ID:   to.Ptr(testVNetID + "/subnets/web"),
+						Name: to.Ptr("web"),
+						Properties: &armnetwork.SubnetPropertiesFormat{
+							AddressPrefix:        to.Ptr("10.0.1.0/24"),
+							NetworkSecurityGroup: &armnetwork.SecurityGroup{ID: to.Ptr(rg + "Microsoft.Network/networkSecurityGroups/nsg-web")},
+							IPConfigurations: []*armnetwork.IPConfiguration{
+								{ID: to.Ptr(rg + "Microsoft.Network/networkInterfaces/vm1-nic/ipConfigurations/ipconfig1")},
+								{ID: to.Ptr(rg + "Microsoft.Compute/virtualMachineScaleSets/vmss-web/virtualMachines/0/networkInterfaces/nic/ipConfigurations/ip")},
+								{ID: to.Ptr(rg + "Microsoft.Compute/virtualMachineScaleSets/vmss-web/virtualMachines/1/networkInterfaces/nic/ipConfigurations/ip")},
+							},
+						},
+					},
+					{
+						ID:   to.Ptr(testVNetID + "/subnets/apps"),
+						Name: to.Ptr("apps"),
+						Properties: &armnetwork.SubnetPropertiesFormat{
+							AddressPrefix: to.Ptr("10.0.2.0/24"),
+							RouteTable:    &armnetwork.RouteTable{ID: to.Ptr(rg + "Microsoft.Network/routeTables/rt-apps")},
+							Delegations: []*armnetwork.Delegation{
+								{Properties: &armnetwork.ServiceDelegationPropertiesFormat{ServiceName: to.Ptr("Microsoft.Web/serverFarms")}},
+							},
+						},
+					},
+				},
+				VirtualNetworkPeerings: []*armnetwork.VirtualNetworkPeering{
+					{
+						Name: to.Ptr("hub-to-spoke"),
+						Properties: &armnetwork.VirtualNetworkPeeringPropertiesFormat{
+							RemoteVirtualNetwork:  &armnetwork.SubResource{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-b/providers/Microsoft.Network/virtualNetworks/vnet-spoke")},
+							PeeringState:          to.Ptr(armnetwork.VirtualNetworkPeeringStateConnected),
+							PeeringSyncLevel:      to.Ptr(armnetwork.VirtualNetworkPeeringLevelFullyInSync),
+							AllowGatewayTransit:   to.Ptr(true),
+							AllowForwardedTraffic: to.Ptr(false),
+						},
+					},
+				},
+			},
+		},
+		Usages: []*armnetwork.VirtualNetworkUsage{
+			{ID: to.Ptr(strings.ToUpper(testVNetID) + "/SUBNETS/WEB"), CurrentValue: to.Ptr[float64](8), Limit: to.Ptr[float64](251)},
+		},
+	})
+	model = updated.(Model)
+
+	if got := propertyValue(t, model, "Address space"); got != "10.0.0.0/16, 10.1.0.0/16" {
+		t.Errorf("Address space = %q", got)
+	}
+	want := `vnet-hub (westeurope)
+├── Address space
+│   ├── 10.0.0.0/16
+│   └── 10.1.0.0/16
+├── Subnets (2)
+│   ├── web  10.0.1.0/24  (243 free of 251)
+│   │   ├── NSG: nsg-web
+│   │   ├── Route table: none
+│   │   └── Connected (2)
+│   │       ├── vm1-nic (network interface)
+│   │       └── vmss-web (scale set, 2 IP configurations)
+│   └── apps  10.0.2.0/24
+│       ├── NSG: none
+│       ├── Route table: rt-apps
+│       └── Delegated to: Microsoft.Web/serverFarms
+└── Peerings (1)
+    └── hub-to-spoke → vnet-spoke in rg-b: Connected, FullyInSync (gateway transit)`
+	if got := strings.Join(model.topology.allLines(), "\n"); got != want {
+		t.Errorf("topology =\n%s\nwant\n%s", got, want)
+	}
+
+	model, _ = pressKeys(model, runes("/"), runes("rt-apps"), tea.KeyMsg{Type: tea.KeyEnter})
+	if len(model.topology.matches) != 1 {
+		t.Errorf("searching the tree found %d matches, want 1", len(model.topology.matches))
+	}
+}
+
+func TestVNetReplyAfterEsc(t *testing.T) {
+	model := newResourcesModel(
+		armresources.GenericResourceExpanded{ID: to.Ptr(testVNetID), Name: to.Ptr("vnet-hub"), Type: to.Ptr("Microsoft.Network/virtualNetworks")},
+	)
+	model, _ = pressKeys(model, tea.KeyMsg{Type: tea.KeyEnter}, tea.KeyMsg{Type: tea.KeyEsc})
+
+	updated, _ := model.Update(azure.VNetMsg{ID: testVNetID, Network: armnetwork.VirtualNetwork{Name: to.Ptr("vnet-hub")}})
+	model = updated.(Model)
+	if model.currentView != "resources" || model.loading {
+		t.Errorf("late virtual network reply left view %q loading=%v", model.currentView, model.loading)
+	}
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/azure"
+	"github.com/mbaykara/azurermcli/internal/styles"
+	"github.com/mbaykara/azurermcli/internal/ui"
+)
+
+// currentScope returns the ARM scope the user is looking at: the subscription
+// or resource group under the cursor, or the one being browsed.
+func (m Model) currentScope() string {
+	return azure.Scope(m.currentSubscriptionAndGroup())
+}
+
+// currentSubscriptionAndGroup returns the subscription and resource group
+// behind currentScope. The resource group is empty at subscription scope.
+func (m Model) currentSubscriptionAndGroup() (subscriptionID, resourceGroup string) {
+	switch m.currentView {
+	case "subscriptions":
+		if selected := m.table.SelectedRow(); len(selected) >= 2 {
+			return selected[1], ""
+		}
+		return "", ""
+	case "resourcegroups":
+		selected := m.table.SelectedRow()
+		if len(selected) < 1 {
+			return m.selectedSub, ""
+		}
+		subID := m.selectedSub
+		if m.multiSub {
+			cursor := m.table.Cursor()
+			if cursor < 0 || cursor >= len(m.rowSubscriptions) {
+				return "", ""
+			}
+			subID = m.rowSubscriptions[cursor]
+		}
+		return subID, selected[0]
+	default:
+		return m.selectedSub, m.selectedRG
+	}
+}
+
+func (m *Model) openWhoami() tea.Cmd {
+	scope := m.currentScope()
+	m.openDetailView("whoami")
+	return azure.FetchWhoami(scope)
+}
+
+func (m *Model) updateTableWithWhoami() {
+	w := m.whoami
+
+	expires := "-"
+	if !w.Expires.IsZero() {
+		expires = fmt.Sprintf("%s (in %s)", w.Expires.Local().Format("2006-01-02 15:04:05"), time.Until(w.Expires).Round(time.Minute))
+	}
+	scope := w.Scope
+	if scope == "" {
+		scope = "none selected"
+	}
+
+	m.properties = []ui.Property{
+		{Key: "Principal", Value: w.Principal},
+		{Key: "Object ID", Value: w.ObjectID},
+		{Key: "Tenant", Value: w.TenantID},
+		{Key: "Auth method", Value: w.Method},
+		{Key: "Token expires", Value: expires},
+		{Key: "Scope", Value: scope},
+	}
+	if w.AssignmentsErr != nil {
+		m.properties = append(m.properties, ui.Property{
+			Key:   "Role assignments",
+			Value: styles.ErrorStyle.Render(w.AssignmentsErr.Error()),
+		})
+	}
+
+	m.table.SetRows([]table.Row{})
+
+	roleWidth := int(float64(m.width) * 0.3)     // 30% of width
+	scopeWidth := int(float64(m.width) * 0.4)    // 40% of width
+	typeWidth := int(float64(m.width) * 0.15)    // 15% of width
+	appliesWidth := int(float64(m.width) * 0.15) // 15% of width
+
+	columns := []table.Column{
+		{Title: "Role", Width: roleWidth},
+		{Title: "Scope", Width: scopeWidth},
+		{Title: "Assigned To", Width: typeWidth},
+		{Title: "Applies", Width: appliesWidth},
+	}
+	m.table.SetColumns(columns)
+
+	var rows []table.Row
+	for _, a := range w.Assignments {
+		rows = append(rows, table.Row{a.Role, a.Scope, a.PrincipalType, a.Applies})
+	}
+	switch {
+	case len(rows) > 0:
+	case w.AssignmentsErr != nil:
+		rows = append(rows, table.Row{"Role assignments could not be listed", w.AssignmentsErr.Error(), "-", "-"})
+	default:
+		rows = append(rows, table.Row{"No role assignments at this scope", "-", "-", "-"})
+	}
+
+	m.table.SetRows(rows)
+	m.table.SetCursor(0)
+}
@@ -0,0 +1,242 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mbaykara/azurermcli/internal/kubeconfig"
+)
+
+func newManagedClustersClient(subscriptionID string) (*armcontainerservice.ManagedClustersClient, error) {
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return armcontainerservice.NewManagedClustersClient(subscriptionID, cred, armOptions())
+}
+
+// FetchAKSCluster loads an AKS cluster and the Kubernetes versions its control
+// plane can be upgraded to.
+func FetchAKSCluster(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := managedCluster(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := AKSClusterMsg{ID: resourceID, Cluster: resp.ManagedCluster}
+
+		// Missing upgrade information should not hide the cluster itself.
+		profile, err := client.GetUpgradeProfile(ctx, id.ResourceGroupName, id.Name, nil)
+		if err == nil && profile.Properties != nil && profile.Properties.ControlPlaneProfile != nil {
+			for _, upgrade := range profile.Properties.ControlPlaneProfile.Upgrades {
+				if upgrade.KubernetesVersion == nil {
+					continue
+				}
+				version := *upgrade.KubernetesVersion
+				if upgrade.IsPreview != nil && *upgrade.IsPreview {
+					version += " (preview)"
+				}
+				msg.Upgrades = append(msg.Upgrades, version)
+			}
+		}
+
+		return msg
+	}
+}
+
+func newAgentPoolsClient(subscriptionID string) (*armcontainerservice.AgentPoolsClient, error) {
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return armcontainerservice.NewAgentPoolsClient(subscriptionID, cred, armOptions())
+}
+
+// StopAKSCluster deallocates the cluster's control plane and nodes as
+// background operation opID.
+func StopAKSCluster(opID int, resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := managedCluster(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := client.BeginStop(context.Background(), id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// StartAKSCluster starts a stopped cluster as background operation opID.
+func StartAKSCluster(opID int, resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := managedCluster(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := client.BeginStart(context.Background(), id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// UpgradeAKSControlPlane upgrades the control plane to version, leaving the
+// node pools on their current version.
+func UpgradeAKSControlPlane(opID int, resourceID, version string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := managedCluster(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		cluster := resp.ManagedCluster
+		if cluster.Properties == nil {
+			cluster.Properties = &armcontainerservice.ManagedClusterProperties{}
+		}
+		cluster.Properties.KubernetesVersion = &version
+
+		poller, err := client.BeginCreateOrUpdate(ctx, id.ResourceGroupName, id.Name, cluster, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// UpgradeAKSNodeImage moves a node pool to the latest node image.
+func UpgradeAKSNodeImage(opID int, resourceID, pool string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		client, err := newAgentPoolsClient(id.SubscriptionID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := client.BeginUpgradeNodeImageVersion(context.Background(), id.ResourceGroupName, id.Name, pool, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// ScaleAKSNodePool sets a node pool's node count. Pools with the autoscaler
+// enabled are rejected by the service.
+func ScaleAKSNodePool(opID int, resourceID, pool string, count int32) tea.Cmd {
+	return updateAgentPool(opID, resourceID, pool, func(props *armcontainerservice.ManagedClusterAgentPoolProfileProperties) {
+		props.Count = &count
+	})
+}
+
+// SetAKSAutoscaler enables the cluster autoscaler on a node pool with the
+// given bounds, or disables it when enabled is false.
+func SetAKSAutoscaler(opID int, resourceID, pool string, enabled bool, minCount, maxCount int32) tea.Cmd {
+	return updateAgentPool(opID, resourceID, pool, func(props *armcontainerservice.ManagedClusterAgentPoolProfileProperties) {
+		props.EnableAutoScaling = &enabled
+		if enabled {
+			props.MinCount = &minCount
+			props.MaxCount = &maxCount
+		} else {
+			props.MinCount = nil
+			props.MaxCount = nil
+		}
+	})
+}
+
+// updateAgentPool reads a node pool, applies change and writes it back.
+func updateAgentPool(opID int, resourceID, pool string, change func(*armcontainerservice.ManagedClusterAgentPoolProfileProperties)) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		client, err := newAgentPoolsClient(id.SubscriptionID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, pool, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		agentPool := resp.AgentPool
+		if agentPool.Properties == nil {
+			agentPool.Properties = &armcontainerservice.ManagedClusterAgentPoolProfileProperties{}
+		}
+		change(agentPool.Properties)
+
+		poller, err := client.BeginCreateOrUpdate(ctx, id.ResourceGroupName, id.Name, pool, agentPool, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+func managedCluster(resourceID string) (*arm.ResourceID, *armcontainerservice.ManagedClustersClient, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newManagedClustersClient(id.SubscriptionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, client, nil
+}
+
+// WriteAKSKubeconfig fetches the cluster's user or admin credentials and
+// merges them into the kubeconfig file at path as context contextName.
+func WriteAKSKubeconfig(resourceID string, admin bool, path, contextName string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := managedCluster(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		var credentials armcontainerservice.CredentialResults
+		if admin {
+			resp, err := client.ListClusterAdminCredentials(ctx, id.ResourceGroupName, id.Name, nil)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			credentials = resp.CredentialResults
+		} else {
+			resp, err := client.ListClusterUserCredentials(ctx, id.ResourceGroupName, id.Name, nil)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			credentials = resp.CredentialResults
+		}
+		if len(credentials.Kubeconfigs) == 0 || credentials.Kubeconfigs[0] == nil {
+			return ErrorMsg{fmt.Errorf("no kubeconfig returned for cluster %s", id.Name)}
+		}
+
+		if err := kubeconfig.Merge(path, credentials.Kubeconfigs[0].Value, contextName); err != nil {
+			return ErrorMsg{err}
+		}
+		return KubeconfigMsg{Path: path, Context: contextName}
+	}
+}
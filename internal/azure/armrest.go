@@ -0,0 +1,67 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// armGet reads a Resource Manager path, such as a metrics query, decoding
+// the JSON response into out. It goes through the same pipeline, credential
+// and cloud as the SDK clients.
+func armGet(ctx context.Context, path, apiVersion string, query url.Values, out any) error {
+	client, err := armClient()
+	if err != nil {
+		return err
+	}
+	params := url.Values{}
+	for key, values := range query {
+		params[key] = values
+	}
+	params.Set("api-version", apiVersion)
+	return armDo(ctx, client, runtime.JoinPaths(client.Endpoint(), path)+"?"+params.Encode(), out)
+}
+
+// armGetNext reads the next page of a listing read with armGet. The link
+// has to point at the Resource Manager endpoint, so that the token is only
+// ever sent there.
+func armGetNext(ctx context.Context, nextLink string, out any) error {
+	client, err := armClient()
+	if err != nil {
+		return err
+	}
+	if !sameHost(nextLink, client.Endpoint()) {
+		return fmt.Errorf("refusing next link %s outside %s", nextLink, strings.TrimSuffix(client.Endpoint(), "/"))
+	}
+	return armDo(ctx, client, nextLink, out)
+}
+
+func armClient() (*arm.Client, error) {
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return arm.NewClient("azurermcli", "v0", cred, armOptions())
+}
+
+func armDo(ctx context.Context, client *arm.Client, rawURL string, out any) error {
+	req, err := runtime.NewRequest(ctx, http.MethodGet, rawURL)
+	if err != nil {
+		return err
+	}
+	req.Raw().Header.Set("Accept", "application/json")
+
+	resp, err := client.Pipeline().Do(req)
+	if err != nil {
+		return err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return runtime.NewResponseError(resp)
+	}
+	return runtime.UnmarshalAsJSON(resp, out)
+}
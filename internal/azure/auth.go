@@ -0,0 +1,170 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Authentication methods accepted by --auth.
+const (
+	AuthDefault          = "default"
+	AuthCLI              = "azcli"
+	AuthDeviceCode       = "devicecode"
+	AuthBrowser          = "browser"
+	AuthServicePrincipal = "serviceprincipal"
+	AuthWorkloadIdentity = "workloadidentity"
+	AuthManagedIdentity  = "managedidentity"
+)
+
+// AuthMethods lists the supported authentication methods.
+var AuthMethods = []string{
+	AuthDefault,
+	AuthCLI,
+	AuthDeviceCode,
+	AuthBrowser,
+	AuthServicePrincipal,
+	AuthWorkloadIdentity,
+	AuthManagedIdentity,
+}
+
+// AuthOptions selects how azr signs in.
+type AuthOptions struct {
+	Method string
+	// ClientID is the application of a service principal or workload
+	// identity, or the user-assigned managed identity to use.
+	ClientID string
+	// ClientSecret or CertificatePath authenticate a service principal.
+	ClientSecret        string
+	CertificatePath     string
+	CertificatePassword string
+}
+
+// SetAuth selects the authentication method used for all requests.
+func SetAuth(opts AuthOptions) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.auth = opts
+	session.cred = nil
+}
+
+// AuthMethod returns the selected authentication method.
+func AuthMethod() string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.auth.Method == "" {
+		return AuthDefault
+	}
+	return session.auth.Method
+}
+
+// SetAuthPrompt registers a callback that shows sign-in instructions, such as
+// the device code, to the user.
+func SetAuthPrompt(prompt func(message string)) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.prompt = prompt
+}
+
+// newCredential builds the credential for the selected method. Only the
+// default method probes several sources; every other method fails loudly
+// instead of silently falling back to whatever happens to be configured.
+func newCredential(opts AuthOptions, tenantID string, cloudCfg cloud.Configuration, prompt func(string)) (azcore.TokenCredential, error) {
+	clientOpts := azcore.ClientOptions{Cloud: cloudCfg}
+
+	switch strings.ToLower(opts.Method) {
+	case "", AuthDefault:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOpts,
+			TenantID:      tenantID,
+		})
+	case AuthCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+			TenantID: tenantID,
+		})
+	case AuthDeviceCode:
+		return azidentity.NewDeviceCodeCredential(&azidentity.DeviceCodeCredentialOptions{
+			ClientOptions: clientOpts,
+			ClientID:      opts.ClientID,
+			TenantID:      tenantID,
+			UserPrompt: func(_ context.Context, msg azidentity.DeviceCodeMessage) error {
+				if prompt == nil {
+					return fmt.Errorf("device code sign-in needs a prompt: %s", msg.Message)
+				}
+				prompt(msg.Message)
+				return nil
+			},
+		})
+	case AuthBrowser:
+		return azidentity.NewInteractiveBrowserCredential(&azidentity.InteractiveBrowserCredentialOptions{
+			ClientOptions: clientOpts,
+			ClientID:      opts.ClientID,
+			TenantID:      tenantID,
+		})
+	case AuthServicePrincipal:
+		if tenantID == "" || opts.ClientID == "" {
+			return nil, fmt.Errorf("service principal sign-in needs a tenant and a client ID")
+		}
+		if opts.CertificatePath != "" {
+			data, err := os.ReadFile(opts.CertificatePath)
+			if err != nil {
+				return nil, err
+			}
+			certs, key, err := azidentity.ParseCertificates(data, []byte(opts.CertificatePassword))
+			if err != nil {
+				return nil, err
+			}
+			return azidentity.NewClientCertificateCredential(tenantID, opts.ClientID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+				ClientOptions: clientOpts,
+			})
+		}
+		if opts.ClientSecret == "" {
+			return nil, fmt.Errorf("service principal sign-in needs a client secret or certificate")
+		}
+		return azidentity.NewClientSecretCredential(tenantID, opts.ClientID, opts.ClientSecret, &azidentity.ClientSecretCredentialOptions{
+			ClientOptions: clientOpts,
+		})
+	case AuthWorkloadIdentity:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOpts,
+			ClientID:      opts.ClientID,
+			TenantID:      tenantID,
+		})
+	case AuthManagedIdentity:
+		miOpts := &azidentity.ManagedIdentityCredentialOptions{ClientOptions: clientOpts}
+		if opts.ClientID != "" {
+			miOpts.ID = azidentity.ClientID(opts.ClientID)
+		}
+		return azidentity.NewManagedIdentityCredential(miOpts)
+	default:
+		return nil, fmt.Errorf("unknown authentication method %q (want one of %s)", opts.Method, strings.Join(AuthMethods, ", "))
+	}
+}
+
+// FetchIdentity reports which identity and tenant the session is using.
+func FetchIdentity() tea.Msg {
+	cred, err := credential()
+	if err != nil {
+		return ErrorMsg{err}
+	}
+
+	claims, err := currentClaims(context.Background(), cred)
+	if err != nil {
+		return ErrorMsg{err}
+	}
+
+	return IdentityMsg{
+		Method:    AuthMethod(),
+		Principal: claims.principal(),
+		TenantID:  claims.TenantID,
+	}
+}
@@ -0,0 +1,67 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+func TestNewCredential(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     AuthOptions
+		tenantID string
+		wantErr  bool
+	}{
+		{name: "Default", opts: AuthOptions{}},
+		{name: "Azure CLI", opts: AuthOptions{Method: AuthCLI}},
+		{name: "Device code", opts: AuthOptions{Method: AuthDeviceCode}},
+		{name: "Service principal secret", opts: AuthOptions{Method: AuthServicePrincipal, ClientID: "app", ClientSecret: "secret"}, tenantID: "tenant"},
+		{name: "Service principal without tenant", opts: AuthOptions{Method: AuthServicePrincipal, ClientID: "app", ClientSecret: "secret"}, wantErr: true},
+		{name: "Service principal without secret", opts: AuthOptions{Method: AuthServicePrincipal, ClientID: "app"}, tenantID: "tenant", wantErr: true},
+		{name: "Service principal missing certificate", opts: AuthOptions{Method: AuthServicePrincipal, ClientID: "app", CertificatePath: "/does/not/exist.pem"}, tenantID: "tenant", wantErr: true},
+		{name: "Managed identity", opts: AuthOptions{Method: AuthManagedIdentity, ClientID: "app"}},
+		{name: "Unknown", opts: AuthOptions{Method: "kerberos"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newCredential(tt.opts, tt.tenantID, cloud.AzurePublic, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("newCredential() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewCredentialMethodIsExplicit(t *testing.T) {
+	cred, err := newCredential(AuthOptions{Method: AuthCLI}, "", cloud.AzurePublic, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cred.(*azidentity.AzureCLICredential); !ok {
+		t.Errorf("newCredential(azcli) = %T, want *azidentity.AzureCLICredential", cred)
+	}
+}
+
+func TestTokenClaimsPrincipal(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims tokenClaims
+		want   string
+	}{
+		{name: "User", claims: tokenClaims{UPN: "jane@contoso.com", AppID: "app", ObjectID: "oid"}, want: "jane@contoso.com"},
+		{name: "Guest user", claims: tokenClaims{UniqueName: "live.com#jane@example.com", ObjectID: "oid"}, want: "live.com#jane@example.com"},
+		{name: "Service principal", claims: tokenClaims{AppID: "app", ObjectID: "oid"}, want: "app"},
+		{name: "Nothing", claims: tokenClaims{}, want: "unknown"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.principal(); got != tt.want {
+				t.Errorf("principal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
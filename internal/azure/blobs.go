@@ -0,0 +1,330 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+	blobcontainer "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// maxTransferWorkers bounds how many files a transfer moves at once.
+	maxTransferWorkers = 4
+	// MaxBlobPreview is the most of a blob read for a preview.
+	MaxBlobPreview = 1 << 20
+)
+
+// TransferFile is one file of a download or upload.
+type TransferFile struct {
+	Blob  string
+	Local string
+	Size  int64
+}
+
+// Transfer is a running download or upload. Its progress arrives as
+// TransferUpdateMsg, one message per Next.
+type Transfer struct {
+	ID       int
+	Download bool
+	Files    []TransferFile
+	updates  chan TransferUpdateMsg
+}
+
+// NewTransfer returns a transfer of files whose progress is not yet
+// being reported.
+func NewTransfer(id int, download bool, files []TransferFile) *Transfer {
+	return &Transfer{ID: id, Download: download, Files: files, updates: make(chan TransferUpdateMsg, len(files)+64)}
+}
+
+// Next waits for the next progress update of the transfer.
+func (t *Transfer) Next() tea.Cmd {
+	return func() tea.Msg {
+		return <-t.updates
+	}
+}
+
+// run moves every file with move, at most maxTransferWorkers at a time,
+// and reports each file's progress and outcome followed by a final update
+// with Finished set. Files not started when ctx is cancelled fail with the
+// context's error.
+func (t *Transfer) run(ctx context.Context, move func(ctx context.Context, file TransferFile, progress func(int64)) error) {
+	sem := make(chan struct{}, maxTransferWorkers)
+	var wg sync.WaitGroup
+	for i, file := range t.Files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				t.updates <- TransferUpdateMsg{ID: t.ID, Index: i, Err: ctx.Err(), Done: true}
+				return
+			}
+			defer func() { <-sem }()
+
+			progress := func(bytes int64) {
+				select {
+				case t.updates <- TransferUpdateMsg{ID: t.ID, Index: i, Bytes: bytes}:
+				default: // drop progress rather than stall the transfer
+				}
+			}
+			err := ctx.Err()
+			if err == nil {
+				err = move(ctx, file, progress)
+			}
+			t.updates <- TransferUpdateMsg{ID: t.ID, Index: i, Bytes: file.Size, Err: err, Done: true}
+		}()
+	}
+	wg.Wait()
+	t.updates <- TransferUpdateMsg{ID: t.ID, Index: -1, Finished: true}
+}
+
+// localTarget maps a blob to a path under dir, naming it relative to
+// strip. Blob names that would escape dir are refused.
+func localTarget(dir, blobName, strip string) (string, error) {
+	rel := strings.TrimPrefix(blobName, strip)
+	target := filepath.Join(dir, filepath.FromSlash(rel))
+	within, err := filepath.Rel(dir, target)
+	if err != nil || within == "." || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("refusing to download %q outside %s", blobName, dir)
+	}
+	return target, nil
+}
+
+// errBlobExists fails the upload of a file whose blob already exists when
+// overwriting was not asked for.
+var errBlobExists = errors.New("blob already exists")
+
+// StartDownload downloads a blob, or every blob under a virtual directory
+// when name ends in "/", into dir. Blobs keep their path relative to
+// parent, the directory being browsed. Existing files are only replaced
+// when overwrite is set; otherwise they fail.
+func StartDownload(ctx context.Context, id int, account StorageAccount, containerName, parent, name, dir string, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		client, err := account.blobService()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		containerClient := client.NewContainerClient(containerName)
+
+		var files []TransferFile
+		if strings.HasSuffix(name, "/") {
+			pager := containerClient.NewListBlobsFlatPager(&blobcontainer.ListBlobsFlatOptions{Prefix: to.Ptr(name)})
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				if page.Segment == nil {
+					continue
+				}
+				for _, item := range page.Segment.BlobItems {
+					if item == nil || item.Name == nil {
+						continue
+					}
+					file := TransferFile{Blob: *item.Name}
+					if item.Properties != nil && item.Properties.ContentLength != nil {
+						file.Size = *item.Properties.ContentLength
+					}
+					files = append(files, file)
+				}
+			}
+		} else {
+			props, err := containerClient.NewBlobClient(name).GetProperties(ctx, nil)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			files = append(files, TransferFile{Blob: name, Size: valueOrZero(props.ContentLength)})
+		}
+		if len(files) == 0 {
+			return ErrorMsg{fmt.Errorf("nothing to download under %s", name)}
+		}
+		for i := range files {
+			if files[i].Local, err = localTarget(dir, files[i].Blob, parent); err != nil {
+				return ErrorMsg{err}
+			}
+		}
+
+		transfer := NewTransfer(id, true, files)
+		go transfer.run(ctx, func(ctx context.Context, file TransferFile, progress func(int64)) error {
+			return downloadBlob(ctx, containerClient.NewBlobClient(file.Blob), file.Local, overwrite, progress)
+		})
+		return TransferStartedMsg{Transfer: transfer}
+	}
+}
+
+// downloadBlob writes a blob to path, removing the partial file when the
+// download fails or is cancelled. An existing file is left alone unless
+// overwrite is set.
+func downloadBlob(ctx context.Context, client *blob.Client, path string, overwrite bool, progress func(int64)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	flag := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+	if overwrite {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = client.DownloadFile(ctx, f, &blob.DownloadFileOptions{Progress: progress})
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// uploadFiles lists what uploading local into prefix moves: the file
+// itself, or every file below a directory under a virtual directory of the
+// same name.
+func uploadFiles(local, prefix string) ([]TransferFile, error) {
+	info, err := os.Stat(local)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []TransferFile{{Blob: prefix + filepath.Base(local), Local: local, Size: info.Size()}}, nil
+	}
+
+	root := filepath.Clean(local)
+	base := filepath.Base(root)
+	var files []TransferFile
+	err = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		files = append(files, TransferFile{Blob: prefix + path.Join(base, filepath.ToSlash(rel)), Local: p, Size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no files under %s", local)
+	}
+	return files, nil
+}
+
+// StartUpload uploads a local file, or a directory tree, into a virtual
+// directory of a container as block blobs. Existing blobs are only
+// replaced when overwrite is set; otherwise they fail.
+func StartUpload(ctx context.Context, id int, account StorageAccount, containerName, prefix, local string, overwrite bool) tea.Cmd {
+	return func() tea.Msg {
+		client, err := account.blobService()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		files, err := uploadFiles(local, prefix)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		containerClient := client.NewContainerClient(containerName)
+
+		transfer := NewTransfer(id, false, files)
+		go transfer.run(ctx, func(ctx context.Context, file TransferFile, progress func(int64)) error {
+			return uploadBlob(ctx, containerClient.NewBlockBlobClient(file.Blob), file.Local, overwrite, progress)
+		})
+		return TransferStartedMsg{Transfer: transfer}
+	}
+}
+
+func uploadBlob(ctx context.Context, client *blockblob.Client, path string, overwrite bool, progress func(int64)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	options := &blockblob.UploadFileOptions{Progress: progress}
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		options.HTTPHeaders = &blob.HTTPHeaders{BlobContentType: to.Ptr(contentType)}
+	}
+	if !overwrite {
+		// The condition is only sent with uploads made in one request, so
+		// files large enough to go in blocks are checked for first.
+		if _, err := client.GetProperties(ctx, nil); err == nil {
+			return errBlobExists
+		} else if !bloberror.HasCode(err, bloberror.BlobNotFound) {
+			return err
+		}
+		options.AccessConditions = &blob.AccessConditions{
+			ModifiedAccessConditions: &blob.ModifiedAccessConditions{IfNoneMatch: to.Ptr(azcore.ETagAny)},
+		}
+	}
+	_, err = client.UploadFile(ctx, f, options)
+	if bloberror.HasCode(err, bloberror.BlobAlreadyExists, bloberror.ConditionNotMet) {
+		return errBlobExists
+	}
+	return err
+}
+
+// FetchBlobPreview reads up to MaxBlobPreview bytes from the start of a
+// blob.
+func FetchBlobPreview(account StorageAccount, containerName, name string) tea.Cmd {
+	return func() tea.Msg {
+		client, err := account.blobService()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		ctx := context.Background()
+		blobClient := client.NewContainerClient(containerName).NewBlobClient(name)
+		props, err := blobClient.GetProperties(ctx, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		size := valueOrZero(props.ContentLength)
+
+		msg := BlobPreviewMsg{AccountID: account.ID, Container: containerName, Name: name, Size: size, ContentType: deref(props.ContentType)}
+		if size == 0 {
+			return msg
+		}
+		resp, err := blobClient.DownloadStream(ctx, &blob.DownloadStreamOptions{
+			Range: blob.HTTPRange{Count: min(size, MaxBlobPreview)},
+		})
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		defer resp.Body.Close()
+		msg.Data, err = io.ReadAll(io.LimitReader(resp.Body, MaxBlobPreview))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return ErrorMsg{err}
+		}
+		return msg
+	}
+}
+
+func valueOrZero(n *int64) int64 {
+	if n == nil {
+		return 0
+	}
+	return *n
+}
@@ -0,0 +1,161 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func drain(t *testing.T, transfer *Transfer) []TransferUpdateMsg {
+	t.Helper()
+
+	var updates []TransferUpdateMsg
+	for {
+		select {
+		case update := <-transfer.updates:
+			updates = append(updates, update)
+			if update.Finished {
+				return updates
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("transfer never finished")
+		}
+	}
+}
+
+// fileErr returns the first error a transfer's files finished with.
+func fileErr(updates []TransferUpdateMsg) error {
+	for _, update := range updates {
+		if update.Done && update.Err != nil {
+			return update.Err
+		}
+	}
+	return nil
+}
+
+func TestDownloadKeepsExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	os.WriteFile(path, []byte("keep"), 0o644)
+
+	err := downloadBlob(context.Background(), nil, path, false, nil)
+	if !errors.Is(err, os.ErrExist) {
+		t.Errorf("downloadBlob() over an existing file = %v, want it to exist", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "keep" {
+		t.Errorf("existing file = %q after a refused download", data)
+	}
+}
+
+func TestTransferBoundsConcurrency(t *testing.T) {
+	files := make([]TransferFile, 10)
+	for i := range files {
+		files[i] = TransferFile{Blob: "f", Size: 3}
+	}
+	transfer := NewTransfer(7, true, files)
+
+	var running, peak atomic.Int32
+	go transfer.run(context.Background(), func(ctx context.Context, file TransferFile, progress func(int64)) error {
+		n := running.Add(1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		progress(1)
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+
+	done := 0
+	for _, update := range drain(t, transfer) {
+		if update.ID != 7 {
+			t.Errorf("update for transfer %d, want 7", update.ID)
+		}
+		if update.Done {
+			done++
+			if update.Err != nil || update.Bytes != 3 {
+				t.Errorf("file %d finished with %d bytes, %v", update.Index, update.Bytes, update.Err)
+			}
+		}
+	}
+	if done != len(files) {
+		t.Errorf("%d files reported done, want %d", done, len(files))
+	}
+	if peak.Load() > maxTransferWorkers {
+		t.Errorf("%d files moved at once, want at most %d", peak.Load(), maxTransferWorkers)
+	}
+}
+
+func TestTransferCancel(t *testing.T) {
+	transfer := NewTransfer(1, false, make([]TransferFile, 8))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{}, 8)
+	go transfer.run(ctx, func(ctx context.Context, file TransferFile, progress func(int64)) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	<-started
+	cancel()
+
+	failed := 0
+	for _, update := range drain(t, transfer) {
+		if update.Done && errors.Is(update.Err, context.Canceled) {
+			failed++
+		}
+	}
+	if failed != 8 {
+		t.Errorf("%d files cancelled, want all 8", failed)
+	}
+}
+
+func TestLocalTarget(t *testing.T) {
+	dir := t.TempDir()
+
+	got, err := localTarget(dir, "logs/2024/a.txt", "logs/")
+	if err != nil || got != filepath.Join(dir, "2024", "a.txt") {
+		t.Errorf("localTarget() = %q, %v", got, err)
+	}
+	for _, name := range []string{"../etc/passwd", "logs/../../x", "logs/"} {
+		if _, err := localTarget(dir, name, "logs/"); err == nil {
+			t.Errorf("localTarget(%q) escaped the download directory", name)
+		}
+	}
+}
+
+func TestUploadFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	for _, name := range []string{"index.html", "css/main.css"} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("body"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := uploadFiles(dir, "www/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Blob != "www/site/css/main.css" || files[1].Blob != "www/site/index.html" || files[1].Size != 4 {
+		t.Errorf("uploadFiles(dir) = %+v", files)
+	}
+
+	files, err = uploadFiles(filepath.Join(dir, "index.html"), "")
+	if err != nil || len(files) != 1 || files[0].Blob != "index.html" {
+		t.Errorf("uploadFiles(file) = %+v, %v", files, err)
+	}
+
+	if _, err := uploadFiles(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Error("uploading a missing file did not fail")
+	}
+}
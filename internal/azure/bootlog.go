@@ -0,0 +1,96 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxBootLogChunk caps how much of a serial log is read per request.
+const maxBootLogChunk = 4 << 20
+
+// bootLogClient gives up on a range read that hangs, so that following a
+// log does not stop for good.
+var bootLogClient = &http.Client{Timeout: 30 * time.Second}
+
+// errBootLogRefused is returned when the storage account refuses the SAS
+// URI, typically because it has expired.
+var errBootLogRefused = errors.New("serial log URI was refused")
+
+// FetchBootLogURI asks for a short-lived SAS URI of the VM's boot
+// diagnostics serial log.
+func FetchBootLogURI(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := virtualMachine(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		resp, err := client.RetrieveBootDiagnosticsData(context.Background(), id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		if resp.SerialConsoleLogBlobURI == nil || *resp.SerialConsoleLogBlobURI == "" {
+			return ErrorMsg{fmt.Errorf("%s has no serial log; is boot diagnostics enabled?", id.Name)}
+		}
+		return BootLogURIMsg{VMID: resourceID, URI: *resp.SerialConsoleLogBlobURI}
+	}
+}
+
+// FetchBootLog reads the serial log blob at uri starting at byte offset
+// from, so following a log only transfers what was appended. A refused URI
+// is reported as Expired rather than as an error.
+func FetchBootLog(uri string, from int64) tea.Cmd {
+	return func() tea.Msg {
+		data, err := readBlobRange(context.Background(), uri, from)
+		if errors.Is(err, errBootLogRefused) {
+			return BootLogMsg{URI: uri, From: from, Expired: true}
+		}
+		if err != nil {
+			return ErrorMsg{fmt.Errorf("reading serial log: %w", err)}
+		}
+		return BootLogMsg{URI: uri, From: from, Data: data}
+	}
+}
+
+// readBlobRange GETs the bytes of a blob from offset on. A range past the
+// end of the blob yields no data rather than an error.
+func readBlobRange(ctx context.Context, uri string, from int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if from > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", from))
+	}
+	req.Header.Set("x-ms-version", "2021-08-06")
+
+	resp, err := bootLogClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusRequestedRangeNotSatisfiable:
+		return nil, nil
+	case http.StatusOK:
+		// The server ignored the range; skip what was already read.
+		if _, err := io.CopyN(io.Discard, resp.Body, from); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, nil // The log got shorter than what was read.
+			}
+			return nil, err
+		}
+	case http.StatusPartialContent:
+	case http.StatusForbidden:
+		return nil, errBootLogRefused
+	default:
+		return nil, fmt.Errorf("GET serial log: %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxBootLogChunk))
+}
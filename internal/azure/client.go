@@ -4,19 +4,23 @@ import (
 	"context"
 	"strings"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// subscriptionsAPIVersion is a version of the subscriptions listing that
+// carries each subscription's tenant.
+const subscriptionsAPIVersion = "2022-12-01"
+
 func FetchSubscriptions() tea.Msg {
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	cred, err := credential()
 	if err != nil {
 		return ErrorMsg{err}
 	}
 
-	client, err := armsubscription.NewSubscriptionsClient(cred, nil)
+	client, err := armsubscription.NewSubscriptionsClient(cred, armOptions())
 	if err != nil {
 		return ErrorMsg{err}
 	}
@@ -34,59 +38,79 @@ func FetchSubscriptions() tea.Msg {
 		}
 	}
 
-	return SubscriptionsMsg{Subs: subs}
+	tenantID := Tenant()
+	if tenantID == "" {
+		if claims, err := currentClaims(context.Background(), cred); err == nil {
+			tenantID = claims.TenantID
+		}
+	}
+
+	// armsubscription's Subscription has no tenant ID, so the tenants come
+	// from the REST listing. The view shows a dash for any it cannot read.
+	tenants, _ := subscriptionTenants(context.Background())
+
+	return SubscriptionsMsg{Subs: subs, TenantID: tenantID, Tenants: tenants}
+}
+
+// subscriptionTenants maps the ID of every subscription the caller can see
+// to the tenant it belongs to.
+func subscriptionTenants(ctx context.Context) (map[string]string, error) {
+	type subscriptionPage struct {
+		Value []struct {
+			SubscriptionID string `json:"subscriptionId"`
+			TenantID       string `json:"tenantId"`
+		} `json:"value"`
+		NextLink string `json:"nextLink"`
+	}
+
+	tenants := map[string]string{}
+	var page subscriptionPage
+	err := armGet(ctx, "/subscriptions", subscriptionsAPIVersion, nil, &page)
+	for err == nil {
+		for _, sub := range page.Value {
+			if sub.TenantID != "" {
+				tenants[sub.SubscriptionID] = sub.TenantID
+			}
+		}
+		if page.NextLink == "" {
+			return tenants, nil
+		}
+		next := page.NextLink
+		page = subscriptionPage{}
+		err = armGetNext(ctx, next, &page)
+	}
+	return tenants, err
 }
 
 func FetchResourceGroups(subscriptionID string) tea.Cmd {
 	return func() tea.Msg {
-		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		cred, err := credential()
 		if err != nil {
 			return ErrorMsg{err}
 		}
 
-		subscriptionID = strings.TrimSpace(subscriptionID)
-		if strings.HasPrefix(subscriptionID, "/subscriptions/") {
-			subscriptionID = strings.TrimPrefix(subscriptionID, "/subscriptions/")
-		}
-
-		client, err := armresources.NewResourceGroupsClient(subscriptionID, cred, nil)
+		subscriptionID = normalizeSubscriptionID(subscriptionID)
+		groups, err := listResourceGroups(context.Background(), cred, subscriptionID)
 		if err != nil {
 			return ErrorMsg{err}
 		}
 
-		pager := client.NewListPager(&armresources.ResourceGroupsClientListOptions{})
-		var groups []armresources.ResourceGroup
-
-		for pager.More() {
-			page, err := pager.NextPage(context.Background())
-			if err != nil {
-				return ErrorMsg{err}
-			}
-			for _, group := range page.Value {
-				groups = append(groups, *group)
-			}
-		}
-
 		return ResourceGroupsMsg{
 			SubscriptionID: subscriptionID,
-			Groups:        groups,
+			Groups:         groups,
 		}
 	}
 }
 
 func FetchResources(subscriptionID, resourceGroupName string) tea.Cmd {
 	return func() tea.Msg {
-		cred, err := azidentity.NewDefaultAzureCredential(nil)
+		cred, err := credential()
 		if err != nil {
 			return ErrorMsg{err}
 		}
 
-		subscriptionID = strings.TrimSpace(subscriptionID)
-		if strings.HasPrefix(subscriptionID, "/subscriptions/") {
-			subscriptionID = strings.TrimPrefix(subscriptionID, "/subscriptions/")
-		}
-
-		client, err := armresources.NewClient(subscriptionID, cred, nil)
+		subscriptionID = normalizeSubscriptionID(subscriptionID)
+		client, err := armresources.NewClient(subscriptionID, cred, armOptions())
 		if err != nil {
 			return ErrorMsg{err}
 		}
@@ -106,7 +130,58 @@ func FetchResources(subscriptionID, resourceGroupName string) tea.Cmd {
 
 		return ResourcesMsg{
 			ResourceGroupName: resourceGroupName,
-			Resources:        resources,
+			Resources:         resources,
 		}
 	}
-} 
\ No newline at end of file
+}
+
+func normalizeSubscriptionID(subscriptionID string) string {
+	subscriptionID = strings.TrimSpace(subscriptionID)
+	return strings.TrimPrefix(subscriptionID, "/subscriptions/")
+}
+
+func listResourceGroups(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) ([]armresources.ResourceGroup, error) {
+	client, err := armresources.NewResourceGroupsClient(subscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(&armresources.ResourceGroupsClientListOptions{})
+	var groups []armresources.ResourceGroup
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range page.Value {
+			groups = append(groups, *group)
+		}
+	}
+
+	return groups, nil
+}
+
+// listSubscriptionResources lists every resource in a subscription, across all
+// of its resource groups.
+func listSubscriptionResources(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) ([]armresources.GenericResourceExpanded, error) {
+	client, err := armresources.NewClient(subscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(nil)
+	var resources []armresources.GenericResourceExpanded
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range page.Value {
+			resources = append(resources, *resource)
+		}
+	}
+
+	return resources, nil
+}
@@ -0,0 +1,94 @@
+package azure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// Cloud names accepted by ParseCloud.
+const (
+	CloudPublic          = "AzurePublic"
+	CloudChina           = "AzureChina"
+	CloudUSGovernment    = "AzureUSGovernment"
+	CloudCustom          = "Custom"
+	defaultARMEndpoint   = "https://management.azure.com"
+	defaultAuthorityHost = "https://login.microsoftonline.com/"
+)
+
+// ParseCloud resolves a cloud name to its endpoint configuration. A custom
+// cloud (Azure Stack Hub or a private deployment) needs both the ARM endpoint
+// and the authority host; the ARM endpoint doubles as the token audience.
+func ParseCloud(name, armEndpoint, authorityHost string) (cloud.Configuration, error) {
+	switch strings.ToLower(name) {
+	case "", "azurepublic", "azurecloud", "public":
+		return cloud.AzurePublic, nil
+	case "azurechina", "azurechinacloud", "china":
+		return cloud.AzureChina, nil
+	case "azureusgovernment", "azuregovernment", "usgovernment", "usgov":
+		return cloud.AzureGovernment, nil
+	case "custom":
+		if armEndpoint == "" || authorityHost == "" {
+			return cloud.Configuration{}, fmt.Errorf("custom cloud needs both an ARM endpoint and an authority host")
+		}
+		return cloud.Configuration{
+			ActiveDirectoryAuthorityHost: authorityHost,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Endpoint: armEndpoint,
+					Audience: armEndpoint,
+				},
+			},
+		}, nil
+	default:
+		return cloud.Configuration{}, fmt.Errorf("unknown cloud %q (want %s, %s, %s or %s)",
+			name, CloudPublic, CloudChina, CloudUSGovernment, CloudCustom)
+	}
+}
+
+// SetCloud points the credential and every ARM client at the given cloud.
+func SetCloud(cfg cloud.Configuration) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	session.cloud = cfg
+	session.cred = nil
+}
+
+func currentCloud() cloud.Configuration {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.cloud.ActiveDirectoryAuthorityHost == "" {
+		return cloud.AzurePublic
+	}
+	return session.cloud
+}
+
+// armOptions returns the client options every ARM client is created with.
+func armOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{Cloud: currentCloud()},
+	}
+}
+
+// ARMEndpoint returns the Resource Manager endpoint of the selected cloud.
+func ARMEndpoint() string {
+	if svc, ok := currentCloud().Services[cloud.ResourceManager]; ok && svc.Endpoint != "" {
+		return strings.TrimSuffix(svc.Endpoint, "/")
+	}
+	return defaultARMEndpoint
+}
+
+// managementScope is the token scope for Resource Manager in the selected
+// cloud.
+func managementScope() string {
+	audience := defaultARMEndpoint
+	if svc, ok := currentCloud().Services[cloud.ResourceManager]; ok && svc.Audience != "" {
+		audience = svc.Audience
+	}
+	return strings.TrimSuffix(audience, "/") + "/.default"
+}
@@ -0,0 +1,57 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+func TestParseCloud(t *testing.T) {
+	tests := []struct {
+		name          string
+		cloud         string
+		armEndpoint   string
+		authorityHost string
+		wantAuthority string
+		wantErr       bool
+	}{
+		{name: "Default", cloud: "", wantAuthority: cloud.AzurePublic.ActiveDirectoryAuthorityHost},
+		{name: "China", cloud: "AzureChina", wantAuthority: cloud.AzureChina.ActiveDirectoryAuthorityHost},
+		{name: "US Government", cloud: "azureusgovernment", wantAuthority: cloud.AzureGovernment.ActiveDirectoryAuthorityHost},
+		{name: "Custom", cloud: "Custom", armEndpoint: "https://management.local", authorityHost: "https://login.local/", wantAuthority: "https://login.local/"},
+		{name: "Custom without endpoint", cloud: "Custom", authorityHost: "https://login.local/", wantErr: true},
+		{name: "Unknown", cloud: "Mars", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseCloud(tt.cloud, tt.armEndpoint, tt.authorityHost)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCloud() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.ActiveDirectoryAuthorityHost != tt.wantAuthority {
+				t.Errorf("authority = %q, want %q", cfg.ActiveDirectoryAuthorityHost, tt.wantAuthority)
+			}
+		})
+	}
+}
+
+func TestSetCloudScopesClients(t *testing.T) {
+	defer SetCloud(cloud.Configuration{})
+
+	cfg, err := ParseCloud("Custom", "https://management.local/", "https://login.local/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetCloud(cfg)
+
+	if got := ARMEndpoint(); got != "https://management.local" {
+		t.Errorf("ARMEndpoint() = %q, want %q", got, "https://management.local")
+	}
+	if got := managementScope(); got != "https://management.local/.default" {
+		t.Errorf("managementScope() = %q, want %q", got, "https://management.local/.default")
+	}
+	if got := armOptions().Cloud.ActiveDirectoryAuthorityHost; got != "https://login.local/" {
+		t.Errorf("armOptions() authority = %q, want %q", got, "https://login.local/")
+	}
+}
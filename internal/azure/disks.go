@@ -0,0 +1,173 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func newDiskClients(subscriptionID string) (*armcompute.DisksClient, *armcompute.SnapshotsClient, error) {
+	cred, err := credential()
+	if err != nil {
+		return nil, nil, err
+	}
+	disks, err := armcompute.NewDisksClient(subscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshots, err := armcompute.NewSnapshotsClient(subscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+	return disks, snapshots, nil
+}
+
+// FetchDisks lists the managed disks and snapshots of a resource group, or
+// of the whole subscription when resourceGroup is empty.
+func FetchDisks(subscriptionID, resourceGroup string) tea.Cmd {
+	return func() tea.Msg {
+		disks, snapshots, err := newDiskClients(normalizeSubscriptionID(subscriptionID))
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		msg := DisksMsg{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup}
+
+		if resourceGroup != "" {
+			pager := disks.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				msg.Disks = append(msg.Disks, page.Value...)
+			}
+			snapshotPager := snapshots.NewListByResourceGroupPager(resourceGroup, nil)
+			for snapshotPager.More() {
+				page, err := snapshotPager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				msg.Snapshots = append(msg.Snapshots, page.Value...)
+			}
+			return msg
+		}
+
+		pager := disks.NewListPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			msg.Disks = append(msg.Disks, page.Value...)
+		}
+		snapshotPager := snapshots.NewListPager(nil)
+		for snapshotPager.More() {
+			page, err := snapshotPager.NextPage(ctx)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			msg.Snapshots = append(msg.Snapshots, page.Value...)
+		}
+		return msg
+	}
+}
+
+// SnapshotDisk takes an incremental snapshot of a disk into the disk's
+// resource group.
+func SnapshotDisk(opID int, diskID, snapshotName string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(diskID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		disks, snapshots, err := newDiskClients(id.SubscriptionID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		ctx := context.Background()
+		disk, err := disks.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		poller, err := snapshots.BeginCreateOrUpdate(ctx, id.ResourceGroupName, snapshotName, armcompute.Snapshot{
+			Location: disk.Location,
+			SKU:      &armcompute.SnapshotSKU{Name: to.Ptr(armcompute.SnapshotStorageAccountTypesStandardLRS)},
+			Properties: &armcompute.SnapshotProperties{
+				Incremental: to.Ptr(true),
+				CreationData: &armcompute.CreationData{
+					CreateOption:     to.Ptr(armcompute.DiskCreateOptionCopy),
+					SourceResourceID: &diskID,
+				},
+			},
+		}, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// CreateDiskFromSnapshot creates a managed disk with the given SKU from a
+// snapshot, in the snapshot's resource group and location.
+func CreateDiskFromSnapshot(opID int, snapshotID, diskName string, sku armcompute.DiskStorageAccountTypes) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(snapshotID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		disks, snapshots, err := newDiskClients(id.SubscriptionID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		ctx := context.Background()
+		snapshot, err := snapshots.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+
+		poller, err := disks.BeginCreateOrUpdate(ctx, id.ResourceGroupName, diskName, armcompute.Disk{
+			Location: snapshot.Location,
+			SKU:      &armcompute.DiskSKU{Name: &sku},
+			Properties: &armcompute.DiskProperties{
+				CreationData: &armcompute.CreationData{
+					CreateOption:     to.Ptr(armcompute.DiskCreateOptionCopy),
+					SourceResourceID: &snapshotID,
+				},
+			},
+		}, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// ResizeDisk grows a disk to sizeGB. Disks can only grow, and most attached
+// disks need their VM deallocated first.
+func ResizeDisk(opID int, diskID string, sizeGB int32) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(diskID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		disks, _, err := newDiskClients(id.SubscriptionID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := disks.BeginUpdate(context.Background(), id.ResourceGroupName, id.Name, armcompute.DiskUpdate{
+			Properties: &armcompute.DiskUpdateProperties{DiskSizeGB: &sizeGB},
+		}, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
@@ -0,0 +1,11 @@
+// Package azure talks to Azure for the app: each Fetch function returns a
+// tea.Cmd whose message carries the result or an ErrorMsg.
+//
+// Most APIs go through the SDK modules pinned in go.mod. The queue and
+// table services, the Key Vault data plane and a few Resource Manager reads
+// have no client among those modules, and the module has to build from
+// that pinned set alone, without downloading azqueue, aztables, the Key
+// Vault SDKs or armkeyvault. Those APIs are spoken over REST instead, by
+// storagerest.go, keyvault.go and armGet, with the same credential and
+// cloud as the SDK clients.
+package azure
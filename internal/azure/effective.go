@@ -0,0 +1,149 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchEffectiveNetwork computes the security rules and routes in force on
+// a network interface, or on the primary interface of a virtual machine.
+// Both are long-running operations that only succeed while the interface is
+// attached to a running VM; each fails on its own, so one can be shown when
+// the other is refused.
+func FetchEffectiveNetwork(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		nicID, err := primaryInterface(ctx, resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		id, err := arm.ParseResourceID(nicID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		nics, err := armnetwork.NewInterfacesClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		nic, err := nics.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := EffectiveNetworkMsg{ResourceID: resourceID, NIC: nic.Interface}
+		msg.Subnet, msg.RouteTable = interfaceSubnet(ctx, nic.Interface)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			poller, err := nics.BeginListEffectiveNetworkSecurityGroups(ctx, id.ResourceGroupName, id.Name, nil)
+			if err != nil {
+				msg.RulesErr = err
+				return
+			}
+			resp, err := poller.PollUntilDone(ctx, nil)
+			if err != nil {
+				msg.RulesErr = err
+				return
+			}
+			msg.SecurityGroups = resp.Value
+		}()
+		go func() {
+			defer wg.Done()
+			poller, err := nics.BeginGetEffectiveRouteTable(ctx, id.ResourceGroupName, id.Name, nil)
+			if err != nil {
+				msg.RoutesErr = err
+				return
+			}
+			resp, err := poller.PollUntilDone(ctx, nil)
+			if err != nil {
+				msg.RoutesErr = err
+				return
+			}
+			msg.Routes = resp.Value
+		}()
+		wg.Wait()
+		return msg
+	}
+}
+
+// primaryInterface returns resourceID itself for a network interface, and
+// the primary (or only) interface for a virtual machine.
+func primaryInterface(ctx context.Context, resourceID string) (string, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return "", err
+	}
+	if !strings.EqualFold(id.ResourceType.String(), "Microsoft.Compute/virtualMachines") {
+		return resourceID, nil
+	}
+	client, err := newVirtualMachinesClient(id.SubscriptionID)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	nicID := ""
+	if props := resp.Properties; props != nil && props.NetworkProfile != nil {
+		for _, ref := range props.NetworkProfile.NetworkInterfaces {
+			if ref == nil || ref.ID == nil {
+				continue
+			}
+			if nicID == "" || (ref.Properties != nil && ref.Properties.Primary != nil && *ref.Properties.Primary) {
+				nicID = *ref.ID
+			}
+		}
+	}
+	if nicID == "" {
+		return "", fmt.Errorf("virtual machine %s has no network interface", id.Name)
+	}
+	return nicID, nil
+}
+
+// interfaceSubnet returns the subnet of a network interface's primary IP
+// configuration and the route table associated with it, whose routes show
+// up as user routes. Either is "" when it cannot be resolved.
+func interfaceSubnet(ctx context.Context, nic armnetwork.Interface) (string, string) {
+	if nic.Properties == nil {
+		return "", ""
+	}
+	subnetID := ""
+	for _, config := range nic.Properties.IPConfigurations {
+		if config == nil || config.Properties == nil || config.Properties.Subnet == nil || config.Properties.Subnet.ID == nil {
+			continue
+		}
+		if subnetID == "" || (config.Properties.Primary != nil && *config.Properties.Primary) {
+			subnetID = *config.Properties.Subnet.ID
+		}
+	}
+	id, err := arm.ParseResourceID(subnetID)
+	if err != nil || id.Parent == nil {
+		return subnetID, ""
+	}
+	cred, err := credential()
+	if err != nil {
+		return subnetID, ""
+	}
+	subnets, err := armnetwork.NewSubnetsClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return subnetID, ""
+	}
+	resp, err := subnets.Get(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	if err != nil || resp.Properties == nil || resp.Properties.RouteTable == nil {
+		return subnetID, ""
+	}
+	return subnetID, deref(resp.Properties.RouteTable.ID)
+}
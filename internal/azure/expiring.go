@@ -0,0 +1,265 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const appServiceCertificateAPIVersion = "2022-09-01"
+
+// Items expiring within these many days are critical or a warning.
+const (
+	ExpiryCriticalDays = 7
+	ExpiryWarningDays  = 30
+)
+
+// ExpiringItem is a Key Vault secret, key or certificate, or an App Service
+// certificate, that expires within the scanned window or already has.
+// Resource is the vault or certificate resource it belongs to.
+type ExpiringItem struct {
+	SubscriptionID string    `json:"subscriptionId"`
+	ResourceGroup  string    `json:"resourceGroup"`
+	Resource       string    `json:"resourceId"`
+	Vault          string    `json:"vault,omitempty"`
+	Kind           string    `json:"kind"`
+	Name           string    `json:"name"`
+	Enabled        bool      `json:"enabled"`
+	Expires        time.Time `json:"expires"`
+	DaysLeft       int       `json:"daysLeft"`
+	Status         string    `json:"status"`
+}
+
+// Kinds of expiring items besides the Key Vault kinds.
+const (
+	KindAppServiceCertificate = "appservice-certificate"
+	KindCertificateOrder      = "appservice-certificate-order"
+)
+
+// ExpiryStatus classifies the days left before an expiry: expired,
+// critical, warning or ok.
+func ExpiryStatus(daysLeft int) string {
+	switch {
+	case daysLeft < 0:
+		return "expired"
+	case daysLeft <= ExpiryCriticalDays:
+		return "critical"
+	case daysLeft <= ExpiryWarningDays:
+		return "warning"
+	default:
+		return "ok"
+	}
+}
+
+// ExpiryScan is the result of scanning subscriptions for expiring items.
+// Errors holds the subscriptions, vaults and certificates that could not be
+// read, keyed by their ID. Unlisted holds the kinds of item a vault does
+// not let the caller list, keyed by the vault ID and kind, such as
+// .../vaults/kv-app/keys; the rest of such a vault is scanned.
+type ExpiryScan struct {
+	Items    []ExpiringItem
+	Errors   map[string]error
+	Unlisted map[string]error
+}
+
+// FetchExpiring scans the key vaults and App Service certificates of the
+// given subscriptions for items expiring within days.
+func FetchExpiring(subscriptionIDs []string, days int) tea.Cmd {
+	return func() tea.Msg {
+		scan, err := ScanExpiring(context.Background(), subscriptionIDs, days)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		return ExpiringMsg{SubscriptionIDs: subscriptionIDs, Days: days, Items: scan.Items, Errors: scan.Errors, Unlisted: scan.Unlisted}
+	}
+}
+
+// ScanExpiring returns the items of the given subscriptions that expire
+// within days, soonest first, without stopping at what could not be read.
+// The subscriptions are listed first and their vaults and certificates read
+// afterwards by one shared pool, so a large tenant never has more than
+// maxConcurrentFetches requests in flight.
+func ScanExpiring(ctx context.Context, subscriptionIDs []string, days int) (ExpiryScan, error) {
+	cred, err := credential()
+	if err != nil {
+		return ExpiryScan{}, err
+	}
+
+	var (
+		mu        sync.Mutex
+		ids       []string
+		resources = map[string]expiryResource{}
+	)
+	errs := forEachSubscription(subscriptionIDs, maxConcurrentFetches, func(subscriptionID string) error {
+		found, err := expiryResources(ctx, cred, subscriptionID)
+		if err != nil {
+			return err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		for id, resource := range found {
+			resources[id] = resource
+			ids = append(ids, id)
+		}
+		return nil
+	})
+
+	scan := ExpiryScan{Errors: errs, Unlisted: map[string]error{}}
+	resourceErrs := forEachID(ids, maxConcurrentFetches, func(resourceID string) error {
+		found, unlisted, err := resourceExpiry(ctx, cred, resourceID, resources[resourceID])
+		mu.Lock()
+		defer mu.Unlock()
+		scan.Items = append(scan.Items, found...)
+		for kind, err := range unlisted {
+			scan.Unlisted[resourceID+"/"+kind] = err
+		}
+		return err
+	})
+	for id, err := range resourceErrs {
+		scan.Errors[id] = err
+	}
+	scan.Items = selectExpiring(scan.Items, time.Now(), days)
+	return scan, nil
+}
+
+// expiryResource is a vault or certificate found by expiryResources.
+type expiryResource struct {
+	subscriptionID string
+	resourceType   string
+}
+
+// expiryResources lists the key vaults, App Service certificates and
+// certificate orders of one subscription, keyed by resource ID.
+func expiryResources(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) (map[string]expiryResource, error) {
+	listed, err := listSubscriptionResources(ctx, cred, normalizeSubscriptionID(subscriptionID))
+	if err != nil {
+		return nil, err
+	}
+	found := map[string]expiryResource{}
+	for _, resource := range listed {
+		if resource.ID == nil || resource.Type == nil {
+			continue
+		}
+		switch resourceType := strings.ToLower(*resource.Type); resourceType {
+		case "microsoft.keyvault/vaults", "microsoft.web/certificates", "microsoft.certificateregistration/certificateorders":
+			found[*resource.ID] = expiryResource{subscriptionID: subscriptionID, resourceType: resourceType}
+		}
+	}
+	return found, nil
+}
+
+// resourceExpiry lists every expiring item of one vault or certificate,
+// whatever its expiry, with the kinds of vault item it may not list.
+func resourceExpiry(ctx context.Context, cred azcore.TokenCredential, resourceID string, resource expiryResource) ([]ExpiringItem, map[string]error, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	base := ExpiringItem{SubscriptionID: resource.subscriptionID, ResourceGroup: id.ResourceGroupName, Resource: resourceID}
+	if resource.resourceType == "microsoft.keyvault/vaults" {
+		return vaultExpiry(ctx, cred, base, id.Name)
+	}
+	item, err := appServiceCertificateExpiry(ctx, base, id.Name, resource.resourceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return []ExpiringItem{item}, nil, nil
+}
+
+// vaultExpiry lists the secrets, keys and certificates of a vault that have
+// an expiry. The secret and key behind a certificate expire with it and are
+// left out. Kinds the caller is forbidden to list are returned by kind
+// rather than failing the vault, since data plane roles such as Key Vault
+// Secrets User only cover one kind.
+func vaultExpiry(ctx context.Context, cred azcore.TokenCredential, base ExpiringItem, vaultName string) ([]ExpiringItem, map[string]error, error) {
+	uri, err := keyVaultURI(ctx, base.Resource)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []ExpiringItem
+	var errs []error
+	unlisted := map[string]error{}
+	for _, kind := range VaultKinds {
+		listed, err := listVaultItems(ctx, cred, uri, kind)
+		if isVaultForbidden(err) {
+			unlisted[kind] = err
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("listing %s: %w", kind, err))
+			continue
+		}
+		for _, vaultItem := range listed {
+			if vaultItem.Managed || vaultItem.Expires.IsZero() {
+				continue
+			}
+			item := base
+			item.Vault = vaultName
+			item.Kind = strings.TrimSuffix(kind, "s")
+			item.Name = vaultItem.Name
+			item.Enabled = vaultItem.Enabled
+			item.Expires = vaultItem.Expires
+			items = append(items, item)
+		}
+	}
+	return items, unlisted, errors.Join(errs...)
+}
+
+// appServiceCertificateExpiry reads the expiry of an App Service
+// certificate or certificate order.
+func appServiceCertificateExpiry(ctx context.Context, base ExpiringItem, name, resourceType string) (ExpiringItem, error) {
+	var certificate struct {
+		Properties struct {
+			ExpirationDate *time.Time `json:"expirationDate"`
+			ExpirationTime *time.Time `json:"expirationTime"`
+		} `json:"properties"`
+	}
+	if err := armGet(ctx, base.Resource, appServiceCertificateAPIVersion, nil, &certificate); err != nil {
+		return base, err
+	}
+
+	item := base
+	item.Name = name
+	item.Enabled = true
+	item.Kind = KindAppServiceCertificate
+	expires := certificate.Properties.ExpirationDate
+	if strings.EqualFold(resourceType, "microsoft.certificateregistration/certificateorders") {
+		item.Kind = KindCertificateOrder
+		expires = certificate.Properties.ExpirationTime
+	}
+	if expires != nil {
+		item.Expires = *expires
+	}
+	return item, nil
+}
+
+// selectExpiring keeps the items with an expiry before days from now,
+// expired ones included, sets their days left and status, and sorts them
+// soonest first.
+func selectExpiring(items []ExpiringItem, now time.Time, days int) []ExpiringItem {
+	deadline := now.AddDate(0, 0, days)
+	var selected []ExpiringItem
+	for _, item := range items {
+		if item.Expires.IsZero() || item.Expires.After(deadline) {
+			continue
+		}
+		left := item.Expires.Sub(now)
+		item.DaysLeft = int(left / (24 * time.Hour))
+		if left < 0 {
+			item.DaysLeft = -int((-left + 24*time.Hour - 1) / (24 * time.Hour))
+		}
+		item.Status = ExpiryStatus(item.DaysLeft)
+		selected = append(selected, item)
+	}
+	sort.SliceStable(selected, func(i, j int) bool { return selected[i].Expires.Before(selected[j].Expires) })
+	return selected
+}
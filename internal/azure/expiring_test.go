@@ -0,0 +1,42 @@
+package azure
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSelectExpiring(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	items := []ExpiringItem{
+		{Name: "later", Expires: now.AddDate(0, 0, 20)},
+		{Name: "never"},
+		{Name: "outside", Expires: now.AddDate(0, 0, 31)},
+		{Name: "expired", Expires: now.Add(-time.Hour)},
+		{Name: "soon", Expires: now.Add(3*24*time.Hour + time.Hour)},
+		{Name: "today", Expires: now.Add(5 * time.Hour)},
+	}
+
+	got := selectExpiring(items, now, 30)
+	want := []struct {
+		name   string
+		days   int
+		status string
+	}{
+		{"expired", -1, "expired"},
+		{"today", 0, "critical"},
+		{"soon", 3, "critical"},
+		{"later", 20, "warning"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("selectExpiring() = %+v, want %d items", got, len(want))
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].DaysLeft != w.days || got[i].Status != w.status {
+			t.Errorf("item %d = %s, %d days, %s; want %s, %d days, %s", i, got[i].Name, got[i].DaysLeft, got[i].Status, w.name, w.days, w.status)
+		}
+	}
+
+	if got := selectExpiring(items, now, 60); len(got) != 5 || got[4].Name != "outside" || got[4].Status != "ok" {
+		t.Errorf("a 60 day window selected %+v", got)
+	}
+}
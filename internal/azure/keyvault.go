@@ -0,0 +1,339 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keyVaultARMAPIVersion reads the vault resource; keyVaultAPIVersion is
+// the data plane version.
+const (
+	keyVaultARMAPIVersion = "2023-07-01"
+	keyVaultAPIVersion    = "7.4"
+)
+
+// vaultClient gives up on a vault that stops answering rather than leaving
+// the browser loading forever.
+var vaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Kinds of Key Vault items, as they appear in data plane paths.
+const (
+	VaultSecrets      = "secrets"
+	VaultKeys         = "keys"
+	VaultCertificates = "certificates"
+)
+
+// VaultKinds lists the item kinds in display order.
+var VaultKinds = []string{VaultSecrets, VaultKeys, VaultCertificates}
+
+// VaultItem is a secret, key or certificate, or one version of it. Version
+// is only set when listing versions. Zero times are not set on the item.
+type VaultItem struct {
+	ID          string
+	Name        string
+	Version     string
+	Enabled     bool
+	Expires     time.Time
+	Created     time.Time
+	Updated     time.Time
+	ContentType string
+	// Managed is set on the secret and key that back a certificate.
+	Managed bool
+}
+
+// vaultItemJSON is an item of a data plane listing. Keys carry their ID in
+// kid, secrets and certificates in id.
+type vaultItemJSON struct {
+	ID          string `json:"id"`
+	KID         string `json:"kid"`
+	ContentType string `json:"contentType"`
+	Managed     bool   `json:"managed"`
+	Attributes  struct {
+		Enabled *bool `json:"enabled"`
+		Expires int64 `json:"exp"`
+		Created int64 `json:"created"`
+		Updated int64 `json:"updated"`
+	} `json:"attributes"`
+}
+
+// FetchKeyVault looks up a vault's URI and lists its secrets, keys and
+// certificates. Kinds the caller may not list are reported in Errors.
+func FetchKeyVault(vaultID string) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		uri, err := keyVaultURI(ctx, vaultID)
+		if err != nil {
+			return ErrorMsg{fmt.Errorf("failed to get key vault: %w", err)}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := KeyVaultMsg{ID: vaultID, URI: uri, Items: map[string][]VaultItem{}}
+		for _, kind := range VaultKinds {
+			items, err := listVaultItems(ctx, cred, uri, kind)
+			if err != nil {
+				if msg.Errors == nil {
+					msg.Errors = map[string]error{}
+				}
+				msg.Errors[kind] = err
+				continue
+			}
+			msg.Items[kind] = items
+		}
+		return msg
+	}
+}
+
+// FetchVaultVersions lists the versions of a secret, key or certificate,
+// newest first.
+func FetchVaultVersions(vaultID, uri, kind, name string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		versions, err := listVaultVersions(context.Background(), cred, uri, kind, name)
+		if err != nil {
+			return ErrorMsg{fmt.Errorf("failed to list versions of %s: %w", name, err)}
+		}
+		return VaultVersionsMsg{ID: vaultID, Kind: kind, Name: name, Versions: versions}
+	}
+}
+
+// FetchSecretValue reads a secret's value; an empty version reads the
+// current one. The reply carries copy so that it is copied, not shown.
+func FetchSecretValue(vaultID, uri, name, version string, copy bool) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		value, err := secretValue(context.Background(), cred, uri, name, version)
+		if err != nil {
+			return ErrorMsg{fmt.Errorf("failed to read secret %s: %w", name, err)}
+		}
+		return SecretValueMsg{ID: vaultID, Name: name, Version: version, Value: value, Copy: copy}
+	}
+}
+
+// keyVaultURI returns the data plane URI of a vault, such as
+// https://kv-app.vault.azure.net/.
+func keyVaultURI(ctx context.Context, vaultID string) (string, error) {
+	var vault struct {
+		Properties struct {
+			VaultURI string `json:"vaultUri"`
+		} `json:"properties"`
+	}
+	if err := armGet(ctx, vaultID, keyVaultARMAPIVersion, nil, &vault); err != nil {
+		return "", err
+	}
+	if vault.Properties.VaultURI == "" {
+		return "", fmt.Errorf("%s has no vault URI", vaultID)
+	}
+	return vault.Properties.VaultURI, nil
+}
+
+// listVaultItems lists every item of one kind in a vault, sorted by name.
+func listVaultItems(ctx context.Context, cred azcore.TokenCredential, uri, kind string) ([]VaultItem, error) {
+	items, err := vaultList(ctx, cred, uri, strings.TrimSuffix(uri, "/")+"/"+kind)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(items, func(i, j int) bool { return strings.ToLower(items[i].Name) < strings.ToLower(items[j].Name) })
+	return items, nil
+}
+
+// listVaultVersions lists the versions of one item, newest first.
+func listVaultVersions(ctx context.Context, cred azcore.TokenCredential, uri, kind, name string) ([]VaultItem, error) {
+	versions, err := vaultList(ctx, cred, uri, strings.TrimSuffix(uri, "/")+"/"+kind+"/"+url.PathEscape(name)+"/versions")
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(versions, func(i, j int) bool { return versions[i].Created.After(versions[j].Created) })
+	return versions, nil
+}
+
+// vaultList reads a data plane listing, following its next links.
+func vaultList(ctx context.Context, cred azcore.TokenCredential, uri, next string) ([]VaultItem, error) {
+	var items []VaultItem
+	for next != "" {
+		data, err := vaultRequest(ctx, cred, uri, next)
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Value    []vaultItemJSON `json:"value"`
+			NextLink string          `json:"nextLink"`
+		}
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", next, err)
+		}
+		for _, raw := range page.Value {
+			items = append(items, raw.item())
+		}
+		if page.NextLink != "" && !sameHost(page.NextLink, uri) {
+			return nil, fmt.Errorf("refusing next link %s outside the vault %s", page.NextLink, uri)
+		}
+		next = page.NextLink
+	}
+	return items, nil
+}
+
+// sameHost reports whether two URLs name the same host and port, so that
+// the vault's token is only ever sent to the vault.
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Scheme == ub.Scheme && strings.EqualFold(ua.Host, ub.Host)
+}
+
+func (raw vaultItemJSON) item() VaultItem {
+	id := raw.ID
+	if id == "" {
+		id = raw.KID
+	}
+	item := VaultItem{
+		ID:          id,
+		Enabled:     raw.Attributes.Enabled == nil || *raw.Attributes.Enabled,
+		Expires:     unixTime(raw.Attributes.Expires),
+		Created:     unixTime(raw.Attributes.Created),
+		Updated:     unixTime(raw.Attributes.Updated),
+		ContentType: raw.ContentType,
+		Managed:     raw.Managed,
+	}
+	// IDs are https://{vault}/{kind}/{name}[/{version}].
+	if u, err := url.Parse(id); err == nil {
+		parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(parts) > 1 {
+			item.Name = parts[1]
+		}
+		if len(parts) > 2 {
+			item.Version = parts[2]
+		}
+	}
+	return item
+}
+
+func unixTime(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(seconds, 0)
+}
+
+// secretValue reads a secret's value.
+func secretValue(ctx context.Context, cred azcore.TokenCredential, uri, name, version string) (string, error) {
+	path := strings.TrimSuffix(uri, "/") + "/secrets/" + url.PathEscape(name)
+	if version != "" {
+		path += "/" + url.PathEscape(version)
+	}
+	data, err := vaultRequest(ctx, cred, uri, path)
+	if err != nil {
+		return "", err
+	}
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &secret); err != nil {
+		return "", fmt.Errorf("decoding secret: %w", err)
+	}
+	return secret.Value, nil
+}
+
+// vaultRequest sends a GET to the data plane of the vault at uri with an
+// Entra ID token for it and returns the response body. Non-2xx responses
+// are errors carrying the vault's error code and message.
+func vaultRequest(ctx context.Context, cred azcore.TokenCredential, uri, rawURL string) ([]byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	if query.Get("api-version") == "" {
+		query.Set("api-version", keyVaultAPIVersion)
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{vaultScope(uri)}})
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := vaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		var failure struct {
+			Error struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Code != "" {
+			return nil, &vaultError{status: resp.StatusCode, message: fmt.Sprintf("%s: %s", failure.Error.Code, failure.Error.Message)}
+		}
+		return nil, &vaultError{status: resp.StatusCode, message: fmt.Sprintf("GET %s: %s", u.Path, resp.Status)}
+	}
+	return data, nil
+}
+
+// vaultError is a non-2xx response of a vault's data plane.
+type vaultError struct {
+	status  int
+	message string
+}
+
+func (e *vaultError) Error() string { return e.message }
+
+// isVaultForbidden reports whether err is a vault refusing the caller.
+func isVaultForbidden(err error) bool {
+	var vaultErr *vaultError
+	return errors.As(err, &vaultErr) && vaultErr.status == http.StatusForbidden
+}
+
+// vaultScope is the token scope of a vault's data plane: its DNS suffix,
+// e.g. https://vault.azure.net/.default for kv-app.vault.azure.net, so that
+// sovereign clouds get tokens for their own vaults.
+func vaultScope(uri string) string {
+	host := uri
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		host = u.Hostname()
+	}
+	if _, suffix, ok := strings.Cut(host, "."); ok {
+		host = suffix
+	}
+	return "https://" + host + "/.default"
+}
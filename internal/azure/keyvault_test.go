@@ -0,0 +1,113 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// staticCredential hands out a fixed token.
+type staticCredential struct{}
+
+func (staticCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "vault-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func TestVaultDataPlane(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer vault-token" {
+			t.Errorf("%s sent without the vault token", r.URL)
+		}
+		if r.URL.Query().Get("api-version") != keyVaultAPIVersion {
+			t.Errorf("%s sent without api-version", r.URL)
+		}
+		switch r.URL.Path {
+		case "/secrets":
+			if r.URL.Query().Get("page") == "" {
+				fmt.Fprintf(w, `{"value":[{"id":"%[1]s/secrets/db-password","attributes":{"enabled":true,"exp":1767225600,"created":1704067200,"updated":1704067200}}],"nextLink":"%[1]s/secrets?api-version=%[2]s&page=2"}`, server.URL, keyVaultAPIVersion)
+				return
+			}
+			fmt.Fprintf(w, `{"value":[{"id":"%s/secrets/api-key","contentType":"text/plain","attributes":{"enabled":false,"created":1704067200,"updated":1704067200}}],"nextLink":null}`, server.URL)
+		case "/escape":
+			fmt.Fprint(w, `{"value":[],"nextLink":"https://attacker.example/steal"}`)
+		case "/keys":
+			fmt.Fprintf(w, `{"value":[{"kid":"%s/keys/signing","managed":true,"attributes":{"enabled":true}}]}`, server.URL)
+		case "/certificates":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"error":{"code":"Forbidden","message":"The user does not have certificates list permission"}}`)
+		case "/secrets/db-password/versions":
+			fmt.Fprintf(w, `{"value":[{"id":"%[1]s/secrets/db-password/v1","attributes":{"enabled":false,"created":1700000000}},{"id":"%[1]s/secrets/db-password/v2","attributes":{"enabled":true,"created":1704067200}}]}`, server.URL)
+		case "/secrets/db-password/v1":
+			fmt.Fprint(w, `{"value":"hunter2"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cred := staticCredential{}
+	ctx := context.Background()
+	uri := server.URL + "/"
+
+	secrets, err := listVaultItems(ctx, cred, uri, VaultSecrets)
+	if err != nil || len(secrets) != 2 {
+		t.Fatalf("listVaultItems(secrets) = %+v, %v, want two across pages", secrets, err)
+	}
+	if secrets[0].Name != "api-key" || secrets[0].Enabled || secrets[0].ContentType != "text/plain" || !secrets[0].Expires.IsZero() {
+		t.Errorf("first secret = %+v", secrets[0])
+	}
+	if secrets[1].Name != "db-password" || !secrets[1].Enabled || !secrets[1].Expires.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("second secret = %+v", secrets[1])
+	}
+
+	keys, err := listVaultItems(ctx, cred, uri, VaultKeys)
+	if err != nil || len(keys) != 1 || keys[0].Name != "signing" || !keys[0].Managed {
+		t.Errorf("listVaultItems(keys) = %+v, %v", keys, err)
+	}
+
+	if _, err := listVaultItems(ctx, cred, uri, VaultCertificates); err == nil || !strings.HasPrefix(err.Error(), "Forbidden: ") || !isVaultForbidden(err) {
+		t.Errorf("listing certificates without permission returned %v", err)
+	}
+
+	if _, err := listVaultItems(ctx, cred, uri, "missing"); err == nil || isVaultForbidden(err) {
+		t.Errorf("a missing listing was taken for a refused one: %v", err)
+	}
+
+	if _, err := listVaultItems(ctx, cred, uri, "escape"); err == nil || !strings.Contains(err.Error(), "refusing next link") {
+		t.Errorf("following a next link to another host returned %v", err)
+	}
+
+	versions, err := listVaultVersions(ctx, cred, uri, VaultSecrets, "db-password")
+	if err != nil || len(versions) != 2 || versions[0].Version != "v2" || versions[1].Version != "v1" {
+		t.Errorf("listVaultVersions() = %+v, %v, want v2 then v1", versions, err)
+	}
+
+	if value, err := secretValue(ctx, cred, uri, "db-password", "v1"); err != nil || value != "hunter2" {
+		t.Errorf("secretValue() = %q, %v", value, err)
+	}
+	if _, err := secretValue(ctx, cred, uri, "missing", ""); err == nil {
+		t.Error("reading a missing secret succeeded")
+	}
+}
+
+func TestVaultScope(t *testing.T) {
+	tests := map[string]string{
+		"https://kv-app.vault.azure.net/":          "https://vault.azure.net/.default",
+		"https://kv-app.vault.azure.cn/":           "https://vault.azure.cn/.default",
+		"https://kv-app.vault.usgovcloudapi.net":   "https://vault.usgovcloudapi.net/.default",
+		"https://kv-app.vault.azure.net:443/other": "https://vault.azure.net/.default",
+	}
+	for uri, want := range tests {
+		if got := vaultScope(uri); got != want {
+			t.Errorf("vaultScope(%q) = %q, want %q", uri, got, want)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// metricsAPIVersion is the Azure Monitor metrics API the load balancer view
+// reads probe health from.
+const metricsAPIVersion = "2018-01-01"
+
+// BackendMember is one address in a load balancer backend pool.
+type BackendMember struct {
+	Pool string // backend pool resource ID
+	// Target is the IP configuration resource ID of a NIC-based pool
+	// member, "" for members added by address.
+	Target string
+	IP     string
+	// Availability is the share of health probes the member answered in
+	// the last minutes, in percent, or -1 when it is not known.
+	Availability float64
+}
+
+// FetchLoadBalancer loads a load balancer with the public addresses of its
+// frontends and the members of its backend pools. A public address that
+// cannot be read is reported in FrontendErrs. Member health comes from
+// the DipAvailability metric, which only Standard load balancers report;
+// failing to read it leaves every member's availability unknown and sets
+// HealthErr rather than failing.
+func FetchLoadBalancer(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armnetwork.NewLoadBalancersClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := LoadBalancerMsg{ID: resourceID, LoadBalancer: resp.LoadBalancer, FrontendIPs: map[string]string{}, FrontendErrs: map[string]error{}}
+		props := resp.Properties
+		if props == nil {
+			return msg
+		}
+		for _, frontend := range props.FrontendIPConfigurations {
+			if frontend == nil || frontend.ID == nil || frontend.Properties == nil || frontend.Properties.PublicIPAddress == nil || frontend.Properties.PublicIPAddress.ID == nil {
+				continue
+			}
+			address, err := publicIPAddress(ctx, *frontend.Properties.PublicIPAddress.ID)
+			if err != nil {
+				msg.FrontendErrs[strings.ToLower(*frontend.ID)] = err
+				continue
+			}
+			msg.FrontendIPs[strings.ToLower(*frontend.ID)] = address
+		}
+
+		for _, pool := range props.BackendAddressPools {
+			if pool == nil || pool.ID == nil || pool.Properties == nil {
+				continue
+			}
+			msg.Members = append(msg.Members, poolMembers(ctx, *pool.ID, pool.Properties)...)
+		}
+
+		if resp.SKU == nil || resp.SKU.Name == nil || *resp.SKU.Name != armnetwork.LoadBalancerSKUNameStandard {
+			msg.HealthErr = fmt.Errorf("only Standard load balancers report probe health")
+			return msg
+		}
+		availability, err := backendAvailability(ctx, resourceID)
+		if err != nil {
+			msg.HealthErr = err
+			return msg
+		}
+		for i := range msg.Members {
+			if value, ok := availability[msg.Members[i].IP]; ok {
+				msg.Members[i].Availability = value
+			}
+		}
+		return msg
+	}
+}
+
+// poolMembers lists the members of a backend pool, looking up the private
+// address of NIC-based members. An address that cannot be read is left
+// empty, which only costs that member its health.
+func poolMembers(ctx context.Context, poolID string, props *armnetwork.BackendAddressPoolPropertiesFormat) []BackendMember {
+	var members []BackendMember
+	seen := map[string]bool{}
+	for _, config := range props.BackendIPConfigurations {
+		if config == nil || config.ID == nil {
+			continue
+		}
+		address := ""
+		if config.Properties != nil {
+			address = deref(config.Properties.PrivateIPAddress)
+		}
+		if address == "" {
+			address, _ = ipConfigurationAddress(ctx, *config.ID)
+		}
+		seen[strings.ToLower(*config.ID)] = true
+		members = append(members, BackendMember{Pool: poolID, Target: *config.ID, IP: address, Availability: -1})
+	}
+	for _, backend := range props.LoadBalancerBackendAddresses {
+		if backend == nil || backend.Properties == nil {
+			continue
+		}
+		if ref := backend.Properties.NetworkInterfaceIPConfiguration; ref != nil && ref.ID != nil {
+			if seen[strings.ToLower(*ref.ID)] {
+				continue
+			}
+		}
+		members = append(members, BackendMember{Pool: poolID, IP: deref(backend.Properties.IPAddress), Availability: -1})
+	}
+	return members
+}
+
+// ipConfigurationAddress returns the private address of a network interface
+// IP configuration, on a standalone NIC or on a scale set instance.
+func ipConfigurationAddress(ctx context.Context, configID string) (string, error) {
+	id, err := arm.ParseResourceID(configID)
+	if err != nil || id.Parent == nil {
+		return "", err
+	}
+	cred, err := credential()
+	if err != nil {
+		return "", err
+	}
+	nic := id.Parent
+	var props *armnetwork.InterfaceIPConfigurationPropertiesFormat
+	if instance := nic.Parent; instance != nil && instance.Parent != nil && strings.EqualFold(instance.Parent.ResourceType.String(), "Microsoft.Compute/virtualMachineScaleSets") {
+		client, err := armnetwork.NewInterfacesClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.GetVirtualMachineScaleSetIPConfiguration(ctx, id.ResourceGroupName, instance.Parent.Name, instance.Name, nic.Name, id.Name, nil)
+		if err != nil {
+			return "", err
+		}
+		props = resp.Properties
+	} else {
+		client, err := armnetwork.NewInterfaceIPConfigurationsClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Get(ctx, id.ResourceGroupName, nic.Name, id.Name, nil)
+		if err != nil {
+			return "", err
+		}
+		props = resp.Properties
+	}
+	if props == nil {
+		return "", nil
+	}
+	return deref(props.PrivateIPAddress), nil
+}
+
+// metricsResponse is the part of an Azure Monitor metrics response the
+// health lookup reads.
+type metricsResponse struct {
+	Value []struct {
+		Timeseries []struct {
+			Metadatavalues []struct {
+				Name struct {
+					Value string `json:"value"`
+				} `json:"name"`
+				Value string `json:"value"`
+			} `json:"metadatavalues"`
+			Data []struct {
+				Average *float64 `json:"average"`
+			} `json:"data"`
+		} `json:"timeseries"`
+	} `json:"value"`
+}
+
+// backendAvailability reads the health probe status of a Standard load
+// balancer's backends over the last five minutes, keyed by backend address.
+// The latest minute with data wins.
+func backendAvailability(ctx context.Context, loadBalancerID string) (map[string]float64, error) {
+	query := url.Values{
+		"metricnames": {"DipAvailability"},
+		"aggregation": {"Average"},
+		"interval":    {"PT1M"},
+		"timespan":    {"PT5M"},
+		"$filter":     {"BackendIPAddress eq '*'"},
+	}
+	var resp metricsResponse
+	if err := armGet(ctx, loadBalancerID+"/providers/Microsoft.Insights/metrics", metricsAPIVersion, query, &resp); err != nil {
+		return nil, fmt.Errorf("reading probe health: %w", err)
+	}
+	return parseAvailability(resp), nil
+}
+
+func parseAvailability(resp metricsResponse) map[string]float64 {
+	availability := map[string]float64{}
+	for _, metric := range resp.Value {
+		for _, series := range metric.Timeseries {
+			address := ""
+			for _, meta := range series.Metadatavalues {
+				if strings.EqualFold(meta.Name.Value, "BackendIPAddress") {
+					address = meta.Value
+				}
+			}
+			if address == "" {
+				continue
+			}
+			for i := len(series.Data) - 1; i >= 0; i-- {
+				if series.Data[i].Average != nil {
+					availability[address] = *series.Data[i].Average
+					break
+				}
+			}
+		}
+	}
+	return availability
+}
+
+// FetchAppGateway loads an application gateway with the public addresses of
+// its frontends, reporting those that cannot be read in FrontendErrs.
+// Backend health is a separate, slower call: FetchAppGatewayHealth.
+func FetchAppGateway(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armnetwork.NewApplicationGatewaysClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := AppGatewayMsg{ID: resourceID, Gateway: resp.ApplicationGateway, FrontendIPs: map[string]string{}, FrontendErrs: map[string]error{}}
+		if resp.Properties == nil {
+			return msg
+		}
+		for _, frontend := range resp.Properties.FrontendIPConfigurations {
+			if frontend == nil || frontend.ID == nil || frontend.Properties == nil || frontend.Properties.PublicIPAddress == nil || frontend.Properties.PublicIPAddress.ID == nil {
+				continue
+			}
+			address, err := publicIPAddress(ctx, *frontend.Properties.PublicIPAddress.ID)
+			if err != nil {
+				msg.FrontendErrs[strings.ToLower(*frontend.ID)] = err
+				continue
+			}
+			msg.FrontendIPs[strings.ToLower(*frontend.ID)] = address
+		}
+		return msg
+	}
+}
+
+// FetchAppGatewayHealth asks an application gateway to probe its backends
+// now. The gateway runs the probes, which can take a minute; the message
+// carries the error instead of failing the view.
+func FetchAppGatewayHealth(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		msg := AppGatewayHealthMsg{ID: resourceID}
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		cred, err := credential()
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		client, err := armnetwork.NewApplicationGatewaysClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		ctx := context.Background()
+		poller, err := client.BeginBackendHealth(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		resp, err := poller.PollUntilDone(ctx, nil)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		msg.Health = resp.ApplicationGatewayBackendHealth
+		return msg
+	}
+}
@@ -0,0 +1,31 @@
+package azure
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseAvailability(t *testing.T) {
+	body := `{"value": [{"name": {"value": "DipAvailability"}, "timeseries": [
+		{"metadatavalues": [{"name": {"value": "backendipaddress"}, "value": "10.0.1.4"}],
+		 "data": [{"timeStamp": "t1", "average": 0}, {"timeStamp": "t2", "average": 100}]},
+		{"metadatavalues": [{"name": {"value": "backendipaddress"}, "value": "10.0.1.5"}],
+		 "data": [{"timeStamp": "t1", "average": 50}, {"timeStamp": "t2"}]},
+		{"metadatavalues": [], "data": [{"timeStamp": "t1", "average": 100}]}
+	]}]}`
+	var resp metricsResponse
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+
+	availability := parseAvailability(resp)
+	if len(availability) != 2 {
+		t.Fatalf("availability = %v, want two backends", availability)
+	}
+	if availability["10.0.1.4"] != 100 {
+		t.Errorf("10.0.1.4 = %v, want the latest minute, 100", availability["10.0.1.4"])
+	}
+	if availability["10.0.1.5"] != 50 {
+		t.Errorf("10.0.1.5 = %v, want the latest minute with data, 50", availability["10.0.1.5"])
+	}
+}
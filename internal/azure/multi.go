@@ -0,0 +1,117 @@
+package azure
+
+import (
+	"context"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxConcurrentFetches bounds how many subscriptions are queried at once when
+// building an aggregated view.
+const maxConcurrentFetches = 8
+
+// forEachSubscription runs fn for every subscription using a bounded pool of
+// workers. Failures are collected per subscription rather than aborting the
+// remaining work, so callers can render partial results.
+func forEachSubscription(subscriptionIDs []string, workers int, fn func(subscriptionID string) error) map[string]error {
+	return forEachID(subscriptionIDs, workers, fn)
+}
+
+// forEachID is forEachSubscription for any list of IDs, such as the
+// resources of one subscription.
+func forEachID(ids []string, workers int, fn func(id string) error) map[string]error {
+	if workers < 1 {
+		workers = 1
+	}
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs = make(map[string]error)
+		jobs = make(chan string)
+	)
+
+	for i := 0; i < workers && i < len(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				if err := fn(id); err != nil {
+					mu.Lock()
+					errs[id] = err
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, id := range ids {
+		jobs <- id
+	}
+	close(jobs)
+	wg.Wait()
+
+	return errs
+}
+
+// FetchResourceGroupsMulti lists resource groups for several subscriptions
+// concurrently.
+func FetchResourceGroupsMulti(subscriptionIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		var mu sync.Mutex
+		groups := make(map[string][]armresources.ResourceGroup)
+
+		errs := forEachSubscription(subscriptionIDs, maxConcurrentFetches, func(subscriptionID string) error {
+			result, err := listResourceGroups(context.Background(), cred, normalizeSubscriptionID(subscriptionID))
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			groups[subscriptionID] = result
+			mu.Unlock()
+			return nil
+		})
+
+		return MultiResourceGroupsMsg{
+			Groups: groups,
+			Errors: errs,
+		}
+	}
+}
+
+// FetchResourcesMulti lists every resource in several subscriptions
+// concurrently.
+func FetchResourcesMulti(subscriptionIDs []string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		var mu sync.Mutex
+		resources := make(map[string][]armresources.GenericResourceExpanded)
+
+		errs := forEachSubscription(subscriptionIDs, maxConcurrentFetches, func(subscriptionID string) error {
+			result, err := listSubscriptionResources(context.Background(), cred, normalizeSubscriptionID(subscriptionID))
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			resources[subscriptionID] = result
+			mu.Unlock()
+			return nil
+		})
+
+		return MultiResourcesMsg{
+			Resources: resources,
+			Errors:    errs,
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package azure
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEachSubscription(t *testing.T) {
+	ids := []string{"a", "b", "c", "d", "e", "f"}
+
+	var running, peak int32
+	release := make(chan struct{})
+	done := make(chan map[string]error)
+
+	go func() {
+		done <- forEachSubscription(ids, 2, func(id string) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			if id == "c" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+	}()
+
+	for range ids {
+		release <- struct{}{}
+	}
+	errs := <-done
+
+	if peak > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak)
+	}
+	if len(errs) != 1 || errs["c"] == nil {
+		t.Errorf("errors = %v, want only c", errs)
+	}
+}
@@ -0,0 +1,81 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// securityRules parses a network security group ID and returns a client for
+// its rules.
+func securityRules(nsgID string) (*arm.ResourceID, *armnetwork.SecurityRulesClient, error) {
+	id, err := arm.ParseResourceID(nsgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := armnetwork.NewSecurityRulesClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, client, nil
+}
+
+// FetchNSG loads a network security group with its rules and the subnets
+// and network interfaces it is associated with.
+func FetchNSG(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armnetwork.NewSecurityGroupsClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		resp, err := client.Get(context.Background(), id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		return NSGMsg{ID: resourceID, Group: resp.SecurityGroup}
+	}
+}
+
+// PutNSGRule creates a security rule, or replaces the rule of the same name.
+func PutNSGRule(opID int, nsgID string, rule armnetwork.SecurityRule) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := securityRules(nsgID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := client.BeginCreateOrUpdate(context.Background(), id.ResourceGroupName, id.Name, deref(rule.Name), rule, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// DeleteNSGRule removes a security rule.
+func DeleteNSGRule(opID int, nsgID, name string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := securityRules(nsgID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := client.BeginDelete(context.Background(), id.ResourceGroupName, id.Name, name, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
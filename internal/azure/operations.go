@@ -0,0 +1,78 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// operationPollInterval is how often background operations are polled.
+var operationPollInterval = 5 * time.Second
+
+// OperationUpdateMsg reports progress of a background long-running operation.
+// While the operation is still running Next polls it again; the receiver is
+// expected to return it as its command.
+type OperationUpdateMsg struct {
+	ID     int
+	Status string
+	Done   bool
+	Err    error
+	Next   tea.Cmd
+}
+
+// operationStarted turns a freshly started poller into the first update of
+// operation id.
+func operationStarted[T any](id int, poller *runtime.Poller[T]) tea.Msg {
+	if poller.Done() {
+		if _, err := poller.Result(context.Background()); err != nil {
+			return OperationUpdateMsg{ID: id, Status: "Failed", Done: true, Err: err}
+		}
+		return OperationUpdateMsg{ID: id, Status: "Succeeded", Done: true}
+	}
+	return OperationUpdateMsg{ID: id, Status: "InProgress", Next: pollOperation(id, poller)}
+}
+
+// pollOperation polls the operation once after operationPollInterval.
+func pollOperation[T any](id int, poller *runtime.Poller[T]) tea.Cmd {
+	return func() tea.Msg {
+		time.Sleep(operationPollInterval)
+
+		ctx := context.Background()
+		resp, err := poller.Poll(ctx)
+		if err != nil {
+			return OperationUpdateMsg{ID: id, Status: "Failed", Done: true, Err: err}
+		}
+		if poller.Done() {
+			if _, err := poller.Result(ctx); err != nil {
+				return OperationUpdateMsg{ID: id, Status: "Failed", Done: true, Err: err}
+			}
+			return OperationUpdateMsg{ID: id, Status: "Succeeded", Done: true}
+		}
+
+		body, _ := runtime.Payload(resp)
+		return OperationUpdateMsg{ID: id, Status: operationStatus(body), Next: pollOperation(id, poller)}
+	}
+}
+
+// operationStatus extracts the status reported by an operation status
+// monitor, falling back to InProgress for bodies without one.
+func operationStatus(body []byte) string {
+	var status struct {
+		Status     string `json:"status"`
+		Properties struct {
+			ProvisioningState string `json:"provisioningState"`
+		} `json:"properties"`
+	}
+	if json.Unmarshal(body, &status) == nil {
+		if status.Status != "" {
+			return status.Status
+		}
+		if status.Properties.ProvisioningState != "" {
+			return status.Properties.ProvisioningState
+		}
+	}
+	return "InProgress"
+}
@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+func TestOperationStatus(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "Status monitor", body: `{"status": "Running"}`, want: "Running"},
+		{name: "Resource", body: `{"properties": {"provisioningState": "Upgrading"}}`, want: "Upgrading"},
+		{name: "Empty", body: ``, want: "InProgress"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := operationStatus([]byte(tt.body)); got != tt.want {
+				t.Errorf("operationStatus(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+// donePoller is an operation that finished as soon as it started.
+type donePoller struct{ err error }
+
+func (p donePoller) Done() bool                                   { return true }
+func (p donePoller) Poll(context.Context) (*http.Response, error) { return nil, nil }
+func (p donePoller) Result(context.Context, *struct{}) error      { return p.err }
+
+func TestOperationStartedDone(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+	}{
+		{name: "succeeded", wantStatus: "Succeeded"},
+		{name: "failed", err: errors.New("OperationNotAllowed"), wantStatus: "Failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poller, err := runtime.NewPoller(nil, runtime.Pipeline{}, &runtime.NewPollerOptions[struct{}]{Handler: donePoller{err: tt.err}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			msg := operationStarted(1, poller).(OperationUpdateMsg)
+			if msg.Status != tt.wantStatus || !msg.Done || !errors.Is(msg.Err, tt.err) || msg.Next != nil {
+				t.Errorf("update = %+v, want %s and done", msg, tt.wantStatus)
+			}
+		})
+	}
+}
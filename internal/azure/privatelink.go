@@ -0,0 +1,361 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PrivateEndpoint is a private endpoint with the names it serves and the
+// private address each one must resolve to.
+type PrivateEndpoint struct {
+	Endpoint *armnetwork.PrivateEndpoint
+	VNet     string // resource ID of the virtual network of its subnet
+	Names    []PrivateName
+	// Err is set when a network interface of the endpoint could not be
+	// read, so that Names may be incomplete.
+	Err error
+}
+
+// PrivateName is a name a private endpoint serves and its address on the
+// endpoint's network interface.
+type PrivateName struct {
+	FQDN string
+	IP   string
+}
+
+// PrivateZone is a private DNS zone with its record sets and the virtual
+// networks linked to it.
+type PrivateZone struct {
+	Zone    *armprivatedns.PrivateZone
+	Records []*armprivatedns.RecordSet
+	Links   []*armprivatedns.VirtualNetworkLink
+}
+
+// DNSIssue is a name of a private endpoint that its virtual network does
+// not resolve to the endpoint through a private DNS zone, or, with an empty
+// FQDN, an endpoint whose names could not be read.
+type DNSIssue struct {
+	Endpoint string // resource ID
+	FQDN     string
+	IP       string
+	Problem  string
+}
+
+// FetchPrivateLink lists the private endpoints and private DNS zones of a
+// resource group, or of the whole subscription when resourceGroup is empty,
+// reading each endpoint's network interface for the names it serves and
+// each zone's record sets and virtual network links. Network interfaces are
+// read by a bounded pool, and one that cannot be read is reported on its
+// endpoint.
+func FetchPrivateLink(subscriptionID, resourceGroup string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		subscription := normalizeSubscriptionID(subscriptionID)
+		ctx := context.Background()
+		msg := PrivateLinkMsg{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup}
+
+		endpoints, err := armnetwork.NewPrivateEndpointsClient(subscription, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		var list []*armnetwork.PrivateEndpoint
+		if resourceGroup != "" {
+			pager := endpoints.NewListPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				list = append(list, page.Value...)
+			}
+		} else {
+			pager := endpoints.NewListBySubscriptionPager(nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				list = append(list, page.Value...)
+			}
+		}
+		var ids []string
+		byID := map[string]*armnetwork.PrivateEndpoint{}
+		for _, endpoint := range list {
+			if endpoint == nil || endpoint.ID == nil || byID[*endpoint.ID] != nil {
+				continue
+			}
+			ids = append(ids, *endpoint.ID)
+			byID[*endpoint.ID] = endpoint
+		}
+		var mu sync.Mutex
+		entries := map[string]PrivateEndpoint{}
+		forEachID(ids, maxConcurrentFetches, func(endpointID string) error {
+			entry := privateEndpointNames(ctx, byID[endpointID])
+			mu.Lock()
+			entries[endpointID] = entry
+			mu.Unlock()
+			return nil
+		})
+		for _, id := range ids {
+			msg.Endpoints = append(msg.Endpoints, entries[id])
+		}
+
+		zones, err := armprivatedns.NewPrivateZonesClient(subscription, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		var zoneList []*armprivatedns.PrivateZone
+		if resourceGroup != "" {
+			pager := zones.NewListByResourceGroupPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				zoneList = append(zoneList, page.Value...)
+			}
+		} else {
+			pager := zones.NewListPager(nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				zoneList = append(zoneList, page.Value...)
+			}
+		}
+		records, err := armprivatedns.NewRecordSetsClient(subscription, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		links, err := armprivatedns.NewVirtualNetworkLinksClient(subscription, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		for _, zone := range zoneList {
+			if zone == nil || zone.ID == nil || zone.Name == nil {
+				continue
+			}
+			id, err := arm.ParseResourceID(*zone.ID)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			entry := PrivateZone{Zone: zone}
+			recordPager := records.NewListPager(id.ResourceGroupName, *zone.Name, nil)
+			for recordPager.More() {
+				page, err := recordPager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				entry.Records = append(entry.Records, page.Value...)
+			}
+			linkPager := links.NewListPager(id.ResourceGroupName, *zone.Name, nil)
+			for linkPager.More() {
+				page, err := linkPager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				entry.Links = append(entry.Links, page.Value...)
+			}
+			msg.Zones = append(msg.Zones, entry)
+		}
+		return msg
+	}
+}
+
+// privateEndpointNames reads the names a private endpoint serves from its
+// network interface, falling back to the endpoint's custom DNS
+// configuration when the interface lists none. Interfaces that cannot be
+// read are reported in Err.
+func privateEndpointNames(ctx context.Context, endpoint *armnetwork.PrivateEndpoint) PrivateEndpoint {
+	entry := PrivateEndpoint{Endpoint: endpoint}
+	props := endpoint.Properties
+	if props == nil {
+		return entry
+	}
+	if props.Subnet != nil && props.Subnet.ID != nil {
+		if id, err := arm.ParseResourceID(*props.Subnet.ID); err == nil && id.Parent != nil {
+			entry.VNet = id.Parent.String()
+		}
+	}
+
+	var errs []error
+	for _, ref := range props.NetworkInterfaces {
+		if ref == nil || ref.ID == nil {
+			continue
+		}
+		nic, err := readInterface(ctx, *ref.ID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if nic.Properties == nil {
+			continue
+		}
+		for _, config := range nic.Properties.IPConfigurations {
+			if config == nil || config.Properties == nil || config.Properties.PrivateLinkConnectionProperties == nil {
+				continue
+			}
+			address := deref(config.Properties.PrivateIPAddress)
+			for _, fqdn := range config.Properties.PrivateLinkConnectionProperties.Fqdns {
+				if fqdn != nil && *fqdn != "" {
+					entry.Names = append(entry.Names, PrivateName{FQDN: *fqdn, IP: address})
+				}
+			}
+		}
+	}
+	entry.Err = errors.Join(errs...)
+	if len(entry.Names) > 0 {
+		return entry
+	}
+	for _, config := range props.CustomDNSConfigs {
+		if config == nil || config.Fqdn == nil {
+			continue
+		}
+		for _, address := range config.IPAddresses {
+			if address != nil {
+				entry.Names = append(entry.Names, PrivateName{FQDN: *config.Fqdn, IP: *address})
+			}
+		}
+	}
+	return entry
+}
+
+// readInterface reads a network interface by resource ID.
+func readInterface(ctx context.Context, nicID string) (armnetwork.Interface, error) {
+	id, err := arm.ParseResourceID(nicID)
+	if err != nil {
+		return armnetwork.Interface{}, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return armnetwork.Interface{}, err
+	}
+	client, err := armnetwork.NewInterfacesClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return armnetwork.Interface{}, err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return armnetwork.Interface{}, err
+	}
+	return resp.Interface, nil
+}
+
+// CheckPrivateDNS finds the names of private endpoints that their virtual
+// network does not resolve to them through one of zones: no zone for the
+// name is linked to the network, or the linked zone has no A record for it
+// or one with other addresses. Endpoints whose names could not all be read
+// get an issue without an FQDN. A name such as
+// mystorage.blob.core.windows.net belongs in a zone named after its domain
+// with a privatelink. prefix, privatelink.blob.core.windows.net, or in a
+// zone named after the domain itself.
+func CheckPrivateDNS(endpoints []PrivateEndpoint, zones []PrivateZone) []DNSIssue {
+	var issues []DNSIssue
+	for _, endpoint := range endpoints {
+		if endpoint.Endpoint == nil || endpoint.Endpoint.ID == nil {
+			continue
+		}
+		if endpoint.Err != nil {
+			issues = append(issues, DNSIssue{Endpoint: *endpoint.Endpoint.ID, Problem: "network interface could not be read: " + endpoint.Err.Error()})
+		}
+		for _, name := range endpoint.Names {
+			if problem := checkPrivateName(name, endpoint.VNet, zones); problem != "" {
+				issues = append(issues, DNSIssue{Endpoint: *endpoint.Endpoint.ID, FQDN: name.FQDN, IP: name.IP, Problem: problem})
+			}
+		}
+	}
+	return issues
+}
+
+func checkPrivateName(name PrivateName, vnet string, zones []PrivateZone) string {
+	fqdn := strings.ToLower(strings.TrimSuffix(name.FQDN, "."))
+	var candidates []string
+	for _, zone := range zones {
+		zoneName := strings.ToLower(deref(zone.Zone.Name))
+		record, ok := zoneRecordName(fqdn, zoneName)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, zoneName)
+		if !linkedTo(zone, vnet) {
+			continue
+		}
+		addresses := aRecord(zone, record)
+		if addresses == nil {
+			return fmt.Sprintf("no A record %s in %s", record, zoneName)
+		}
+		for _, address := range addresses {
+			if address == name.IP {
+				return ""
+			}
+		}
+		return fmt.Sprintf("A record %s in %s points to %s", record, zoneName, strings.Join(addresses, ", "))
+	}
+	if len(candidates) == 0 {
+		return "no private DNS zone for it in scope"
+	}
+	sort.Strings(candidates)
+	network := vnet
+	if id, err := arm.ParseResourceID(vnet); err == nil {
+		network = id.Name
+	}
+	return fmt.Sprintf("%s is not linked to %s", strings.Join(candidates, ", "), network)
+}
+
+// zoneRecordName returns the record name fqdn takes in zone, "@" for the
+// zone apex, and whether fqdn belongs in it at all.
+func zoneRecordName(fqdn, zone string) (string, bool) {
+	if zone == "" {
+		return "", false
+	}
+	if fqdn == zone {
+		return "@", true
+	}
+	if strings.HasSuffix(fqdn, "."+zone) {
+		return strings.TrimSuffix(fqdn, "."+zone), true
+	}
+	if domain, ok := strings.CutPrefix(zone, "privatelink."); ok && strings.HasSuffix(fqdn, "."+domain) {
+		return strings.TrimSuffix(fqdn, "."+domain), true
+	}
+	return "", false
+}
+
+func linkedTo(zone PrivateZone, vnet string) bool {
+	for _, link := range zone.Links {
+		if link != nil && link.Properties != nil && link.Properties.VirtualNetwork != nil && strings.EqualFold(deref(link.Properties.VirtualNetwork.ID), vnet) {
+			return true
+		}
+	}
+	return false
+}
+
+// aRecord returns the addresses of the A record named record in zone, or
+// nil when there is none.
+func aRecord(zone PrivateZone, record string) []string {
+	for _, set := range zone.Records {
+		if set == nil || set.Properties == nil || !strings.EqualFold(deref(set.Name), record) || !strings.HasSuffix(deref(set.Type), "/A") {
+			continue
+		}
+		addresses := []string{}
+		for _, a := range set.Properties.ARecords {
+			if a != nil && a.IPv4Address != nil {
+				addresses = append(addresses, *a.IPv4Address)
+			}
+		}
+		return addresses
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package azure
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+)
+
+const (
+	testHubVNet   = "/subscriptions/sub-1/resourceGroups/rg-net/providers/Microsoft.Network/virtualNetworks/vnet-hub"
+	testSpokeVNet = "/subscriptions/sub-1/resourceGroups/rg-net/providers/Microsoft.Network/virtualNetworks/vnet-spoke"
+)
+
+func privateZone(name, linkedVNet string, records map[string]string) PrivateZone {
+	zone := PrivateZone{Zone: &armprivatedns.PrivateZone{Name: to.Ptr(name)}}
+	if linkedVNet != "" {
+		zone.Links = []*armprivatedns.VirtualNetworkLink{{
+			Properties: &armprivatedns.VirtualNetworkLinkProperties{VirtualNetwork: &armprivatedns.SubResource{ID: to.Ptr(linkedVNet)}},
+		}}
+	}
+	for record, address := range records {
+		zone.Records = append(zone.Records, &armprivatedns.RecordSet{
+			Name:       to.Ptr(record),
+			Type:       to.Ptr("Microsoft.Network/privateDnsZones/A"),
+			Properties: &armprivatedns.RecordSetProperties{ARecords: []*armprivatedns.ARecord{{IPv4Address: to.Ptr(address)}}},
+		})
+	}
+	return zone
+}
+
+func TestCheckPrivateDNS(t *testing.T) {
+	tests := []struct {
+		name  string
+		vnet  string
+		fqdn  string
+		zones []PrivateZone
+		want  string
+	}{
+		{
+			name:  "A record in the privatelink zone",
+			vnet:  testHubVNet,
+			fqdn:  "mystorage.blob.core.windows.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, map[string]string{"mystorage": "10.0.5.4"})},
+		},
+		{
+			name:  "Name already in the privatelink domain",
+			vnet:  testHubVNet,
+			fqdn:  "mystorage.privatelink.blob.core.windows.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, map[string]string{"mystorage": "10.0.5.4"})},
+		},
+		{
+			name:  "A record missing",
+			vnet:  testHubVNet,
+			fqdn:  "mystorage.blob.core.windows.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, map[string]string{"other": "10.0.5.9"})},
+			want:  "no A record mystorage in privatelink.blob.core.windows.net",
+		},
+		{
+			name:  "A record with a stale address",
+			vnet:  testHubVNet,
+			fqdn:  "mystorage.blob.core.windows.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, map[string]string{"mystorage": "10.0.5.99"})},
+			want:  "A record mystorage in privatelink.blob.core.windows.net points to 10.0.5.99",
+		},
+		{
+			name:  "Zone linked to another network",
+			vnet:  testSpokeVNet,
+			fqdn:  "mystorage.blob.core.windows.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, map[string]string{"mystorage": "10.0.5.4"})},
+			want:  "privatelink.blob.core.windows.net is not linked to vnet-spoke",
+		},
+		{
+			name:  "No zone for the name",
+			vnet:  testHubVNet,
+			fqdn:  "myvault.vault.azure.net",
+			zones: []PrivateZone{privateZone("privatelink.blob.core.windows.net", testHubVNet, nil)},
+			want:  "no private DNS zone for it in scope",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := PrivateEndpoint{
+				Endpoint: &armnetwork.PrivateEndpoint{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/privateEndpoints/pe-storage")},
+				VNet:     tt.vnet,
+				Names:    []PrivateName{{FQDN: tt.fqdn, IP: "10.0.5.4"}},
+			}
+			issues := CheckPrivateDNS([]PrivateEndpoint{endpoint}, tt.zones)
+			if tt.want == "" {
+				if len(issues) != 0 {
+					t.Errorf("issues = %+v, want none", issues)
+				}
+				return
+			}
+			if len(issues) != 1 || issues[0].Problem != tt.want || issues[0].FQDN != tt.fqdn {
+				t.Errorf("issues = %+v, want %q", issues, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPrivateDNSUnreadableEndpoint(t *testing.T) {
+	endpoint := PrivateEndpoint{
+		Endpoint: &armnetwork.PrivateEndpoint{ID: to.Ptr("/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Network/privateEndpoints/pe-vault")},
+		VNet:     testHubVNet,
+		Err:      errors.New("AuthorizationFailed"),
+	}
+	issues := CheckPrivateDNS([]PrivateEndpoint{endpoint}, nil)
+	if len(issues) != 1 || issues[0].FQDN != "" || !strings.Contains(issues[0].Problem, "AuthorizationFailed") {
+		t.Errorf("issues = %+v, want the interface error", issues)
+	}
+}
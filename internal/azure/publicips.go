@@ -0,0 +1,355 @@
+package azure
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ManagementPorts are the ports the exposure report checks: SSH, RDP and
+// WinRM over HTTP and HTTPS.
+var ManagementPorts = []int{22, 3389, 5985, 5986}
+
+// Exposure is a public IP on a network interface whose NSGs let the whole
+// internet reach management ports, or that could not be checked.
+type Exposure struct {
+	PublicIP string // resource ID
+	NIC      string // resource ID
+	VM       string // resource ID, "" when the NIC is not attached to a VM
+	Ports    []int
+	// AllowedBy names the rules letting the traffic in, as nsg/rule, or
+	// says why no rule is needed.
+	AllowedBy []string
+	// Err is set when the interface, its subnet or one of its NSGs could not
+	// be read, leaving the exposure of the IP unknown.
+	Err error
+}
+
+// FetchPublicIPs lists the public IP addresses of a resource group, or of
+// the whole subscription when resourceGroup is empty.
+func FetchPublicIPs(subscriptionID, resourceGroup string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armnetwork.NewPublicIPAddressesClient(normalizeSubscriptionID(subscriptionID), cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		msg := PublicIPsMsg{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup}
+		if resourceGroup != "" {
+			pager := client.NewListPager(resourceGroup, nil)
+			for pager.More() {
+				page, err := pager.NextPage(ctx)
+				if err != nil {
+					return ErrorMsg{err}
+				}
+				msg.PublicIPs = append(msg.PublicIPs, page.Value...)
+			}
+			return msg
+		}
+		pager := client.NewListAllPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			msg.PublicIPs = append(msg.PublicIPs, page.Value...)
+		}
+		return msg
+	}
+}
+
+// FetchExposure checks every public IP bound to a network interface for
+// management ports open to the internet, reading the NSGs of the interface
+// and of its subnet. Public IPs are checked by a bounded pool, and one whose
+// interface or NSGs cannot be read is reported with its error.
+func FetchExposure(subscriptionID, resourceGroup string, publicIPs []*armnetwork.PublicIPAddress) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		ctx := context.Background()
+		groups := &securityGroups{cred: cred, reads: map[string]*securityGroupRead{}}
+
+		var ids []string
+		byID := map[string]*armnetwork.PublicIPAddress{}
+		for _, pip := range publicIPs {
+			if pip == nil || pip.ID == nil || byID[*pip.ID] != nil {
+				continue
+			}
+			ids = append(ids, *pip.ID)
+			byID[*pip.ID] = pip
+		}
+
+		var mu sync.Mutex
+		found := map[string]Exposure{}
+		forEachID(ids, maxConcurrentFetches, func(pipID string) error {
+			if exposure, ok := publicIPExposure(ctx, cred, groups, byID[pipID]); ok {
+				mu.Lock()
+				found[pipID] = exposure
+				mu.Unlock()
+			}
+			return nil
+		})
+
+		msg := ExposureMsg{SubscriptionID: subscriptionID, ResourceGroup: resourceGroup}
+		for _, id := range ids {
+			if exposure, ok := found[id]; ok {
+				msg.Exposures = append(msg.Exposures, exposure)
+			}
+		}
+		return msg
+	}
+}
+
+// publicIPExposure checks one public IP. It reports false when the IP is
+// not bound to a network interface or exposes nothing.
+func publicIPExposure(ctx context.Context, cred azcore.TokenCredential, groups *securityGroups, pip *armnetwork.PublicIPAddress) (Exposure, bool) {
+	if pip.Properties == nil || pip.Properties.IPConfiguration == nil || pip.Properties.IPConfiguration.ID == nil {
+		return Exposure{}, false
+	}
+	configID, err := arm.ParseResourceID(*pip.Properties.IPConfiguration.ID)
+	if err != nil || configID.Parent == nil || !strings.EqualFold(configID.Parent.ResourceType.String(), "Microsoft.Network/networkInterfaces") {
+		return Exposure{}, false
+	}
+	nicID := configID.Parent
+	exposure := Exposure{PublicIP: *pip.ID, NIC: nicID.String()}
+
+	nics, err := armnetwork.NewInterfacesClient(nicID.SubscriptionID, cred, armOptions())
+	if err != nil {
+		exposure.Err = err
+		return exposure, true
+	}
+	nic, err := nics.Get(ctx, nicID.ResourceGroupName, nicID.Name, nil)
+	if err != nil {
+		exposure.Err = err
+		return exposure, true
+	}
+	if nic.Properties != nil && nic.Properties.VirtualMachine != nil {
+		exposure.VM = deref(nic.Properties.VirtualMachine.ID)
+	}
+
+	// Traffic passes the subnet's NSG and then the NIC's; each one present
+	// must allow it.
+	var nsgs []*armnetwork.SecurityGroup
+	if props := nic.Properties; props != nil {
+		for _, config := range props.IPConfigurations {
+			if config == nil || config.Name == nil || !strings.EqualFold(*config.Name, configID.Name) || config.Properties == nil || config.Properties.Subnet == nil {
+				continue
+			}
+			subnetNSG, err := subnetSecurityGroup(ctx, deref(config.Properties.Subnet.ID))
+			if err != nil {
+				exposure.Err = err
+				return exposure, true
+			}
+			if subnetNSG != "" {
+				group, err := groups.get(ctx, subnetNSG)
+				if err != nil {
+					exposure.Err = err
+					return exposure, true
+				}
+				nsgs = append(nsgs, group)
+			}
+		}
+		if props.NetworkSecurityGroup != nil && props.NetworkSecurityGroup.ID != nil {
+			group, err := groups.get(ctx, *props.NetworkSecurityGroup.ID)
+			if err != nil {
+				exposure.Err = err
+				return exposure, true
+			}
+			nsgs = append(nsgs, group)
+		}
+	}
+
+	basic := pip.SKU == nil || pip.SKU.Name == nil || *pip.SKU.Name != armnetwork.PublicIPAddressSKUNameStandard
+	exposure.Ports, exposure.AllowedBy = ExposedPorts(nsgs, basic)
+	return exposure, len(exposure.Ports) > 0
+}
+
+// securityGroups reads each NSG once, however many public IPs share it.
+type securityGroups struct {
+	cred  azcore.TokenCredential
+	mu    sync.Mutex
+	reads map[string]*securityGroupRead
+}
+
+type securityGroupRead struct {
+	once  sync.Once
+	group *armnetwork.SecurityGroup
+	err   error
+}
+
+func (s *securityGroups) get(ctx context.Context, nsgID string) (*armnetwork.SecurityGroup, error) {
+	key := strings.ToLower(nsgID)
+	s.mu.Lock()
+	read, ok := s.reads[key]
+	if !ok {
+		read = &securityGroupRead{}
+		s.reads[key] = read
+	}
+	s.mu.Unlock()
+
+	read.once.Do(func() {
+		id, err := arm.ParseResourceID(nsgID)
+		if err != nil {
+			read.err = err
+			return
+		}
+		client, err := armnetwork.NewSecurityGroupsClient(id.SubscriptionID, s.cred, armOptions())
+		if err != nil {
+			read.err = err
+			return
+		}
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			read.err = err
+			return
+		}
+		read.group = &resp.SecurityGroup
+	})
+	return read.group, read.err
+}
+
+// subnetSecurityGroup returns the ID of the NSG associated with a subnet,
+// or "" when it has none.
+func subnetSecurityGroup(ctx context.Context, subnetID string) (string, error) {
+	id, err := arm.ParseResourceID(subnetID)
+	if err != nil || id.Parent == nil {
+		return "", err
+	}
+	cred, err := credential()
+	if err != nil {
+		return "", err
+	}
+	client, err := armnetwork.NewSubnetsClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Parent.Name, id.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.Properties == nil || resp.Properties.NetworkSecurityGroup == nil {
+		return "", nil
+	}
+	return deref(resp.Properties.NetworkSecurityGroup.ID), nil
+}
+
+// ExposedPorts returns the management ports that TCP traffic from any
+// internet address reaches through nsgs, which must all allow it, and the
+// rules that let it in. Without any NSG a Basic public IP is open, while a
+// Standard one is closed by default.
+func ExposedPorts(nsgs []*armnetwork.SecurityGroup, basic bool) ([]int, []string) {
+	if len(nsgs) == 0 {
+		if basic {
+			return ManagementPorts, []string{"no NSG on a Basic public IP"}
+		}
+		return nil, nil
+	}
+
+	var ports []int
+	allowedBy := map[string]bool{}
+	for _, port := range ManagementPorts {
+		open := true
+		var rules []string
+		for _, nsg := range nsgs {
+			rule := firstInternetRule(nsg, port)
+			if rule == nil || rule.Properties.Access == nil || *rule.Properties.Access != armnetwork.SecurityRuleAccessAllow {
+				open = false
+				break
+			}
+			rules = append(rules, deref(nsg.Name)+"/"+deref(rule.Name))
+		}
+		if open {
+			ports = append(ports, port)
+			for _, rule := range rules {
+				allowedBy[rule] = true
+			}
+		}
+	}
+	names := make([]string, 0, len(allowedBy))
+	for name := range allowedBy {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return ports, names
+}
+
+// firstInternetRule returns the inbound rule of nsg, custom or default, that
+// decides TCP traffic from any internet address to port: the one with the
+// lowest priority number that matches it.
+func firstInternetRule(nsg *armnetwork.SecurityGroup, port int) *armnetwork.SecurityRule {
+	if nsg.Properties == nil {
+		return nil
+	}
+	var best *armnetwork.SecurityRule
+	for _, rule := range append(append([]*armnetwork.SecurityRule{}, nsg.Properties.SecurityRules...), nsg.Properties.DefaultSecurityRules...) {
+		if rule == nil || rule.Properties == nil || rule.Properties.Priority == nil {
+			continue
+		}
+		props := rule.Properties
+		if props.Direction == nil || *props.Direction != armnetwork.SecurityRuleDirectionInbound {
+			continue
+		}
+		if props.Protocol != nil && *props.Protocol != armnetwork.SecurityRuleProtocolAsterisk && *props.Protocol != armnetwork.SecurityRuleProtocolTCP {
+			continue
+		}
+		if !matchesAnySource(props.SourceAddressPrefix, props.SourceAddressPrefixes) || !matchesPort(props.DestinationPortRange, props.DestinationPortRanges, port) {
+			continue
+		}
+		if best == nil || *props.Priority < *best.Properties.Priority {
+			best = rule
+		}
+	}
+	return best
+}
+
+// matchesAnySource reports whether a rule's source covers every internet
+// address.
+func matchesAnySource(prefix *string, prefixes []*string) bool {
+	for _, p := range append([]*string{prefix}, prefixes...) {
+		if p == nil {
+			continue
+		}
+		switch strings.ToLower(*p) {
+		case "*", "0.0.0.0/0", "internet", "any":
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPort reports whether a rule's port ranges, such as "*", "22" or
+// "3000-4000", include port.
+func matchesPort(portRange *string, portRanges []*string, port int) bool {
+	for _, r := range append([]*string{portRange}, portRanges...) {
+		if r == nil {
+			continue
+		}
+		if *r == "*" {
+			return true
+		}
+		low, high, isRange := strings.Cut(*r, "-")
+		if !isRange {
+			high = low
+		}
+		from, err1 := strconv.Atoi(strings.TrimSpace(low))
+		to, err2 := strconv.Atoi(strings.TrimSpace(high))
+		if err1 == nil && err2 == nil && from <= port && port <= to {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package azure
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+)
+
+func inboundRule(name string, priority int32, access armnetwork.SecurityRuleAccess, source, ports string) *armnetwork.SecurityRule {
+	return &armnetwork.SecurityRule{
+		Name: to.Ptr(name),
+		Properties: &armnetwork.SecurityRulePropertiesFormat{
+			Direction:            to.Ptr(armnetwork.SecurityRuleDirectionInbound),
+			Priority:             to.Ptr(priority),
+			Access:               to.Ptr(access),
+			Protocol:             to.Ptr(armnetwork.SecurityRuleProtocolTCP),
+			SourceAddressPrefix:  to.Ptr(source),
+			DestinationPortRange: to.Ptr(ports),
+		},
+	}
+}
+
+func securityGroup(name string, rules ...*armnetwork.SecurityRule) *armnetwork.SecurityGroup {
+	return &armnetwork.SecurityGroup{
+		Name: to.Ptr(name),
+		Properties: &armnetwork.SecurityGroupPropertiesFormat{
+			SecurityRules: rules,
+			DefaultSecurityRules: []*armnetwork.SecurityRule{
+				inboundRule("AllowVnetInBound", 65000, armnetwork.SecurityRuleAccessAllow, "VirtualNetwork", "*"),
+				inboundRule("DenyAllInBound", 65500, armnetwork.SecurityRuleAccessDeny, "*", "*"),
+			},
+		},
+	}
+}
+
+func TestExposedPorts(t *testing.T) {
+	allow, deny := armnetwork.SecurityRuleAccessAllow, armnetwork.SecurityRuleAccessDeny
+	tests := []struct {
+		name          string
+		nsgs          []*armnetwork.SecurityGroup
+		basic         bool
+		wantPorts     string
+		wantAllowedBy string
+	}{
+		{
+			name:          "SSH open to the internet",
+			nsgs:          []*armnetwork.SecurityGroup{securityGroup("nsg-vm", inboundRule("ssh", 300, allow, "Internet", "22"))},
+			wantPorts:     "[22]",
+			wantAllowedBy: "[nsg-vm/ssh]",
+		},
+		{
+			name:      "Allowed only from one address",
+			nsgs:      []*armnetwork.SecurityGroup{securityGroup("nsg-vm", inboundRule("ssh", 300, allow, "203.0.113.5/32", "22"))},
+			wantPorts: "[]",
+		},
+		{
+			name: "Denied at a lower priority number",
+			nsgs: []*armnetwork.SecurityGroup{securityGroup("nsg-vm",
+				inboundRule("deny-ssh", 200, deny, "*", "22"),
+				inboundRule("wide-open", 300, allow, "0.0.0.0/0", "*"),
+			)},
+			wantPorts:     "[3389 5985 5986]",
+			wantAllowedBy: "[nsg-vm/wide-open]",
+		},
+		{
+			name: "Subnet NSG blocks what the NIC NSG allows",
+			nsgs: []*armnetwork.SecurityGroup{
+				securityGroup("nsg-subnet", inboundRule("rdp", 100, allow, "*", "3000-4000")),
+				securityGroup("nsg-nic", inboundRule("all", 100, allow, "*", "*")),
+			},
+			wantPorts:     "[3389]",
+			wantAllowedBy: "[nsg-nic/all nsg-subnet/rdp]",
+		},
+		{
+			name:          "Basic public IP without NSG",
+			basic:         true,
+			wantPorts:     "[22 3389 5985 5986]",
+			wantAllowedBy: "[no NSG on a Basic public IP]",
+		},
+		{
+			name:      "Standard public IP without NSG",
+			wantPorts: "[]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ports, allowedBy := ExposedPorts(tt.nsgs, tt.basic)
+			if got := fmt.Sprint(ports); got != tt.wantPorts {
+				t.Errorf("ports = %s, want %s", got, tt.wantPorts)
+			}
+			if tt.wantAllowedBy != "" {
+				if got := fmt.Sprint(allowedBy); got != tt.wantAllowedBy {
+					t.Errorf("allowed by = %s, want %s", got, tt.wantAllowedBy)
+				}
+			}
+		})
+	}
+}
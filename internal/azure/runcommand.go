@@ -0,0 +1,85 @@
+package azure
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RunCommandOnVM runs script on a VM with the Run Command API, as a shell
+// script on Linux and a PowerShell script on Windows, and waits for its
+// output. runID identifies the batch of VMs the script was sent to.
+func RunCommandOnVM(runID int, resourceID, script string) tea.Cmd {
+	return func() tea.Msg {
+		result := RunCommandResultMsg{RunID: runID, VMID: resourceID}
+
+		id, client, err := virtualMachine(resourceID)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		ctx := context.Background()
+		vm, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+
+		poller, err := client.BeginRunCommand(ctx, id.ResourceGroupName, id.Name, armcompute.RunCommandInput{
+			CommandID: to.Ptr(runCommandID(vm.VirtualMachine)),
+			Script:    scriptLines(script),
+		}, nil)
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		resp, err := poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{Frequency: operationPollInterval})
+		if err != nil {
+			result.Err = err
+			return result
+		}
+		result.Stdout, result.Stderr = runCommandOutput(resp.Value)
+		return result
+	}
+}
+
+// runCommandID picks the built-in command matching the VM's OS.
+func runCommandID(vm armcompute.VirtualMachine) string {
+	props := vm.Properties
+	if props != nil && props.StorageProfile != nil && props.StorageProfile.OSDisk != nil &&
+		props.StorageProfile.OSDisk.OSType != nil && *props.StorageProfile.OSDisk.OSType == armcompute.OperatingSystemTypesWindows {
+		return "RunPowerShellScript"
+	}
+	return "RunShellScript"
+}
+
+// scriptLines splits a script into the lines the Run Command API expects.
+func scriptLines(script string) []*string {
+	var lines []*string
+	for _, line := range strings.Split(strings.ReplaceAll(script, "\r\n", "\n"), "\n") {
+		lines = append(lines, &line)
+	}
+	return lines
+}
+
+// runCommandOutput extracts stdout and stderr from the statuses returned by
+// the Run Command API, e.g. "ComponentStatus/StdOut/succeeded".
+func runCommandOutput(statuses []*armcompute.InstanceViewStatus) (stdout, stderr string) {
+	for _, status := range statuses {
+		if status == nil || status.Code == nil {
+			continue
+		}
+		switch {
+		case strings.Contains(*status.Code, "/StdOut/"):
+			stdout = deref(status.Message)
+		case strings.Contains(*status.Code, "/StdErr/"):
+			stderr = deref(status.Message)
+		}
+	}
+	return stdout, stderr
+}
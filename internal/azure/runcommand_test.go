@@ -0,0 +1,41 @@
+package azure
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+)
+
+func TestRunCommandOutput(t *testing.T) {
+	stdout, stderr := runCommandOutput([]*armcompute.InstanceViewStatus{
+		{Code: to.Ptr("ProvisioningState/succeeded")},
+		{Code: to.Ptr("ComponentStatus/StdOut/succeeded"), Message: to.Ptr("Filesystem  Size\n/dev/sda1   30G\n")},
+		{Code: to.Ptr("ComponentStatus/StdErr/succeeded"), Message: to.Ptr("df: /mnt: Permission denied\n")},
+	})
+	if stdout != "Filesystem  Size\n/dev/sda1   30G\n" {
+		t.Errorf("stdout = %q", stdout)
+	}
+	if stderr != "df: /mnt: Permission denied\n" {
+		t.Errorf("stderr = %q", stderr)
+	}
+}
+
+func TestRunCommandID(t *testing.T) {
+	windows := armcompute.VirtualMachine{Properties: &armcompute.VirtualMachineProperties{
+		StorageProfile: &armcompute.StorageProfile{OSDisk: &armcompute.OSDisk{OSType: to.Ptr(armcompute.OperatingSystemTypesWindows)}},
+	}}
+	if got := runCommandID(windows); got != "RunPowerShellScript" {
+		t.Errorf("runCommandID(windows) = %q", got)
+	}
+	if got := runCommandID(armcompute.VirtualMachine{}); got != "RunShellScript" {
+		t.Errorf("runCommandID(unknown OS) = %q", got)
+	}
+}
+
+func TestScriptLines(t *testing.T) {
+	lines := scriptLines("uptime\r\ndf -h")
+	if len(lines) != 2 || *lines[0] != "uptime" || *lines[1] != "df -h" {
+		t.Errorf("scriptLines() = %v", lines)
+	}
+}
@@ -0,0 +1,88 @@
+package azure
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+func testSASAccount() StorageAccount {
+	return StorageAccount{
+		ID:       testStorageAccountID,
+		Name:     azuriteAccountName,
+		Key:      azuriteAccountKey,
+		BlobURL:  "https://devstoreaccount1.blob.core.windows.net/",
+		QueueURL: "https://devstoreaccount1.queue.core.windows.net/",
+	}
+}
+
+const testStorageAccountID = "/subscriptions/sub-1/resourceGroups/rg-a/providers/Microsoft.Storage/storageAccounts/devstoreaccount1"
+
+func TestAccountSASMatchesBlobSDK(t *testing.T) {
+	account := testSASAccount()
+	account.QueueURL = ""
+	start := time.Date(2024, 10, 7, 10, 0, 0, 0, time.UTC)
+	expiry := start.Add(8 * time.Hour)
+
+	token, err := accountSAS(account, "rl", start, expiry)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cred, err := azblob.NewSharedKeyCredential(account.Name, account.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := sas.AccountSignatureValues{
+		Version:       accountSASVersion,
+		Protocol:      sas.ProtocolHTTPS,
+		StartTime:     start,
+		ExpiryTime:    expiry,
+		Permissions:   "rl",
+		ResourceTypes: "sco",
+	}.SignWithSharedKey(cred)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := url.ParseQuery(token)
+	if got.Get("sig") != want.Signature() || got.Get("ss") != "b" {
+		t.Errorf("account SAS = %s, want signature %s as signed by azblob", token, want.Signature())
+	}
+}
+
+func TestGenerateSAS(t *testing.T) {
+	account := testSASAccount()
+	now := time.Date(2024, 10, 7, 10, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	msg, err := generateSAS(ctx, account, SASRequest{Permissions: "rl", Expiry: now.Add(time.Hour)}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, _ := url.ParseQuery(msg.Token)
+	if query.Get("ss") != "bq" || query.Get("st") != "2024-10-07T09:55:00Z" || query.Get("se") != "2024-10-07T11:00:00Z" {
+		t.Errorf("account SAS = %s", msg.Token)
+	}
+	if want := "BlobEndpoint=https://devstoreaccount1.blob.core.windows.net;QueueEndpoint=https://devstoreaccount1.queue.core.windows.net;SharedAccessSignature=" + msg.Token; msg.ConnectionString != want {
+		t.Errorf("connection string = %q", msg.ConnectionString)
+	}
+
+	msg, err = generateSAS(ctx, account, SASRequest{Container: "logs", Blob: "2024/app log.json", Permissions: "r", Expiry: now.Add(time.Hour)}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(msg.URL, "https://devstoreaccount1.blob.core.windows.net/logs/2024/app%20log.json?") || !strings.Contains(msg.Token, "sr=b") {
+		t.Errorf("blob SAS URL = %s", msg.URL)
+	}
+
+	account.Key = ""
+	if _, err := generateSAS(ctx, account, SASRequest{Permissions: "r", Expiry: now.Add(time.Hour)}, now); err == nil {
+		t.Error("an account SAS was signed without the account key")
+	}
+}
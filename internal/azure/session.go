@@ -0,0 +1,60 @@
+package azure
+
+import (
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+)
+
+// session holds the credential shared by every client. It is rebuilt whenever
+// the tenant, cloud or authentication method changes so that tokens are always
+// issued by the selected directory.
+var session struct {
+	mu       sync.Mutex
+	tenantID string
+	cloud    cloud.Configuration
+	auth     AuthOptions
+	prompt   func(string)
+	cred     azcore.TokenCredential
+}
+
+// SetTenant scopes all subsequent requests to the given tenant. An empty ID
+// falls back to the credential's home tenant.
+func SetTenant(tenantID string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if tenantID != session.tenantID {
+		session.tenantID = tenantID
+		session.cred = nil
+	}
+}
+
+// Tenant returns the tenant requested with SetTenant, if any.
+func Tenant() string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.tenantID
+}
+
+func credential() (azcore.TokenCredential, error) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if session.cred != nil {
+		return session.cred, nil
+	}
+
+	cloudCfg := session.cloud
+	if cloudCfg.ActiveDirectoryAuthorityHost == "" {
+		cloudCfg = cloud.AzurePublic
+	}
+
+	cred, err := newCredential(session.auth, session.tenantID, cloudCfg, session.prompt)
+	if err != nil {
+		return nil, err
+	}
+	session.cred = cred
+	return cred, nil
+}
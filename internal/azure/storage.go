@@ -0,0 +1,459 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	blobcontainer "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	blobservice "github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/directory"
+	fileservice "github.com/Azure/azure-sdk-for-go/sdk/storage/azfile/service"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Kinds of StorageEntry.
+const (
+	StorageContainer = "Container"
+	StorageShare     = "File share"
+	StorageQueue     = "Queue"
+	StorageTable     = "Table"
+	StorageDirectory = "Directory"
+	StorageBlob      = "Blob"
+	StorageFile      = "File"
+	StorageMessage   = "Message"
+	StorageEntity    = "Entity"
+)
+
+// Storage services, as used in StoragePath.Service.
+const (
+	BlobService  = "blob"
+	FileService  = "file"
+	QueueService = "queue"
+	TableService = "table"
+)
+
+// StorageAccount is how the storage explorer reaches the data plane of an
+// account: with its account key when ARM hands it out, and with Microsoft
+// Entra ID otherwise. An empty service URL means the service is absent.
+type StorageAccount struct {
+	ID     string
+	Name   string
+	Key    string
+	KeyErr error
+	// SharedKeyDisabled is set when the account refuses anything signed
+	// with its key, account key SAS tokens included.
+	SharedKeyDisabled bool
+	BlobURL           string
+	FileURL           string
+	QueueURL          string
+	TableURL          string
+}
+
+// StoragePath is a location in a storage account: the account root when
+// Service is empty, otherwise a container, share, queue or table. Prefix is
+// the virtual directory of a container or the directory of a share, ending
+// in "/" when set. Filter is an OData filter for table entities.
+type StoragePath struct {
+	Service   string
+	Container string
+	Prefix    string
+	Filter    string
+}
+
+// IsRoot reports whether the path is the account root.
+func (p StoragePath) IsRoot() bool {
+	return p.Service == ""
+}
+
+// String renders the path as service://container/prefix.
+func (p StoragePath) String() string {
+	if p.IsRoot() {
+		return "/"
+	}
+	return p.Service + "://" + p.Container + "/" + p.Prefix
+}
+
+// StorageEntry is one item of a storage listing. Size is -1 when unknown.
+type StorageEntry struct {
+	Name         string
+	Kind         string
+	Size         int64
+	LastModified time.Time
+	Detail       string
+}
+
+// FetchStorageAccount resolves the service endpoints of a storage account
+// and, where allowed, its account key.
+func FetchStorageAccount(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armstorage.NewAccountsClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.GetProperties(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		account := StorageAccount{ID: resourceID, Name: id.Name}
+		if props := resp.Properties; props != nil {
+			if endpoints := props.PrimaryEndpoints; endpoints != nil {
+				account.BlobURL = deref(endpoints.Blob)
+				account.FileURL = deref(endpoints.File)
+				account.QueueURL = deref(endpoints.Queue)
+				account.TableURL = deref(endpoints.Table)
+			}
+			if props.AllowSharedKeyAccess != nil && !*props.AllowSharedKeyAccess {
+				account.SharedKeyDisabled = true
+				account.KeyErr = errors.New("shared key access is disabled")
+				return StorageAccountMsg{Account: account}
+			}
+		}
+
+		keys, err := client.ListKeys(ctx, id.ResourceGroupName, id.Name, nil)
+		switch {
+		case err != nil:
+			account.KeyErr = err
+		case len(keys.Keys) == 0 || keys.Keys[0].Value == nil:
+			account.KeyErr = errors.New("no account keys returned")
+		default:
+			account.Key = *keys.Keys[0].Value
+		}
+		return StorageAccountMsg{Account: account}
+	}
+}
+
+// ListStorage lists what is at path in a storage account.
+func ListStorage(account StorageAccount, path StoragePath) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+		if path.IsRoot() {
+			entries, errs := listStorageRoot(ctx, account)
+			return StorageListMsg{AccountID: account.ID, Path: path, Entries: entries, Errors: errs}
+		}
+		entries, err := listStoragePath(ctx, account, path)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		return StorageListMsg{AccountID: account.ID, Path: path, Entries: entries}
+	}
+}
+
+// listStorageRoot lists the containers, file shares, queues and tables of
+// an account. A service that cannot be listed is reported in the returned
+// map rather than failing the others.
+func listStorageRoot(ctx context.Context, account StorageAccount) ([]StorageEntry, map[string]error) {
+	listers := []struct {
+		service string
+		url     string
+		list    func(context.Context, StorageAccount) ([]StorageEntry, error)
+	}{
+		{BlobService, account.BlobURL, listContainers},
+		{FileService, account.FileURL, listShares},
+		{QueueService, account.QueueURL, listQueues},
+		{TableService, account.TableURL, listTables},
+	}
+
+	var entries []StorageEntry
+	errs := map[string]error{}
+	for _, lister := range listers {
+		if lister.url == "" {
+			continue
+		}
+		found, err := lister.list(ctx, account)
+		if err != nil {
+			errs[lister.service] = err
+			continue
+		}
+		entries = append(entries, found...)
+	}
+	return entries, errs
+}
+
+func listStoragePath(ctx context.Context, account StorageAccount, path StoragePath) ([]StorageEntry, error) {
+	switch path.Service {
+	case BlobService:
+		return listBlobs(ctx, account, path.Container, path.Prefix)
+	case FileService:
+		return listFiles(ctx, account, path.Container, path.Prefix)
+	case QueueService:
+		return peekMessages(ctx, account, path.Container)
+	case TableService:
+		return queryEntities(ctx, account, path.Container, path.Filter)
+	default:
+		return nil, fmt.Errorf("unknown storage service %q", path.Service)
+	}
+}
+
+func (a StorageAccount) blobService() (*blobservice.Client, error) {
+	if a.Key != "" {
+		cred, err := azblob.NewSharedKeyCredential(a.Name, a.Key)
+		if err != nil {
+			return nil, err
+		}
+		return blobservice.NewClientWithSharedKeyCredential(a.BlobURL, cred, nil)
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return blobservice.NewClient(a.BlobURL, cred, nil)
+}
+
+func (a StorageAccount) fileService() (*fileservice.Client, error) {
+	if a.Key != "" {
+		cred, err := fileservice.NewSharedKeyCredential(a.Name, a.Key)
+		if err != nil {
+			return nil, err
+		}
+		return fileservice.NewClientWithSharedKeyCredential(a.FileURL, cred, nil)
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return fileservice.NewClient(a.FileURL, cred, &fileservice.ClientOptions{
+		FileRequestIntent: to.Ptr(fileservice.ShareTokenIntentBackup),
+	})
+}
+
+func listContainers(ctx context.Context, account StorageAccount) ([]StorageEntry, error) {
+	client, err := account.blobService()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []StorageEntry
+	pager := client.NewListContainersPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range page.ContainerItems {
+			if item == nil || item.Name == nil {
+				continue
+			}
+			entry := StorageEntry{Name: *item.Name, Kind: StorageContainer, Size: -1}
+			if props := item.Properties; props != nil {
+				entry.LastModified = timeOrZero(props.LastModified)
+				access := "private"
+				if props.PublicAccess != nil {
+					access = "public " + string(*props.PublicAccess)
+				}
+				entry.Detail = access
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// listBlobs lists one level of a container: the virtual directories and
+// blobs directly under prefix, named relative to it.
+func listBlobs(ctx context.Context, account StorageAccount, containerName, prefix string) ([]StorageEntry, error) {
+	client, err := account.blobService()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []StorageEntry
+	pager := client.NewContainerClient(containerName).NewListBlobsHierarchyPager("/", &blobcontainer.ListBlobsHierarchyOptions{Prefix: to.Ptr(prefix)})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if page.Segment == nil {
+			continue
+		}
+		for _, dir := range page.Segment.BlobPrefixes {
+			if dir == nil || dir.Name == nil {
+				continue
+			}
+			entries = append(entries, StorageEntry{Name: strings.TrimPrefix(*dir.Name, prefix), Kind: StorageDirectory, Size: -1})
+		}
+		for _, item := range page.Segment.BlobItems {
+			if item == nil || item.Name == nil {
+				continue
+			}
+			entry := StorageEntry{Name: strings.TrimPrefix(*item.Name, prefix), Kind: StorageBlob, Size: -1}
+			if props := item.Properties; props != nil {
+				if props.ContentLength != nil {
+					entry.Size = *props.ContentLength
+				}
+				entry.LastModified = timeOrZero(props.LastModified)
+				var detail []string
+				if props.BlobType != nil {
+					detail = append(detail, string(*props.BlobType))
+				}
+				if props.AccessTier != nil {
+					detail = append(detail, string(*props.AccessTier))
+				}
+				if props.ContentType != nil && *props.ContentType != "" {
+					detail = append(detail, *props.ContentType)
+				}
+				entry.Detail = strings.Join(detail, ", ")
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sortDirectoriesFirst(entries)
+	return entries, nil
+}
+
+// listShares lists file shares through the data plane when the account key
+// is known; share listing does not accept Entra ID tokens, so otherwise it
+// goes through ARM.
+func listShares(ctx context.Context, account StorageAccount) ([]StorageEntry, error) {
+	var entries []StorageEntry
+	if account.Key != "" {
+		client, err := account.fileService()
+		if err != nil {
+			return nil, err
+		}
+		pager := client.NewListSharesPager(nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, share := range page.Shares {
+				if share == nil || share.Name == nil {
+					continue
+				}
+				entry := StorageEntry{Name: *share.Name, Kind: StorageShare, Size: -1}
+				if props := share.Properties; props != nil {
+					entry.LastModified = timeOrZero(props.LastModified)
+					if props.Quota != nil {
+						entry.Detail = fmt.Sprintf("quota %d GiB", *props.Quota)
+					}
+				}
+				entries = append(entries, entry)
+			}
+		}
+		return entries, nil
+	}
+
+	id, err := arm.ParseResourceID(account.ID)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	client, err := armstorage.NewFileSharesClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+	pager := client.NewListPager(id.ResourceGroupName, id.Name, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, share := range page.Value {
+			if share == nil || share.Name == nil {
+				continue
+			}
+			entry := StorageEntry{Name: *share.Name, Kind: StorageShare, Size: -1}
+			if props := share.Properties; props != nil {
+				entry.LastModified = timeOrZero(props.LastModifiedTime)
+				if props.ShareQuota != nil {
+					entry.Detail = fmt.Sprintf("quota %d GiB", *props.ShareQuota)
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// listFiles lists the directories and files of one directory of a share.
+func listFiles(ctx context.Context, account StorageAccount, shareName, dir string) ([]StorageEntry, error) {
+	client, err := account.fileService()
+	if err != nil {
+		return nil, err
+	}
+	share := client.NewShareClient(shareName)
+	dirClient := share.NewRootDirectoryClient()
+	if dir != "" {
+		dirClient = share.NewDirectoryClient(strings.TrimSuffix(dir, "/"))
+	}
+
+	var entries []StorageEntry
+	pager := dirClient.NewListFilesAndDirectoriesPager(&directory.ListFilesAndDirectoriesOptions{
+		Include: directory.ListFilesInclude{Timestamps: true},
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if page.Segment == nil {
+			continue
+		}
+		for _, d := range page.Segment.Directories {
+			if d == nil || d.Name == nil {
+				continue
+			}
+			entry := StorageEntry{Name: *d.Name + "/", Kind: StorageDirectory, Size: -1}
+			if d.Properties != nil {
+				entry.LastModified = timeOrZero(d.Properties.LastWriteTime)
+			}
+			entries = append(entries, entry)
+		}
+		for _, f := range page.Segment.Files {
+			if f == nil || f.Name == nil {
+				continue
+			}
+			entry := StorageEntry{Name: *f.Name, Kind: StorageFile, Size: -1}
+			if props := f.Properties; props != nil {
+				if props.ContentLength != nil {
+					entry.Size = *props.ContentLength
+				}
+				entry.LastModified = timeOrZero(props.LastWriteTime)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sortDirectoriesFirst(entries)
+	return entries, nil
+}
+
+// sortDirectoriesFirst orders a listing like a file manager: directories
+// before files, each by name.
+func sortDirectoriesFirst(entries []StorageEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		iDir, jDir := entries[i].Kind == StorageDirectory, entries[j].Kind == StorageDirectory
+		if iDir != jDir {
+			return iDir
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -0,0 +1,240 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// Azurite's well-known development account.
+const (
+	azuriteAccountName = "devstoreaccount1"
+	azuriteAccountKey  = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+// azuriteAccount returns the Azurite account at AZURITE_HOST (default
+// 127.0.0.1), skipping the test when Azurite is not running there.
+func azuriteAccount(t *testing.T) StorageAccount {
+	t.Helper()
+
+	host := os.Getenv("AZURITE_HOST")
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	for _, port := range []string{"10000", "10001", "10002"} {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), 500*time.Millisecond)
+		if err != nil {
+			t.Skipf("Azurite is not running on %s: %v", host, err)
+		}
+		conn.Close()
+	}
+
+	endpoint := func(port string) string {
+		return fmt.Sprintf("http://%s/%s/", net.JoinHostPort(host, port), azuriteAccountName)
+	}
+	return StorageAccount{
+		Name:     azuriteAccountName,
+		Key:      azuriteAccountKey,
+		BlobURL:  endpoint("10000"),
+		QueueURL: endpoint("10001"),
+		TableURL: endpoint("10002"),
+	}
+}
+
+func TestAzuriteStorageExplorer(t *testing.T) {
+	account := azuriteAccount(t)
+	ctx := context.Background()
+	name := fmt.Sprintf("azr%d", time.Now().UnixNano()%1e9)
+
+	blobs, err := account.blobService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	containerClient := blobs.NewContainerClient(name)
+	if _, err := containerClient.Create(ctx, nil); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	t.Cleanup(func() { _, _ = containerClient.Delete(ctx, nil) })
+	for blob, data := range map[string]string{"readme.md": "# hi", "logs/b.txt": "hello", "logs/2024/a.txt": "a"} {
+		if _, err := containerClient.NewBlockBlobClient(blob).UploadBuffer(ctx, []byte(data), nil); err != nil {
+			t.Fatalf("uploading %s: %v", blob, err)
+		}
+	}
+
+	queueURL := storageURL(account.QueueURL, name)
+	if _, _, err := account.storageRequest(ctx, QueueService, http.MethodPut, queueURL, nil, nil); err != nil {
+		t.Fatalf("creating queue: %v", err)
+	}
+	t.Cleanup(func() { _, _, _ = account.storageRequest(ctx, QueueService, http.MethodDelete, queueURL, nil, nil) })
+	message := "<QueueMessage><MessageText>" + base64.StdEncoding.EncodeToString([]byte("order 42")) + "</MessageText></QueueMessage>"
+	if _, _, err := account.storageRequest(ctx, QueueService, http.MethodPost, queueURL+"/messages", nil, []byte(message)); err != nil {
+		t.Fatalf("sending message: %v", err)
+	}
+
+	if _, _, err := account.storageRequest(ctx, TableService, http.MethodPost, storageURL(account.TableURL, "Tables"), nil, []byte(`{"TableName":"`+name+`"}`)); err != nil {
+		t.Fatalf("creating table: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _, _ = account.storageRequest(ctx, TableService, http.MethodDelete, storageURL(account.TableURL, "Tables('"+name+"')"), nil, nil)
+	})
+	for _, entity := range []string{
+		`{"PartitionKey":"eu","RowKey":"1","Color":"blue"}`,
+		`{"PartitionKey":"us","RowKey":"2","Color":"red"}`,
+	} {
+		if _, _, err := account.storageRequest(ctx, TableService, http.MethodPost, storageURL(account.TableURL, name), nil, []byte(entity)); err != nil {
+			t.Fatalf("inserting entity: %v", err)
+		}
+	}
+
+	t.Run("root", func(t *testing.T) {
+		entries, errs := listStorageRoot(ctx, account)
+		if len(errs) != 0 {
+			t.Fatalf("listing services failed: %v", errs)
+		}
+		for _, kind := range []string{StorageContainer, StorageQueue, StorageTable} {
+			if !hasEntry(entries, name, kind) {
+				t.Errorf("root listing has no %s %s", kind, name)
+			}
+		}
+	})
+
+	t.Run("blobs", func(t *testing.T) {
+		entries, err := listBlobs(ctx, account, name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].Name != "logs/" || entries[0].Kind != StorageDirectory || entries[1].Name != "readme.md" {
+			t.Fatalf("container root = %+v, want logs/ then readme.md", entries)
+		}
+
+		entries, err = listBlobs(ctx, account, name, "logs/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].Name != "2024/" || entries[1].Name != "b.txt" || entries[1].Size != 5 || entries[1].LastModified.IsZero() {
+			t.Errorf("logs/ = %+v, want 2024/ and b.txt of 5 bytes", entries)
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		entries, err := peekMessages(ctx, account, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Name != "order 42" {
+			t.Errorf("peeked %+v, want the decoded message", entries)
+		}
+		again, err := peekMessages(ctx, account, name)
+		if err != nil || len(again) != 1 {
+			t.Errorf("peeking dequeued the message: %+v, %v", again, err)
+		}
+	})
+
+	t.Run("table", func(t *testing.T) {
+		entries, err := queryEntities(ctx, account, name, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 2 || entries[0].Name != "eu / 1" || entries[0].Detail != "Color=blue" {
+			t.Fatalf("entities = %+v", entries)
+		}
+
+		entries, err = queryEntities(ctx, account, name, "PartitionKey eq 'us'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Name != "us / 2" {
+			t.Errorf("filtered entities = %+v, want us / 2", entries)
+		}
+	})
+}
+
+func TestAzuriteBlobTransfers(t *testing.T) {
+	account := azuriteAccount(t)
+	ctx := context.Background()
+	name := fmt.Sprintf("azr%d", time.Now().UnixNano()%1e9)
+
+	blobs, err := account.blobService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	containerClient := blobs.NewContainerClient(name)
+	if _, err := containerClient.Create(ctx, nil); err != nil {
+		t.Fatalf("creating container: %v", err)
+	}
+	t.Cleanup(func() { _, _ = containerClient.Delete(ctx, nil) })
+
+	src := filepath.Join(t.TempDir(), "site")
+	if err := os.MkdirAll(filepath.Join(src, "css"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<h1>hi</h1>"), 0o644)
+	os.WriteFile(filepath.Join(src, "css", "main.css"), []byte("h1 {}"), 0o644)
+
+	msg := StartUpload(ctx, 1, account, name, "www/", src, false)()
+	started, ok := msg.(TransferStartedMsg)
+	if !ok {
+		t.Fatalf("StartUpload() = %#v", msg)
+	}
+	for _, update := range drain(t, started.Transfer) {
+		if update.Err != nil {
+			t.Fatalf("uploading %s: %v", started.Transfer.Files[update.Index].Blob, update.Err)
+		}
+	}
+
+	dst := t.TempDir()
+	msg = StartDownload(ctx, 2, account, name, "www/", "www/site/", dst, false)()
+	if started, ok = msg.(TransferStartedMsg); !ok {
+		t.Fatalf("StartDownload() = %#v", msg)
+	}
+	for _, update := range drain(t, started.Transfer) {
+		if update.Err != nil {
+			t.Fatalf("downloading: %v", update.Err)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dst, "site", "css", "main.css")); err != nil || string(data) != "h1 {}" {
+		t.Errorf("downloaded main.css = %q, %v", data, err)
+	}
+
+	// Existing blobs and files are only replaced when asked to.
+	os.WriteFile(filepath.Join(src, "index.html"), []byte("<h1>bye</h1>"), 0o644)
+	for _, overwrite := range []bool{false, true} {
+		msg = StartUpload(ctx, 3, account, name, "www/", filepath.Join(src, "index.html"), overwrite)()
+		if started, ok = msg.(TransferStartedMsg); !ok {
+			t.Fatalf("StartUpload() = %#v", msg)
+		}
+		if err := fileErr(drain(t, started.Transfer)); (err != nil) == overwrite {
+			t.Errorf("uploading over an existing blob with overwrite %v: %v", overwrite, err)
+		}
+		msg = StartDownload(ctx, 4, account, name, "www/site/", "www/site/index.html", filepath.Join(dst, "site"), overwrite)()
+		if started, ok = msg.(TransferStartedMsg); !ok {
+			t.Fatalf("StartDownload() = %#v", msg)
+		}
+		if err := fileErr(drain(t, started.Transfer)); (err != nil) == overwrite {
+			t.Errorf("downloading over an existing file with overwrite %v: %v", overwrite, err)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(dst, "site", "index.html")); string(data) != "<h1>bye</h1>" {
+		t.Errorf("overwritten index.html = %q", data)
+	}
+
+	preview, ok := FetchBlobPreview(account, name, "www/site/index.html")().(BlobPreviewMsg)
+	if !ok || string(preview.Data) != "<h1>hi</h1>" || preview.ContentType != "text/html; charset=utf-8" {
+		t.Errorf("preview = %+v", preview)
+	}
+}
+
+func hasEntry(entries []StorageEntry, name, kind string) bool {
+	for _, entry := range entries {
+		if entry.Name == name && entry.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+)
+
+func TestSharedKeyMatchesBlobSDK(t *testing.T) {
+	var got *http.Request
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	cred, err := azblob.NewSharedKeyCredential(azuriteAccountName, azuriteAccountKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := container.NewClientWithSharedKeyCredential(server.URL+"/"+azuriteAccountName+"/logs", cred, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pager := client.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{Prefix: new(string)})
+	_, _ = pager.NextPage(context.Background())
+	if got == nil {
+		t.Fatal("the blob SDK sent no request")
+	}
+
+	key, _ := base64.StdEncoding.DecodeString(azuriteAccountKey)
+	want := got.Header.Get("Authorization")
+	if signed := "SharedKey " + azuriteAccountName + ":" + signString(key, sharedKeyStringToSign(azuriteAccountName, got)); signed != want {
+		t.Errorf("signature = %q, want %q as signed by azblob", signed, want)
+	}
+}
+
+func TestQueueAndTableListings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey") {
+			t.Errorf("%s sent without shared key auth", r.URL)
+		}
+		switch {
+		case r.URL.Query().Get("comp") == "list":
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Queues><Queue><Name>orders</Name></Queue></Queues><NextMarker /></EnumerationResults>`)
+		case r.URL.Path == "/q/orders/messages" && r.URL.Query().Get("peekonly") == "true":
+			fmt.Fprint(w, `<QueueMessagesList><QueueMessage><MessageId>m1</MessageId><InsertionTime>Mon, 07 Oct 2024 10:00:00 GMT</InsertionTime><ExpirationTime>Mon, 14 Oct 2024 10:00:00 GMT</ExpirationTime><DequeueCount>2</DequeueCount><MessageText>b3JkZXIgNDI=</MessageText></QueueMessage></QueueMessagesList>`)
+		case r.URL.Path == "/t/Tables":
+			if r.URL.Query().Get("NextTableName") == "" {
+				w.Header().Set("x-ms-continuation-NextTableName", "b")
+				fmt.Fprint(w, `{"value":[{"TableName":"a"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"value":[{"TableName":"b"}]}`)
+		case r.URL.Path == "/t/a()":
+			if r.URL.Query().Get("$filter") != "RowKey eq '1'" || r.URL.Query().Get("$top") != "200" {
+				t.Errorf("entity query = %v", r.URL.Query())
+			}
+			fmt.Fprint(w, `{"value":[{"PartitionKey":"eu","RowKey":"1","Timestamp":"2024-10-07T10:00:00.1234567Z","Size":3,"Color":"blue"}]}`)
+		default:
+			w.Header().Set("x-ms-error-code", "ResourceNotFound")
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	account := StorageAccount{Name: azuriteAccountName, Key: azuriteAccountKey, QueueURL: server.URL + "/q/", TableURL: server.URL + "/t/"}
+	ctx := context.Background()
+
+	queues, err := listQueues(ctx, account)
+	if err != nil || len(queues) != 1 || queues[0].Name != "orders" {
+		t.Errorf("listQueues() = %+v, %v", queues, err)
+	}
+
+	messages, err := peekMessages(ctx, account, "orders")
+	if err != nil || len(messages) != 1 {
+		t.Fatalf("peekMessages() = %+v, %v", messages, err)
+	}
+	if messages[0].Name != "order 42" || !strings.Contains(messages[0].Detail, "dequeued 2") || messages[0].LastModified.IsZero() {
+		t.Errorf("message = %+v", messages[0])
+	}
+
+	tables, err := listTables(ctx, account)
+	if err != nil || len(tables) != 2 || tables[1].Name != "b" {
+		t.Errorf("listTables() = %+v, %v, want a and b across pages", tables, err)
+	}
+
+	entities, err := queryEntities(ctx, account, "a", "RowKey eq '1'")
+	if err != nil || len(entities) != 1 {
+		t.Fatalf("queryEntities() = %+v, %v", entities, err)
+	}
+	if entities[0].Name != "eu / 1" || entities[0].Detail != "Color=blue, Size=3" || entities[0].LastModified.IsZero() {
+		t.Errorf("entity = %+v", entities[0])
+	}
+
+	if _, err := peekMessages(ctx, account, "missing"); err == nil || !strings.Contains(err.Error(), "ResourceNotFound") {
+		t.Errorf("peeking a missing queue returned %v", err)
+	}
+}
+
+func TestDecodeMessageText(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: base64.StdEncoding.EncodeToString([]byte(`{"order": 42}`)), want: `{"order": 42}`},
+		{input: "plain text", want: "plain text"},
+		{input: base64.StdEncoding.EncodeToString([]byte{0xff, 0x00, 0x01}), want: "/wAB"},
+		{input: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := decodeMessageText(tt.input); got != tt.want {
+			t.Errorf("decodeMessageText(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestStoragePathString(t *testing.T) {
+	if got := (StoragePath{}).String(); got != "/" {
+		t.Errorf("root path = %q", got)
+	}
+	if got := (StoragePath{Service: BlobService, Container: "logs", Prefix: "2024/"}).String(); got != "blob://logs/2024/" {
+		t.Errorf("blob path = %q", got)
+	}
+}
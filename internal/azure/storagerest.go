@@ -0,0 +1,349 @@
+package azure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// REST API versions and limits of the queue and table explorer.
+const (
+	queueAPIVersion = "2021-08-06"
+	tableAPIVersion = "2019-02-02"
+	storageScope    = "https://storage.azure.com/.default"
+
+	// maxPeekMessages is the most messages a queue lets you peek at.
+	maxPeekMessages = 32
+	// maxEntities caps how many table entities a query shows.
+	maxEntities = 200
+)
+
+// storageClient gives up on a queue or table service that stops answering
+// rather than leaving the explorer loading forever.
+var storageClient = &http.Client{Timeout: 30 * time.Second}
+
+// storageRequest sends a queue or table service request, signed with the
+// account key or carrying an Entra ID token, and returns the response body
+// and headers. Non-2xx responses are errors.
+func (a StorageAccount) storageRequest(ctx context.Context, service, method, rawURL string, query url.Values, body []byte) ([]byte, http.Header, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	if service == TableService {
+		req.Header.Set("x-ms-version", tableAPIVersion)
+		req.Header.Set("Accept", "application/json;odata=nometadata")
+		req.Header.Set("DataServiceVersion", "3.0")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	} else {
+		req.Header.Set("x-ms-version", queueAPIVersion)
+		if body != nil {
+			req.Header.Set("Content-Type", "application/xml")
+		}
+	}
+
+	if a.Key != "" {
+		key, err := base64.StdEncoding.DecodeString(a.Key)
+		if err != nil {
+			return nil, nil, fmt.Errorf("decoding account key: %w", err)
+		}
+		scheme, stringToSign := "SharedKey", sharedKeyStringToSign(a.Name, req)
+		if service == TableService {
+			scheme, stringToSign = "SharedKeyLite", sharedKeyLiteStringToSign(a.Name, req)
+		}
+		req.Header.Set("Authorization", fmt.Sprintf("%s %s:%s", scheme, a.Name, signString(key, stringToSign)))
+	} else {
+		cred, err := credential()
+		if err != nil {
+			return nil, nil, err
+		}
+		token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{storageScope}})
+		if err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token.Token)
+	}
+
+	resp, err := storageClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode >= 300 {
+		if code := resp.Header.Get("x-ms-error-code"); code != "" {
+			return nil, nil, fmt.Errorf("%s %s: %s", method, u.Path, code)
+		}
+		return nil, nil, fmt.Errorf("%s %s: %s", method, u.Path, resp.Status)
+	}
+	return data, resp.Header, nil
+}
+
+// sharedKeyStringToSign builds the Shared Key string to sign of the blob,
+// queue and file services.
+func sharedKeyStringToSign(account string, req *http.Request) string {
+	contentLength := ""
+	if req.ContentLength > 0 {
+		contentLength = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	var msHeaders []string
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-ms-") {
+			msHeaders = append(msHeaders, lower)
+		}
+	}
+	sort.Strings(msHeaders)
+	canonicalHeaders := make([]string, len(msHeaders))
+	for i, name := range msHeaders {
+		canonicalHeaders[i] = name + ":" + strings.Join(req.Header.Values(name), ",")
+	}
+
+	resource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for name := range query {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+	for _, name := range params {
+		values := query[name]
+		sort.Strings(values)
+		resource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLength,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		"", // Date: x-ms-date is sent instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		strings.Join(canonicalHeaders, "\n"),
+		resource,
+	}, "\n")
+}
+
+// sharedKeyLiteStringToSign builds the Shared Key Lite string to sign of the
+// table service.
+func sharedKeyLiteStringToSign(account string, req *http.Request) string {
+	resource := "/" + account + req.URL.EscapedPath()
+	if comp := req.URL.Query().Get("comp"); comp != "" {
+		resource += "?comp=" + comp
+	}
+	return req.Header.Get("x-ms-date") + "\n" + resource
+}
+
+func signString(key []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// storageURL joins an escaped name onto a service endpoint.
+func storageURL(endpoint string, names ...string) string {
+	u := strings.TrimSuffix(endpoint, "/")
+	for _, name := range names {
+		u += "/" + url.PathEscape(name)
+	}
+	return u
+}
+
+func listQueues(ctx context.Context, account StorageAccount) ([]StorageEntry, error) {
+	var entries []StorageEntry
+	marker := ""
+	for {
+		query := url.Values{"comp": {"list"}}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		data, _, err := account.storageRequest(ctx, QueueService, http.MethodGet, storageURL(account.QueueURL)+"/", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Queues     []string `xml:"Queues>Queue>Name"`
+			NextMarker string   `xml:"NextMarker"`
+		}
+		if err := xml.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("decoding queue list: %w", err)
+		}
+		for _, name := range list.Queues {
+			entries = append(entries, StorageEntry{Name: name, Kind: StorageQueue, Size: -1})
+		}
+		if list.NextMarker == "" {
+			return entries, nil
+		}
+		marker = list.NextMarker
+	}
+}
+
+// peekMessages shows the messages at the front of a queue without
+// dequeuing them or changing their visibility.
+func peekMessages(ctx context.Context, account StorageAccount, queue string) ([]StorageEntry, error) {
+	query := url.Values{"peekonly": {"true"}, "numofmessages": {strconv.Itoa(maxPeekMessages)}}
+	data, _, err := account.storageRequest(ctx, QueueService, http.MethodGet, storageURL(account.QueueURL, queue, "messages"), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Messages []struct {
+			ID             string `xml:"MessageId"`
+			InsertionTime  string `xml:"InsertionTime"`
+			ExpirationTime string `xml:"ExpirationTime"`
+			DequeueCount   int    `xml:"DequeueCount"`
+			Text           string `xml:"MessageText"`
+		} `xml:"QueueMessage"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decoding queue messages: %w", err)
+	}
+
+	entries := make([]StorageEntry, 0, len(list.Messages))
+	for _, message := range list.Messages {
+		text := decodeMessageText(message.Text)
+		entry := StorageEntry{
+			Name:   strings.Join(strings.Fields(text), " "),
+			Kind:   StorageMessage,
+			Size:   int64(len(text)),
+			Detail: fmt.Sprintf("id %s, dequeued %d", message.ID, message.DequeueCount),
+		}
+		if inserted, err := http.ParseTime(message.InsertionTime); err == nil {
+			entry.LastModified = inserted
+		}
+		if expires, err := http.ParseTime(message.ExpirationTime); err == nil {
+			entry.Detail += ", expires " + expires.Local().Format("2006-01-02 15:04")
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// decodeMessageText undoes the base64 encoding most SDKs apply to queue
+// messages, leaving text that does not decode to printable UTF-8 as is.
+func decodeMessageText(text string) string {
+	decoded, err := base64.StdEncoding.DecodeString(text)
+	if err != nil || len(decoded) == 0 || !utf8.Valid(decoded) {
+		return text
+	}
+	for _, r := range string(decoded) {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return text
+		}
+	}
+	return string(decoded)
+}
+
+func listTables(ctx context.Context, account StorageAccount) ([]StorageEntry, error) {
+	var entries []StorageEntry
+	next := ""
+	for {
+		query := url.Values{}
+		if next != "" {
+			query.Set("NextTableName", next)
+		}
+		data, header, err := account.storageRequest(ctx, TableService, http.MethodGet, storageURL(account.TableURL, "Tables"), query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var list struct {
+			Value []struct {
+				TableName string `json:"TableName"`
+			} `json:"value"`
+		}
+		if err := json.Unmarshal(data, &list); err != nil {
+			return nil, fmt.Errorf("decoding table list: %w", err)
+		}
+		for _, table := range list.Value {
+			entries = append(entries, StorageEntry{Name: table.TableName, Kind: StorageTable, Size: -1})
+		}
+		if next = header.Get("x-ms-continuation-NextTableName"); next == "" {
+			return entries, nil
+		}
+	}
+}
+
+// queryEntities returns up to maxEntities entities of a table, optionally
+// narrowed by an OData filter such as "PartitionKey eq 'a'".
+func queryEntities(ctx context.Context, account StorageAccount, table, filter string) ([]StorageEntry, error) {
+	query := url.Values{"$top": {strconv.Itoa(maxEntities)}}
+	if filter != "" {
+		query.Set("$filter", filter)
+	}
+	data, _, err := account.storageRequest(ctx, TableService, http.MethodGet, storageURL(account.TableURL, table)+"()", query, nil)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Value []map[string]any `json:"value"`
+	}
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("decoding entities: %w", err)
+	}
+
+	entries := make([]StorageEntry, 0, len(list.Value))
+	for _, entity := range list.Value {
+		entry := StorageEntry{
+			Name: fmt.Sprintf("%v / %v", entity["PartitionKey"], entity["RowKey"]),
+			Kind: StorageEntity,
+			Size: -1,
+		}
+		if stamp, ok := entity["Timestamp"].(string); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				entry.LastModified = t
+			}
+		}
+
+		var names []string
+		for name := range entity {
+			switch {
+			case name == "PartitionKey", name == "RowKey", name == "Timestamp", strings.Contains(name, "@odata"):
+			default:
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		properties := make([]string, len(names))
+		for i, name := range names {
+			properties[i] = fmt.Sprintf("%s=%v", name, entity[name])
+		}
+		entry.Detail = strings.Join(properties, ", ")
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,36 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchTenants lists the tenants the signed-in identity has access to.
+func FetchTenants() tea.Msg {
+	cred, err := credential()
+	if err != nil {
+		return ErrorMsg{err}
+	}
+
+	client, err := armsubscription.NewTenantsClient(cred, armOptions())
+	if err != nil {
+		return ErrorMsg{err}
+	}
+
+	pager := client.NewListPager(nil)
+	var tenants []armsubscription.TenantIDDescription
+
+	for pager.More() {
+		page, err := pager.NextPage(context.Background())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		for _, tenant := range page.Value {
+			tenants = append(tenants, *tenant)
+		}
+	}
+
+	return TenantsMsg{Tenants: tenants}
+}
@@ -0,0 +1,65 @@
+package azure
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// tokenClaims holds the access token claims azr cares about.
+type tokenClaims struct {
+	TenantID          string `json:"tid"`
+	ObjectID          string `json:"oid"`
+	UPN               string `json:"upn"`
+	PreferredUsername string `json:"preferred_username"`
+	UniqueName        string `json:"unique_name"`
+	AppID             string `json:"appid"`
+	Expires           int64  `json:"exp"`
+}
+
+// principal returns the most readable name for the signed-in identity: the
+// user principal name for users, the application ID for service principals
+// and managed identities.
+func (c tokenClaims) principal() string {
+	for _, name := range []string{c.UPN, c.PreferredUsername, c.UniqueName, c.AppID, c.ObjectID} {
+		if name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// parseTokenClaims decodes the payload of a JWT access token without
+// verifying its signature; the token came straight from the identity
+// provider and is only inspected for display purposes.
+func parseTokenClaims(token string) (tokenClaims, error) {
+	var claims tokenClaims
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return claims, fmt.Errorf("access token is not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return claims, fmt.Errorf("decoding access token: %w", err)
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return claims, fmt.Errorf("decoding access token: %w", err)
+	}
+	return claims, nil
+}
+
+// currentClaims requests a management token and returns its claims.
+func currentClaims(ctx context.Context, cred azcore.TokenCredential) (tokenClaims, error) {
+	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{managementScope()}})
+	if err != nil {
+		return tokenClaims{}, err
+	}
+	return parseTokenClaims(token.Token)
+}
@@ -0,0 +1,22 @@
+package azure
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestParseTokenClaims(t *testing.T) {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"tid":"tenant-1"}`))
+
+	claims, err := parseTokenClaims("header." + payload + ".signature")
+	if err != nil {
+		t.Fatalf("parseTokenClaims() error = %v", err)
+	}
+	if claims.TenantID != "tenant-1" {
+		t.Errorf("TenantID = %q, want %q", claims.TenantID, "tenant-1")
+	}
+
+	if _, err := parseTokenClaims("not-a-jwt"); err == nil {
+		t.Error("parseTokenClaims() accepted a malformed token")
+	}
+}
@@ -1,26 +1,330 @@
 package azure
 
 import (
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerservice/armcontainerservice/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/subscription/armsubscription"
 )
 
 type SubscriptionsMsg struct {
 	Subs []armsubscription.Subscription
+	// TenantID is the directory the subscriptions were listed in.
+	TenantID string
+	// Tenants maps subscription IDs to the directory each belongs to. It
+	// is empty when the tenants could not be read.
+	Tenants map[string]string
+}
+
+// IdentityMsg describes the identity the session signed in as.
+type IdentityMsg struct {
+	Method    string
+	Principal string
+	TenantID  string
+}
+
+// AuthPromptMsg carries sign-in instructions, such as a device code, that the
+// user has to act on.
+type AuthPromptMsg struct {
+	Message string
+}
+
+type TenantsMsg struct {
+	Tenants []armsubscription.TenantIDDescription
 }
 
 type ResourceGroupsMsg struct {
 	SubscriptionID string
-	Groups        []armresources.ResourceGroup
+	Groups         []armresources.ResourceGroup
 }
 
 type ResourcesMsg struct {
 	ResourceGroupName string
-	Resources        []armresources.GenericResourceExpanded
+	Resources         []armresources.GenericResourceExpanded
 }
 
 type ErrorMsg struct {
 	Error error
 }
 
-type LoadingMsg bool 
\ No newline at end of file
+type LoadingMsg bool
+
+// MultiResourceGroupsMsg carries resource groups for several subscriptions,
+// keyed by subscription ID. Subscriptions that failed are reported in Errors.
+type MultiResourceGroupsMsg struct {
+	Groups map[string][]armresources.ResourceGroup
+	Errors map[string]error
+}
+
+// MultiResourcesMsg carries resources for several subscriptions, keyed by
+// subscription ID. Subscriptions that failed are reported in Errors.
+type MultiResourcesMsg struct {
+	Resources map[string][]armresources.GenericResourceExpanded
+	Errors    map[string]error
+}
+
+// WhoamiMsg describes the signed-in principal and its role assignments at
+// Scope.
+type WhoamiMsg struct {
+	Method         string
+	Principal      string
+	ObjectID       string
+	TenantID       string
+	Expires        time.Time
+	Scope          string
+	Assignments    []RoleAssignment
+	AssignmentsErr error
+}
+
+// AKSClusterMsg carries an AKS cluster and its available control plane
+// upgrades.
+type AKSClusterMsg struct {
+	ID       string
+	Cluster  armcontainerservice.ManagedCluster
+	Upgrades []string
+}
+
+// KubeconfigMsg reports that cluster credentials were merged into a
+// kubeconfig file.
+type KubeconfigMsg struct {
+	Path    string
+	Context string
+}
+
+// VMMsg carries a virtual machine, including its instance view, and the
+// addresses of its network interfaces.
+type VMMsg struct {
+	ID         string
+	VM         armcompute.VirtualMachine
+	Addresses  []VMAddress
+	NetworkErr error
+}
+
+// SSHTargetMsg carries what is needed to connect to a VM over SSH.
+type SSHTargetMsg struct {
+	VMID           string
+	Name           string
+	SubscriptionID string
+	AdminUsername  string
+	Addresses      []VMAddress
+	// NetworkErr is set when some interfaces or public IPs could not be read.
+	NetworkErr error
+}
+
+// BootLogURIMsg carries the SAS URI of a VM's serial log blob.
+type BootLogURIMsg struct {
+	VMID string
+	URI  string
+}
+
+// BootLogMsg carries the serial log bytes read from offset From. Expired is
+// set when the storage account refused URI and a new one has to be fetched.
+type BootLogMsg struct {
+	URI     string
+	From    int64
+	Data    []byte
+	Expired bool
+}
+
+// VMSSMsg carries a VM scale set and its instances.
+type VMSSMsg struct {
+	ID        string
+	ScaleSet  armcompute.VirtualMachineScaleSet
+	Instances []*armcompute.VirtualMachineScaleSetVM
+}
+
+// NSGMsg carries a network security group with its rules and associations.
+type NSGMsg struct {
+	ID    string
+	Group armnetwork.SecurityGroup
+}
+
+// VNetMsg carries a virtual network and the IP usage of its subnets.
+type VNetMsg struct {
+	ID      string
+	Network armnetwork.VirtualNetwork
+	Usages  []*armnetwork.VirtualNetworkUsage
+}
+
+// EffectiveNetworkMsg carries the security rules and routes in force on a
+// network interface. ResourceID is the VM or interface it was asked for.
+type EffectiveNetworkMsg struct {
+	ResourceID     string
+	NIC            armnetwork.Interface
+	Subnet         string
+	RouteTable     string
+	SecurityGroups []*armnetwork.EffectiveNetworkSecurityGroup
+	RulesErr       error
+	Routes         []*armnetwork.EffectiveRoute
+	RoutesErr      error
+}
+
+// PublicIPsMsg carries the public IP addresses of a subscription or
+// resource group.
+type PublicIPsMsg struct {
+	SubscriptionID string
+	ResourceGroup  string
+	PublicIPs      []*armnetwork.PublicIPAddress
+}
+
+// ExposureMsg carries the public IPs of a subscription or resource group
+// that expose management ports to the internet, and those that could not
+// be checked.
+type ExposureMsg struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Exposures      []Exposure
+}
+
+// PrivateLinkMsg carries the private endpoints and private DNS zones of a
+// subscription or resource group.
+type PrivateLinkMsg struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Endpoints      []PrivateEndpoint
+	Zones          []PrivateZone
+}
+
+// LoadBalancerMsg carries a load balancer, the public addresses of its
+// frontends keyed by lower-cased frontend ID, and its backend pool members.
+// FrontendErrs holds, by the same key, the addresses that could not be
+// read. HealthErr says why member availability is unknown.
+type LoadBalancerMsg struct {
+	ID           string
+	LoadBalancer armnetwork.LoadBalancer
+	FrontendIPs  map[string]string
+	FrontendErrs map[string]error
+	Members      []BackendMember
+	HealthErr    error
+}
+
+// AppGatewayMsg carries an application gateway and the public addresses of
+// its frontends keyed by lower-cased frontend ID, with the addresses that
+// could not be read in FrontendErrs.
+type AppGatewayMsg struct {
+	ID           string
+	Gateway      armnetwork.ApplicationGateway
+	FrontendIPs  map[string]string
+	FrontendErrs map[string]error
+}
+
+// AppGatewayHealthMsg carries the result of probing an application
+// gateway's backends.
+type AppGatewayHealthMsg struct {
+	ID     string
+	Health armnetwork.ApplicationGatewayBackendHealth
+	Err    error
+}
+
+// KeyVaultMsg carries a key vault's data plane URI and its items keyed by
+// kind. Kinds that could not be listed are reported in Errors.
+type KeyVaultMsg struct {
+	ID     string
+	URI    string
+	Items  map[string][]VaultItem
+	Errors map[string]error
+}
+
+// VaultVersionsMsg carries the versions of a secret, key or certificate.
+type VaultVersionsMsg struct {
+	ID       string
+	Kind     string
+	Name     string
+	Versions []VaultItem
+}
+
+// SecretValueMsg carries the value of a secret version; an empty Version
+// is the current one. Copy is set when the value was read to be copied
+// rather than shown.
+type SecretValueMsg struct {
+	ID      string
+	Name    string
+	Version string
+	Value   string
+	Copy    bool
+}
+
+// ExpiringMsg carries the items of the scanned subscriptions that expire
+// within Days. Subscriptions and resources that could not be read are
+// reported in Errors, keyed by their ID, and vault item kinds the caller may
+// not list in Unlisted, as in ExpiryScan.
+type ExpiringMsg struct {
+	SubscriptionIDs []string
+	Days            int
+	Items           []ExpiringItem
+	Errors          map[string]error
+	Unlisted        map[string]error
+}
+
+// RunCommandResultMsg carries the output of a script run on one VM.
+type RunCommandResultMsg struct {
+	RunID  int
+	VMID   string
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+// DisksMsg carries the managed disks and snapshots of a subscription or
+// resource group.
+type DisksMsg struct {
+	SubscriptionID string
+	ResourceGroup  string
+	Disks          []*armcompute.Disk
+	Snapshots      []*armcompute.Snapshot
+}
+
+// StorageAccountMsg carries how to reach a storage account's data plane.
+type StorageAccountMsg struct {
+	Account StorageAccount
+}
+
+// StorageListMsg carries the entries at Path in a storage account. At the
+// account root, services that could not be listed are reported in Errors,
+// keyed by service.
+type StorageListMsg struct {
+	AccountID string
+	Path      StoragePath
+	Entries   []StorageEntry
+	Errors    map[string]error
+}
+
+// TransferStartedMsg reports that a download or upload has started.
+type TransferStartedMsg struct {
+	Transfer *Transfer
+}
+
+// TransferUpdateMsg reports on file Index of transfer ID: Bytes moved so
+// far, and whether it is Done and with what Err. The last update of a
+// transfer has Index -1 and Finished set.
+type TransferUpdateMsg struct {
+	ID       int
+	Index    int
+	Bytes    int64
+	Done     bool
+	Err      error
+	Finished bool
+}
+
+// BlobPreviewMsg carries the first bytes of a blob of Size bytes.
+type BlobPreviewMsg struct {
+	AccountID   string
+	Container   string
+	Name        string
+	Size        int64
+	ContentType string
+	Data        []byte
+}
+
+// SASMsg carries a generated SAS token. URL is set for a container or blob
+// SAS and ConnectionString for an account SAS.
+type SASMsg struct {
+	AccountID        string
+	Request          SASRequest
+	Token            string
+	URL              string
+	ConnectionString string
+}
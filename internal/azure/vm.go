@@ -0,0 +1,206 @@
+package azure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// VMAddress is one IP configuration of a network interface attached to a VM.
+type VMAddress struct {
+	NIC       string
+	Primary   bool
+	PrivateIP string
+	Subnet    string
+	// PublicIPID is set when a public IP resource is bound; PublicIP stays
+	// empty while it has no address allocated.
+	PublicIPID string
+	PublicIP   string
+}
+
+func newVirtualMachinesClient(subscriptionID string) (*armcompute.VirtualMachinesClient, error) {
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	return armcompute.NewVirtualMachinesClient(subscriptionID, cred, armOptions())
+}
+
+// virtualMachine parses a VM resource ID and returns a client for its
+// subscription.
+func virtualMachine(resourceID string) (*arm.ResourceID, *armcompute.VirtualMachinesClient, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := newVirtualMachinesClient(id.SubscriptionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return id, client, nil
+}
+
+// FetchVM loads a virtual machine with its instance view and resolves the
+// addresses of its network interfaces.
+func FetchVM(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := virtualMachine(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, &armcompute.VirtualMachinesClientGetOptions{
+			Expand: to.Ptr(armcompute.InstanceViewTypesInstanceView),
+		})
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := VMMsg{ID: resourceID, VM: resp.VirtualMachine}
+		// The VM is still worth showing when a NIC cannot be read.
+		msg.Addresses, msg.NetworkErr = resolveAddresses(ctx, resp.VirtualMachine)
+		return msg
+	}
+}
+
+// ResolveSSHTarget looks up what is needed to open an SSH session to a VM:
+// its addresses and admin username. Interfaces that cannot be read are
+// reported in NetworkErr; the lookup only fails when no address resolved.
+func ResolveSSHTarget(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, client, err := virtualMachine(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		addresses, err := resolveAddresses(ctx, resp.VirtualMachine)
+		if err != nil && len(addresses) == 0 {
+			return ErrorMsg{err}
+		}
+
+		target := SSHTargetMsg{
+			VMID:           resourceID,
+			Name:           id.Name,
+			SubscriptionID: id.SubscriptionID,
+			Addresses:      addresses,
+			NetworkErr:     err,
+		}
+		if props := resp.Properties; props != nil && props.OSProfile != nil {
+			target.AdminUsername = deref(props.OSProfile.AdminUsername)
+		}
+		return target
+	}
+}
+
+// resolveAddresses collects the addresses of every network interface of a
+// VM. What can be read is returned alongside the errors of the interfaces
+// and public IPs that could not.
+func resolveAddresses(ctx context.Context, vm armcompute.VirtualMachine) ([]VMAddress, error) {
+	if vm.Properties == nil || vm.Properties.NetworkProfile == nil {
+		return nil, nil
+	}
+	var (
+		all  []VMAddress
+		errs []error
+	)
+	for _, ref := range vm.Properties.NetworkProfile.NetworkInterfaces {
+		if ref == nil || ref.ID == nil {
+			continue
+		}
+		addresses, err := vmAddresses(ctx, *ref.ID)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		all = append(all, addresses...)
+	}
+	return all, errors.Join(errs...)
+}
+
+// vmAddresses reads a network interface and the public IPs bound to its IP
+// configurations. A public IP that cannot be read leaves its address
+// unresolved and its error is returned with the addresses.
+func vmAddresses(ctx context.Context, nicID string) ([]VMAddress, error) {
+	id, err := arm.ParseResourceID(nicID)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, err
+	}
+	nics, err := armnetwork.NewInterfacesClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+	nic, err := nics.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return nil, err
+	}
+	if nic.Properties == nil {
+		return nil, nil
+	}
+
+	var (
+		addresses []VMAddress
+		errs      []error
+	)
+	for _, config := range nic.Properties.IPConfigurations {
+		if config == nil || config.Properties == nil {
+			continue
+		}
+		address := VMAddress{
+			NIC:       id.Name,
+			Primary:   config.Properties.Primary != nil && *config.Properties.Primary,
+			PrivateIP: deref(config.Properties.PrivateIPAddress),
+		}
+		if subnet := config.Properties.Subnet; subnet != nil && subnet.ID != nil {
+			if subnetID, err := arm.ParseResourceID(*subnet.ID); err == nil {
+				address.Subnet = subnetID.Parent.Name + "/" + subnetID.Name
+			}
+		}
+		if pip := config.Properties.PublicIPAddress; pip != nil && pip.ID != nil {
+			address.PublicIPID = *pip.ID
+			if address.PublicIP, err = publicIPAddress(ctx, *pip.ID); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		addresses = append(addresses, address)
+	}
+	return addresses, errors.Join(errs...)
+}
+
+// publicIPAddress returns the address allocated to a public IP resource, or
+// "" while it has none.
+func publicIPAddress(ctx context.Context, resourceID string) (string, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return "", err
+	}
+	cred, err := credential()
+	if err != nil {
+		return "", err
+	}
+	client, err := armnetwork.NewPublicIPAddressesClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+	if err != nil {
+		return "", err
+	}
+	if resp.Properties == nil {
+		return "", nil
+	}
+	return deref(resp.Properties.IPAddress), nil
+}
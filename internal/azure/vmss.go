@@ -0,0 +1,126 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v5"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// scaleSet parses a scale set resource ID and returns clients for the scale
+// set and its instances.
+func scaleSet(resourceID string) (*arm.ResourceID, *armcompute.VirtualMachineScaleSetsClient, *armcompute.VirtualMachineScaleSetVMsClient, error) {
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cred, err := credential()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	sets, err := armcompute.NewVirtualMachineScaleSetsClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	vms, err := armcompute.NewVirtualMachineScaleSetVMsClient(id.SubscriptionID, cred, armOptions())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return id, sets, vms, nil
+}
+
+// FetchVMSS loads a scale set and its instances with their instance views.
+func FetchVMSS(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, sets, vms, err := scaleSet(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := sets.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := VMSSMsg{ID: resourceID, ScaleSet: resp.VirtualMachineScaleSet}
+		pager := vms.NewListPager(id.ResourceGroupName, id.Name, &armcompute.VirtualMachineScaleSetVMsClientListOptions{
+			Expand: to.Ptr("instanceView"),
+		})
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return ErrorMsg{err}
+			}
+			msg.Instances = append(msg.Instances, page.Value...)
+		}
+		return msg
+	}
+}
+
+// ScaleVMSS sets the number of instances of a scale set.
+func ScaleVMSS(opID int, resourceID string, capacity int64) tea.Cmd {
+	return func() tea.Msg {
+		id, sets, _, err := scaleSet(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := sets.BeginUpdate(context.Background(), id.ResourceGroupName, id.Name, armcompute.VirtualMachineScaleSetUpdate{
+			SKU: &armcompute.SKU{Capacity: &capacity},
+		}, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// RestartVMSSInstance restarts one instance of a scale set.
+func RestartVMSSInstance(opID int, resourceID, instanceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, _, vms, err := scaleSet(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := vms.BeginRestart(context.Background(), id.ResourceGroupName, id.Name, instanceID, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// ReimageVMSSInstance restores one instance's OS disk from the scale set's
+// image.
+func ReimageVMSSInstance(opID int, resourceID, instanceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, _, vms, err := scaleSet(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := vms.BeginReimage(context.Background(), id.ResourceGroupName, id.Name, instanceID, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
+
+// UpgradeVMSSInstance applies the scale set's latest model to one instance.
+func UpgradeVMSSInstance(opID int, resourceID, instanceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, sets, _, err := scaleSet(resourceID)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		poller, err := sets.BeginUpdateInstances(context.Background(), id.ResourceGroupName, id.Name, armcompute.VirtualMachineScaleSetVMInstanceRequiredIDs{
+			InstanceIDs: []*string{&instanceID},
+		}, nil)
+		if err != nil {
+			return OperationUpdateMsg{ID: opID, Status: "Failed", Done: true, Err: err}
+		}
+		return operationStarted(opID, poller)
+	}
+}
@@ -0,0 +1,47 @@
+package azure
+
+import (
+	"context"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork/v4"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// FetchVNet loads a virtual network with its subnets and peerings, and the
+// IP usage of each subnet. Usage needs more than read access on some
+// networks, so failing to list it leaves Usages empty rather than failing.
+func FetchVNet(resourceID string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := arm.ParseResourceID(resourceID)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+		client, err := armnetwork.NewVirtualNetworksClient(id.SubscriptionID, cred, armOptions())
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		resp, err := client.Get(ctx, id.ResourceGroupName, id.Name, nil)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := VNetMsg{ID: resourceID, Network: resp.VirtualNetwork}
+		pager := client.NewListUsagePager(id.ResourceGroupName, id.Name, nil)
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				msg.Usages = nil
+				break
+			}
+			msg.Usages = append(msg.Usages, page.Value...)
+		}
+		return msg
+	}
+}
@@ -0,0 +1,166 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// RoleAssignment is a role granted to the signed-in principal, directly or
+// through a group, at, above or below the inspected scope. Applies says
+// which of the three it is.
+type RoleAssignment struct {
+	Role          string
+	Scope         string
+	PrincipalType string
+	Applies       string
+}
+
+// Where a role assignment applies relative to the inspected scope: on it,
+// inherited from a parent scope, or only on a child scope such as one
+// resource group or resource below it.
+const (
+	AppliesHere      = "here"
+	AppliesInherited = "inherited"
+	AppliesBelow     = "child scope"
+)
+
+// Scope builds the ARM scope for a subscription and, optionally, one of its
+// resource groups.
+func Scope(subscriptionID, resourceGroup string) string {
+	if subscriptionID == "" {
+		return ""
+	}
+	scope := "/subscriptions/" + normalizeSubscriptionID(subscriptionID)
+	if resourceGroup != "" {
+		scope += "/resourceGroups/" + resourceGroup
+	}
+	return scope
+}
+
+// FetchWhoami describes the signed-in principal and the roles it holds at the
+// given scope. Failing to list role assignments (typically a 403 itself) is
+// reported alongside the identity rather than replacing it.
+func FetchWhoami(scope string) tea.Cmd {
+	return func() tea.Msg {
+		cred, err := credential()
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		ctx := context.Background()
+		claims, err := currentClaims(ctx, cred)
+		if err != nil {
+			return ErrorMsg{err}
+		}
+
+		msg := WhoamiMsg{
+			Method:    AuthMethod(),
+			Principal: claims.principal(),
+			ObjectID:  claims.ObjectID,
+			TenantID:  claims.TenantID,
+			Scope:     scope,
+		}
+		if claims.Expires > 0 {
+			msg.Expires = time.Unix(claims.Expires, 0)
+		}
+
+		if scope != "" && claims.ObjectID != "" {
+			msg.Assignments, msg.AssignmentsErr = listRoleAssignments(ctx, cred, scope, claims.ObjectID)
+		}
+		return msg
+	}
+}
+
+func listRoleAssignments(ctx context.Context, cred azcore.TokenCredential, scope, objectID string) ([]RoleAssignment, error) {
+	// The subscription ID is only used for subscription-level operations;
+	// listing at an explicit scope ignores it.
+	client, err := armauthorization.NewRoleAssignmentsClient("", cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+	definitions, err := armauthorization.NewRoleDefinitionsClient(cred, armOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	// assignedTo() also returns assignments inherited through group membership.
+	filter := fmt.Sprintf("assignedTo('%s')", objectID)
+	pager := client.NewListForScopePager(scope, &armauthorization.RoleAssignmentsClientListForScopeOptions{
+		Filter: &filter,
+	})
+
+	roleNames := make(map[string]string)
+	var assignments []RoleAssignment
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, ra := range page.Value {
+			if ra.Properties == nil {
+				continue
+			}
+			props := ra.Properties
+
+			definitionID := deref(props.RoleDefinitionID)
+			role, ok := roleNames[definitionID]
+			if !ok {
+				role = definitionID[strings.LastIndex(definitionID, "/")+1:]
+				if def, err := definitions.GetByID(ctx, definitionID, nil); err == nil && def.Properties != nil && def.Properties.RoleName != nil {
+					role = *def.Properties.RoleName
+				}
+				roleNames[definitionID] = role
+			}
+
+			assignment := RoleAssignment{
+				Role:  role,
+				Scope: deref(props.Scope),
+			}
+			if props.PrincipalType != nil {
+				assignment.PrincipalType = string(*props.PrincipalType)
+			}
+			assignment.Applies = scopeRelation(assignment.Scope, scope)
+			assignments = append(assignments, assignment)
+		}
+	}
+
+	return assignments, nil
+}
+
+// scopeRelation tells where an assignment at assigned applies relative to
+// the inspected scope. Listing a scope also returns the assignments made
+// on anything below it, which only grant access there. Unrelated scopes
+// give an empty string.
+func scopeRelation(assigned, scope string) string {
+	switch {
+	case strings.EqualFold(assigned, scope):
+		return AppliesHere
+	case withinScope(scope, assigned):
+		return AppliesInherited
+	case withinScope(assigned, scope):
+		return AppliesBelow
+	}
+	return ""
+}
+
+// withinScope reports whether child lies strictly below parent, comparing
+// whole path segments so that /subscriptions/sub-10 is not below
+// /subscriptions/sub-1.
+func withinScope(child, parent string) bool {
+	parent = strings.ToLower(strings.TrimSuffix(parent, "/"))
+	return strings.HasPrefix(strings.ToLower(child), parent+"/")
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
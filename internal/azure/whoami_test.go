@@ -0,0 +1,23 @@
+package azure
+
+import "testing"
+
+func TestScopeRelation(t *testing.T) {
+	const scope = "/subscriptions/sub-1/resourceGroups/rg-app"
+	tests := []struct {
+		assigned string
+		want     string
+	}{
+		{assigned: "/subscriptions/sub-1/resourcegroups/RG-APP", want: AppliesHere},
+		{assigned: "/subscriptions/sub-1", want: AppliesInherited},
+		{assigned: "/", want: AppliesInherited},
+		{assigned: "/providers/Microsoft.Management/managementGroups/mg-root", want: ""},
+		{assigned: "/subscriptions/sub-1/resourceGroups/rg-app/providers/Microsoft.KeyVault/vaults/kv-app", want: AppliesBelow},
+		{assigned: "/subscriptions/sub-1/resourceGroups/rg-app2", want: ""},
+	}
+	for _, tt := range tests {
+		if got := scopeRelation(tt.assigned, scope); got != tt.want {
+			t.Errorf("scopeRelation(%q) = %q, want %q", tt.assigned, got, tt.want)
+		}
+	}
+}
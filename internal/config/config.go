@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Config is the user configuration read from the config file. Command-line
+// flags take precedence over the values stored here.
+type Config struct {
+	// Cloud selects the Azure cloud: AzurePublic, AzureChina,
+	// AzureUSGovernment or Custom.
+	Cloud string `json:"cloud,omitempty"`
+	// ARMEndpoint and AuthorityHost describe a Custom cloud.
+	ARMEndpoint   string `json:"armEndpoint,omitempty"`
+	AuthorityHost string `json:"authorityHost,omitempty"`
+
+	// Auth selects the authentication method, see azure.AuthMethods.
+	Auth string `json:"auth,omitempty"`
+	// ClientID and ClientCertificate configure service principal, workload
+	// identity and user-assigned managed identity sign-in. Secrets are only
+	// read from the environment.
+	ClientID          string `json:"clientId,omitempty"`
+	ClientCertificate string `json:"clientCertificate,omitempty"`
+
+	// SSH configures connecting to virtual machines, keyed by subscription
+	// ID. The "*" entry applies to subscriptions without their own entry.
+	SSH map[string]SSH `json:"ssh,omitempty"`
+}
+
+// SSH holds the settings used to connect to the VMs of a subscription.
+type SSH struct {
+	// User defaults to the VM's admin username.
+	User string `json:"user,omitempty"`
+	// KeyFile is the private key passed to ssh. Without one, Bastion
+	// connections sign in with Microsoft Entra ID.
+	KeyFile string `json:"keyFile,omitempty"`
+	// Bastion is the resource ID of the Azure Bastion host used for VMs
+	// without a public IP.
+	Bastion string `json:"bastion,omitempty"`
+}
+
+// SSHFor returns the SSH settings for a subscription.
+func (c Config) SSHFor(subscriptionID string) SSH {
+	for key, ssh := range c.SSH {
+		if strings.EqualFold(key, subscriptionID) {
+			return ssh
+		}
+	}
+	return c.SSH["*"]
+}
+
+// Path returns the location of the config file. AZR_CONFIG overrides the
+// default of <user config dir>/azr/config.json.
+func Path() (string, error) {
+	if path := os.Getenv("AZR_CONFIG"); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "azr", "config.json"), nil
+}
+
+// Load reads the config file. A missing file is not an error and yields an
+// empty configuration.
+func Load() (Config, error) {
+	var cfg Config
+
+	path, err := Path()
+	if err != nil {
+		return cfg, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	t.Setenv("AZR_CONFIG", path)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() with missing file error = %v", err)
+	}
+	if !reflect.DeepEqual(cfg, Config{}) {
+		t.Errorf("Load() with missing file = %+v, want empty config", cfg)
+	}
+
+	data := `{"cloud": "Custom", "armEndpoint": "https://management.local", "authorityHost": "https://login.local/"}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := Config{Cloud: "Custom", ARMEndpoint: "https://management.local", AuthorityHost: "https://login.local/"}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("Load() = %+v, want %+v", cfg, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Error("Load() accepted malformed JSON")
+	}
+}
+
+func TestSSHFor(t *testing.T) {
+	cfg := Config{SSH: map[string]SSH{
+		"*":                                    {User: "azureuser"},
+		"0B1F6471-1BF0-4DDA-AEC3-CB9272F09590": {User: "ops", Bastion: "/subscriptions/hub/resourceGroups/rg-hub/providers/Microsoft.Network/bastionHosts/bas-hub"},
+	}}
+
+	if got := cfg.SSHFor("0b1f6471-1bf0-4dda-aec3-cb9272f09590"); got.User != "ops" || got.Bastion == "" {
+		t.Errorf("SSHFor(configured subscription) = %+v, want its own entry", got)
+	}
+	if got := cfg.SSHFor("other"); got != (SSH{User: "azureuser"}) {
+		t.Errorf("SSHFor(other) = %+v, want the \"*\" entry", got)
+	}
+	if got := (Config{}).SSHFor("other"); got != (SSH{}) {
+		t.Errorf("SSHFor() without SSH config = %+v, want empty", got)
+	}
+}
@@ -0,0 +1,107 @@
+package kubeconfig
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// config is the subset of a kubeconfig file azr edits. Fields it does not
+// know about are carried along unchanged.
+type config struct {
+	APIVersion     string         `yaml:"apiVersion,omitempty"`
+	Kind           string         `yaml:"kind,omitempty"`
+	Clusters       []namedEntry   `yaml:"clusters"`
+	Contexts       []namedEntry   `yaml:"contexts"`
+	Users          []namedEntry   `yaml:"users"`
+	CurrentContext string         `yaml:"current-context"`
+	Rest           map[string]any `yaml:",inline"`
+}
+
+type namedEntry struct {
+	Name string         `yaml:"name"`
+	Rest map[string]any `yaml:",inline"`
+}
+
+// DefaultPath returns the kubeconfig file kubectl would use: the first entry
+// of KUBECONFIG, or ~/.kube/config.
+func DefaultPath() (string, error) {
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return filepath.SplitList(env)[0], nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".kube", "config"), nil
+}
+
+// Merge adds the single cluster, user and context of an AKS kubeconfig to the
+// file at path under contextName, replacing entries with the same names, and
+// makes it the current context. The file is created if it does not exist.
+func Merge(path string, clusterConfig []byte, contextName string) error {
+	var incoming config
+	if err := yaml.Unmarshal(clusterConfig, &incoming); err != nil {
+		return fmt.Errorf("parsing cluster kubeconfig: %w", err)
+	}
+	if len(incoming.Clusters) != 1 || len(incoming.Users) != 1 || len(incoming.Contexts) != 1 {
+		return fmt.Errorf("cluster kubeconfig has %d clusters, %d users and %d contexts, want one of each",
+			len(incoming.Clusters), len(incoming.Users), len(incoming.Contexts))
+	}
+
+	existing := config{APIVersion: "v1", Kind: "Config"}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return err
+	default:
+		if err := yaml.Unmarshal(data, &existing); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	cluster := incoming.Clusters[0]
+	user := incoming.Users[0]
+	context := incoming.Contexts[0]
+
+	// The cluster entry is named after the context; the user keeps its AKS
+	// name (clusterUser_<group>_<cluster> or clusterAdmin_...) which is
+	// already unique per cluster and role.
+	cluster.Name = contextName
+	context.Name = contextName
+	contextBody, _ := context.Rest["context"].(map[string]any)
+	if contextBody == nil {
+		return fmt.Errorf("cluster kubeconfig context has no body")
+	}
+	contextBody["cluster"] = cluster.Name
+	contextBody["user"] = user.Name
+
+	existing.Clusters = upsert(existing.Clusters, cluster)
+	existing.Users = upsert(existing.Users, user)
+	existing.Contexts = upsert(existing.Contexts, context)
+	existing.CurrentContext = contextName
+
+	out, err := yaml.Marshal(&existing)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out, 0o600)
+}
+
+func upsert(entries []namedEntry, entry namedEntry) []namedEntry {
+	for i := range entries {
+		if entries[i].Name == entry.Name {
+			entries[i] = entry
+			return entries
+		}
+	}
+	return append(entries, entry)
+}
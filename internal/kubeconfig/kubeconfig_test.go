@@ -0,0 +1,116 @@
+package kubeconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const aksKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: aks1
+  cluster:
+    server: https://aks1-dns.hcp.westeurope.azmk8s.io:443
+    certificate-authority-data: Q0E=
+contexts:
+- name: aks1
+  context:
+    cluster: aks1
+    user: clusterUser_rg-a_aks1
+current-context: aks1
+users:
+- name: clusterUser_rg-a_aks1
+  user:
+    token: secret
+`
+
+const existingKubeconfig = `apiVersion: v1
+kind: Config
+preferences:
+  colors: true
+clusters:
+- name: kind
+  cluster:
+    server: https://127.0.0.1:6443
+- name: prod
+  cluster:
+    server: https://old.example.com
+contexts:
+- name: kind
+  context:
+    cluster: kind
+    user: kind
+- name: prod
+  context:
+    cluster: prod
+    user: old-user
+current-context: kind
+users:
+- name: kind
+  user:
+    token: kind-token
+`
+
+func TestMergeIntoExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte(existingKubeconfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Merge(path, []byte(aksKubeconfig), "prod"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got config
+	if err := yaml.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.CurrentContext != "prod" {
+		t.Errorf("current-context = %q, want prod", got.CurrentContext)
+	}
+	if len(got.Clusters) != 2 || len(got.Contexts) != 2 || len(got.Users) != 2 {
+		t.Fatalf("got %d clusters, %d contexts, %d users, want 2 each", len(got.Clusters), len(got.Contexts), len(got.Users))
+	}
+	server := got.Clusters[1].Rest["cluster"].(map[string]any)["server"]
+	if server != "https://aks1-dns.hcp.westeurope.azmk8s.io:443" {
+		t.Errorf("prod cluster server = %v, want the AKS API server", server)
+	}
+	ctx := got.Contexts[1].Rest["context"].(map[string]any)
+	if ctx["cluster"] != "prod" || ctx["user"] != "clusterUser_rg-a_aks1" {
+		t.Errorf("prod context = %v, want cluster prod and the AKS user", ctx)
+	}
+	if _, ok := got.Rest["preferences"]; !ok {
+		t.Error("unknown top-level keys were dropped")
+	}
+}
+
+func TestMergeCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config")
+
+	if err := Merge(path, []byte(aksKubeconfig), "aks1-admin"); err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("kubeconfig mode = %o, want 600", perm)
+	}
+}
+
+func TestMergeRejectsUnexpectedInput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := Merge(path, []byte("clusters: []"), "aks1"); err == nil {
+		t.Error("Merge() accepted a kubeconfig without a cluster")
+	}
+}
@@ -37,6 +37,13 @@ var (
 			Foreground(lipgloss.Color("196")).
 			Bold(true)
 
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214"))
+
+	MatchStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("39")).
+			Reverse(true)
+
 	TabContainerStyle = lipgloss.NewStyle().
 				Width(100).
 				Padding(0, 1)
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mbaykara/azurermcli/internal/styles"
+)
+
+// Property is a labelled value shown in the summary panel of a detail view.
+type Property struct {
+	Key   string
+	Value string
+}
+
+// RenderProperties renders properties as aligned "key: value" lines, one per
+// property.
+func RenderProperties(props []Property) string {
+	keyWidth := 0
+	for _, p := range props {
+		if w := lipgloss.Width(p.Key); w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	lines := make([]string, 0, len(props))
+	for _, p := range props {
+		key := styles.TitleStyle.Render(p.Key + ":" + strings.Repeat(" ", keyWidth-lipgloss.Width(p.Key)))
+		lines = append(lines, key+" "+p.Value)
+	}
+	return strings.Join(lines, "\n")
+}
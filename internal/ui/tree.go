@@ -0,0 +1,29 @@
+package ui
+
+import "strings"
+
+// TreeNode is a labelled node of a tree rendered by RenderTree.
+type TreeNode struct {
+	Label    string
+	Children []TreeNode
+}
+
+// RenderTree renders a tree with box-drawing branches, the root on the first
+// line and each level of children indented below its parent.
+func RenderTree(root TreeNode) string {
+	var sb strings.Builder
+	sb.WriteString(root.Label)
+	renderChildren(&sb, root.Children, "")
+	return sb.String()
+}
+
+func renderChildren(sb *strings.Builder, children []TreeNode, indent string) {
+	for i, child := range children {
+		branch, next := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, next = "└── ", "    "
+		}
+		sb.WriteString("\n" + indent + branch + child.Label)
+		renderChildren(sb, child.Children, indent+next)
+	}
+}
@@ -0,0 +1,37 @@
+package ui
+
+import "testing"
+
+func TestRenderTree(t *testing.T) {
+	tests := []struct {
+		name string
+		root TreeNode
+		want string
+	}{
+		{name: "root only", root: TreeNode{Label: "vnet-hub"}, want: "vnet-hub"},
+		{
+			name: "nested",
+			root: TreeNode{Label: "vnet-hub", Children: []TreeNode{
+				{Label: "snet-app", Children: []TreeNode{
+					{Label: "NSG: nsg-app"},
+					{Label: "Connected (1)", Children: []TreeNode{{Label: "vm1 (VM)"}}},
+				}},
+				{Label: "snet-db", Children: []TreeNode{{Label: "NSG: none"}}},
+			}},
+			want: "vnet-hub\n" +
+				"├── snet-app\n" +
+				"│   ├── NSG: nsg-app\n" +
+				"│   └── Connected (1)\n" +
+				"│       └── vm1 (VM)\n" +
+				"└── snet-db\n" +
+				"    └── NSG: none",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderTree(tt.root); got != tt.want {
+				t.Errorf("RenderTree() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}